@@ -0,0 +1,71 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"net/http"
+
+	"github.com/go-siris/siris/context"
+	"github.com/go-siris/siris/core/router"
+)
+
+// Admin registers the routes which invalidate the cached responses
+// to the "p" Party, all of them respond with 204 No Content on success:
+//
+// DELETE / purges all the cached responses.
+// DELETE /key?key=... invalidates the cached response of a key.
+// DELETE /prefix?prefix=/api/users invalidates the cached responses by a request path prefix.
+// DELETE /tag?tag=users&tag=... invalidates the cached responses by tags.
+//
+// The routes are not protected,
+// register the authentication middleware(s) to the Party, i.e:
+// cache.Admin(app.Party("/admin/cache", basicAuth))
+func Admin(p router.Party) {
+	p.Delete("/", adminHandler(func(ctx context.Context) (bool, error) {
+		return true, Purge()
+	}))
+
+	p.Delete("/key", adminHandler(func(ctx context.Context) (bool, error) {
+		key := ctx.URLParam("key")
+		if key == "" {
+			return false, nil
+		}
+		return true, Invalidate(key)
+	}))
+
+	p.Delete("/prefix", adminHandler(func(ctx context.Context) (bool, error) {
+		prefix := ctx.URLParam("prefix")
+		if prefix == "" {
+			return false, nil
+		}
+		return true, InvalidatePrefix(prefix)
+	}))
+
+	p.Delete("/tag", adminHandler(func(ctx context.Context) (bool, error) {
+		tags := ctx.Request().URL.Query()["tag"]
+		if len(tags) == 0 {
+			return false, nil
+		}
+		return true, InvalidateTag(tags...)
+	}))
+}
+
+// adminHandler converts an invalidation action to a handler,
+// the action returns false if the request is missing its arguments.
+func adminHandler(action func(ctx context.Context) (bool, error)) context.Handler {
+	return func(ctx context.Context) {
+		ok, err := action(ctx)
+		if !ok {
+			ctx.StatusCode(http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			ctx.StatusCode(http.StatusInternalServerError)
+			ctx.WriteString(err.Error())
+			return
+		}
+		ctx.StatusCode(http.StatusNoContent)
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"time"
 
 	"github.com/go-siris/siris/cache/client"
+	"github.com/go-siris/siris/cache/store"
 	"github.com/go-siris/siris/context"
 )
 
@@ -77,7 +78,41 @@ func Handler(expiration time.Duration) context.Handler {
 	return h
 }
 
+// Store is the storage backend of the cached responses,
+// pass a store to the `Cache(...).Store` in order to
+// share the cached responses between the instances of an application.
+//
+// See the `NewMemoryStore`, `NewFilesystemStore` and
+// the "github.com/go-siris/siris/cache/store/redis" package.
+type Store = store.Store
+
 var (
+	// NewMemoryStore returns a new in-memory store of cached responses,
+	// it's the default store of the cached handlers.
+	NewMemoryStore = store.NewMemory
+	// NewFilesystemStore returns a new store which keeps the cached responses
+	// as files inside a directory.
+	NewFilesystemStore = store.NewFilesystem
+	// NewKeyBuilder returns a new key builder, its `Key` can be passed
+	// to the `Cache(...).Key` in order to select the url query parameters
+	// and the request headers that a cached response depends on.
+	//
+	// By-default each cached handler keeps a response per method, host, path and url query.
+	NewKeyBuilder = client.NewKeyBuilder
+	// Tag tags the cached response of the current request, i.e:
+	// cache.Tag(ctx, "users"), call the `InvalidateTag` to remove all
+	// the cached responses with that tag when a user is modified.
+	Tag = client.Tag
+	// Invalidate removes the cached response of a key from all the stores.
+	Invalidate = client.Invalidate
+	// InvalidatePrefix removes the cached responses of all the requests
+	// whose path starts with a prefix, i.e "/api/users".
+	InvalidatePrefix = client.InvalidatePrefix
+	// InvalidateTag removes all the cached responses which are tagged
+	// with at least one of the given tags.
+	InvalidateTag = client.InvalidateTag
+	// Purge removes all the cached responses from all the stores.
+	Purge = client.Purge
 	// NoCache called when a particular handler is not valid for cache.
 	// If this function called inside a handler then the handler is not cached
 	// even if it's surrounded with the Cache/CacheFunc/CacheRemote wrappers.
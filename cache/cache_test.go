@@ -2,12 +2,15 @@
 package cache_test
 
 import (
+	"io/ioutil"
 	"net/http"
+	"os"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/go-siris/siris/cache"
+	"github.com/go-siris/siris/cache/client"
 	"github.com/go-siris/siris/cache/client/rule"
 
 	"github.com/go-siris/siris"
@@ -195,3 +198,301 @@ func TestCacheValidator(t *testing.T) {
 		t.Fatalf(t.Name()+": %v", errTestFailed.Format(3, counter))
 	}
 }
+
+func TestCacheKeyed(t *testing.T) {
+	app := siris.New()
+	var n uint32
+
+	app.Get("/users/{id}", cache.WrapHandler(func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		ctx.Writef("user %s page %s", ctx.Params().Get("id"), ctx.URLParam("page"))
+	}, cacheDuration))
+
+	selectiveCache := cache.Cache(func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		ctx.Writef("page %s", ctx.URLParam("page"))
+	}, cacheDuration).Key(cache.NewKeyBuilder().Query("page").Key)
+	app.Get("/pages", selectiveCache.ServeHTTP)
+
+	boundedCache := cache.Cache(func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		ctx.WriteString(ctx.Path())
+	}, cacheDuration).MaxEntries(1)
+	app.Get("/bounded/{name}", boundedCache.ServeHTTP)
+
+	e := httptest.New(t, app)
+
+	expectCounter := func(expected uint32) {
+		if counter := atomic.LoadUint32(&n); counter != expected {
+			t.Fatal(errTestFailed.Format(expected, counter))
+		}
+	}
+
+	e.GET("/users/1").Expect().Status(http.StatusOK).Body().Equal("user 1 page ")
+	e.GET("/users/2").Expect().Status(http.StatusOK).Body().Equal("user 2 page ")
+	e.GET("/users/1").Expect().Status(http.StatusOK).Body().Equal("user 1 page ")
+	e.GET("/users/2").Expect().Status(http.StatusOK).Body().Equal("user 2 page ")
+	expectCounter(2)
+
+	e.GET("/users/1").WithQuery("page", 2).Expect().Status(http.StatusOK).Body().Equal("user 1 page 2")
+	e.GET("/users/1").WithQuery("page", 2).Expect().Status(http.StatusOK).Body().Equal("user 1 page 2")
+	expectCounter(3)
+
+	// only the "page" query parameter is part of the key.
+	e.GET("/pages").WithQuery("page", 1).WithQuery("utm_source", "a").Expect().Status(http.StatusOK).Body().Equal("page 1")
+	e.GET("/pages").WithQuery("page", 1).WithQuery("utm_source", "b").Expect().Status(http.StatusOK).Body().Equal("page 1")
+	e.GET("/pages").WithQuery("page", 2).Expect().Status(http.StatusOK).Body().Equal("page 2")
+	expectCounter(5)
+
+	// the least recently used entry is evicted.
+	e.GET("/bounded/a").Expect().Status(http.StatusOK).Body().Equal("/bounded/a")
+	e.GET("/bounded/b").Expect().Status(http.StatusOK).Body().Equal("/bounded/b")
+	e.GET("/bounded/a").Expect().Status(http.StatusOK).Body().Equal("/bounded/a")
+	expectCounter(8)
+}
+
+func TestCacheStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "siris-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fsStore, err := cache.NewFilesystemStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, s := range map[string]cache.Store{
+		"memory":     cache.NewMemoryStore(0),
+		"filesystem": fsStore,
+	} {
+		var n uint32
+		h := func(ctx context.Context) {
+			atomic.AddUint32(&n, 1)
+			ctx.WriteString(expectedBodyStr)
+		}
+
+		// two applications, i.e two instances, which share the same store.
+		app1, app2 := siris.New(), siris.New()
+		app1.Get("/", cache.Cache(h, cacheDuration).Store(s).ServeHTTP)
+		app2.Get("/", cache.Cache(h, cacheDuration).Store(s).ServeHTTP)
+
+		e1, e2 := httptest.New(t, app1), httptest.New(t, app2)
+		e1.GET("/").Expect().Status(http.StatusOK).Body().Equal(expectedBodyStr)
+		e2.GET("/").Expect().Status(http.StatusOK).Body().Equal(expectedBodyStr)
+		if counter := atomic.LoadUint32(&n); counter != 1 {
+			t.Fatalf("%s: %v", name, errTestFailed.Format(1, counter))
+		}
+
+		if err = s.Purge(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		e2.GET("/").Expect().Status(http.StatusOK).Body().Equal(expectedBodyStr)
+		if counter := atomic.LoadUint32(&n); counter != 2 {
+			t.Fatalf("%s: %v", name, errTestFailed.Format(2, counter))
+		}
+	}
+}
+
+func TestCacheHeaders(t *testing.T) {
+	app := siris.New()
+	var n uint32
+
+	lastModified := time.Now().UTC().Add(-time.Hour).Format(app.ConfigurationReadOnly().GetTimeFormat())
+	app.Get("/", cache.WrapHandler(func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		ctx.Header("ETag", `"v1"`)
+		ctx.Header("Last-Modified", lastModified)
+		ctx.Header("X-Custom", "value")
+		ctx.SetCookieKV("session", "secret")
+		ctx.ContentType("application/json")
+		ctx.WriteString(`{"cached":true}`)
+	}, cacheDuration))
+
+	e := httptest.New(t, app)
+
+	e.GET("/").Expect().Status(http.StatusOK).Header("Set-Cookie").NotEmpty()
+	// served from the cache, the custom headers are kept but not the cookies.
+	res := e.GET("/").Expect().Status(http.StatusOK)
+	res.Header("X-Custom").Equal("value")
+	res.Header("ETag").Equal(`"v1"`)
+	res.Header("Set-Cookie").Empty()
+	res.ContentType("application/json")
+	res.Body().Equal(`{"cached":true}`)
+
+	e.GET("/").WithHeader("If-None-Match", `W/"v1", "v0"`).Expect().
+		Status(http.StatusNotModified).Body().Empty()
+	e.GET("/").WithHeader("If-None-Match", `"v2"`).Expect().
+		Status(http.StatusOK).Body().Equal(`{"cached":true}`)
+	e.GET("/").WithHeader("If-Modified-Since", lastModified).Expect().
+		Status(http.StatusNotModified).Header("ETag").Equal(`"v1"`)
+
+	if counter := atomic.LoadUint32(&n); counter != 1 {
+		t.Fatal(errTestFailed.Format(1, counter))
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	app := siris.New()
+	var (
+		n   uint32
+		key atomic.Value
+	)
+
+	app.Get("/invalidate/users/{id}", cache.WrapHandler(func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		key.Store(client.DefaultKeyFunc(ctx))
+		cache.Tag(ctx, "users")
+		ctx.WriteString(ctx.Params().Get("id"))
+	}, cacheDuration))
+	app.Get("/invalidate/posts", cache.WrapHandler(func(ctx context.Context) {
+		atomic.AddUint32(&n, 1)
+		cache.Tag(ctx, "posts")
+		ctx.WriteString("posts")
+	}, cacheDuration))
+	cache.Admin(app.Party("/admin/cache"))
+
+	e := httptest.New(t, app)
+
+	expectCounter := func(expected uint32) {
+		if counter := atomic.LoadUint32(&n); counter != expected {
+			t.Fatal(errTestFailed.Format(expected, counter))
+		}
+	}
+	request := func() {
+		e.GET("/invalidate/users/1").Expect().Status(http.StatusOK).Body().Equal("1")
+		e.GET("/invalidate/users/2").Expect().Status(http.StatusOK).Body().Equal("2")
+		e.GET("/invalidate/posts").Expect().Status(http.StatusOK).Body().Equal("posts")
+	}
+
+	request()
+	request()
+	expectCounter(3)
+
+	// the key of the "/invalidate/users/2".
+	if err := cache.Invalidate(key.Load().(string)); err != nil {
+		t.Fatal(err)
+	}
+	request()
+	expectCounter(4)
+
+	if err := cache.InvalidatePrefix("/invalidate/users"); err != nil {
+		t.Fatal(err)
+	}
+	request()
+	expectCounter(6)
+
+	e.DELETE("/admin/cache/tag").WithQuery("tag", "posts").Expect().Status(http.StatusNoContent)
+	request()
+	expectCounter(7)
+
+	e.DELETE("/admin/cache/prefix").Expect().Status(http.StatusBadRequest)
+	e.DELETE("/admin/cache/prefix").WithQuery("prefix", "/invalidate").Expect().Status(http.StatusNoContent)
+	request()
+	expectCounter(10)
+}
+
+func TestCacheStale(t *testing.T) {
+	app := siris.New()
+	var (
+		coalesced, revalidated, failed uint32
+		fail                           int32
+	)
+
+	app.Get("/coalesced", cache.WrapHandler(func(ctx context.Context) {
+		atomic.AddUint32(&coalesced, 1)
+		time.Sleep(cacheDuration / 10)
+		ctx.WriteString("coalesced")
+	}, cacheDuration))
+
+	app.Get("/revalidated", cache.Cache(func(ctx context.Context) {
+		ctx.Writef("%d", atomic.AddUint32(&revalidated, 1))
+	}, cacheDuration).StaleWhileRevalidate(cacheDuration).ServeHTTP)
+
+	app.Get("/failed", cache.Cache(func(ctx context.Context) {
+		atomic.AddUint32(&failed, 1)
+		if atomic.LoadInt32(&fail) == 1 {
+			ctx.StatusCode(http.StatusServiceUnavailable)
+			ctx.WriteString("failed")
+			return
+		}
+		ctx.WriteString("succeed")
+	}, cacheDuration).StaleIfError(cacheDuration).ServeHTTP)
+
+	e := httptest.New(t, app)
+
+	done := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		go func() {
+			e.GET("/coalesced").Expect().Status(http.StatusOK).Body().Equal("coalesced")
+			done <- struct{}{}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		<-done
+	}
+	if counter := atomic.LoadUint32(&coalesced); counter != 1 {
+		t.Fatal(errTestFailed.Format(1, counter))
+	}
+
+	e.GET("/revalidated").Expect().Status(http.StatusOK).Body().Equal("1")
+	e.GET("/failed").Expect().Status(http.StatusOK).Body().Equal("succeed")
+	atomic.StoreInt32(&fail, 1)
+	time.Sleep(cacheDuration + cacheDuration/10)
+
+	// the stale response is served and it's refreshed in the background.
+	e.GET("/revalidated").Expect().Status(http.StatusOK).Body().Equal("1")
+	time.Sleep(cacheDuration / 10)
+	e.GET("/revalidated").Expect().Status(http.StatusOK).Body().Equal("2")
+
+	// the stale response is served instead of the handler's failure.
+	e.GET("/failed").Expect().Status(http.StatusOK).Body().Equal("succeed")
+	if counter := atomic.LoadUint32(&failed); counter != 2 {
+		t.Fatal(errTestFailed.Format(2, counter))
+	}
+}
+
+func TestCacheRevalidationCredentials(t *testing.T) {
+	app := siris.New()
+	cookies := make(chan string, 4)
+	deadlines := make(chan bool, 4)
+
+	handler := func(ctx context.Context) {
+		_, hasDeadline := ctx.Request().Context().Deadline()
+		cookies <- ctx.GetHeader("Cookie")
+		deadlines <- hasDeadline
+		ctx.WriteString("ok")
+	}
+
+	app.Get("/shared", cache.Cache(handler, cacheDuration).StaleWhileRevalidate(cacheDuration).ServeHTTP)
+	app.Get("/per-client", cache.Cache(handler, cacheDuration).
+		Key(client.NewKeyBuilder().Vary("Cookie").Key).
+		StaleWhileRevalidate(cacheDuration).ServeHTTP)
+
+	e := httptest.New(t, app)
+
+	expectRevalidation := func(path string, expectedCookie string) {
+		e.GET(path).WithHeader("Cookie", "sid=secret").Expect().Status(http.StatusOK)
+		<-cookies
+		<-deadlines
+
+		time.Sleep(cacheDuration + cacheDuration/10)
+		e.GET(path).WithHeader("Cookie", "sid=secret").Expect().Status(http.StatusOK)
+
+		select {
+		case cookie := <-cookies:
+			if cookie != expectedCookie {
+				t.Fatalf("%s: expected the background request's Cookie %q but got %q", path, expectedCookie, cookie)
+			}
+			if !<-deadlines {
+				t.Fatalf("%s: expected the background request to have a deadline", path)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: expected a background request", path)
+		}
+	}
+
+	expectRevalidation("/shared", "")
+	expectRevalidation("/per-client", "sid=secret")
+}
@@ -24,6 +24,35 @@ var (
 // used inside nethttp and fhttp Skippers.
 var NoCacheHeader = "X-No-Cache"
 
+// TagsContextKey is the context's values key which
+// the tags of the current request's cached response are stored to.
+var TagsContextKey = "siris.cache.tags"
+
+// RevalidationTimeout is the maximum duration of a background request
+// which refreshes a stale response, see the `Handler#StaleWhileRevalidate`.
+var RevalidationTimeout = 30 * time.Second
+
 // MinimumCacheDuration is the minimum duration from time.Now
 // which is allowed between cache save and cache clear
 var MinimumCacheDuration = 2 * time.Second
+
+// MaxCacheEntries is the default maximum number of cached responses
+// that a single cache handler keeps in memory,
+// the least recently used ones are evicted first.
+var MaxCacheEntries = 1024
+
+// IgnoredHeaders are the response headers which are not
+// stored with the cached responses, so they are never replayed to other clients.
+//
+// The "Set-Cookie" is one of them because it's, usually, unique per client.
+var IgnoredHeaders = []string{
+	"Set-Cookie",
+	"Content-Length",
+	"Date",
+	"Connection",
+	"Keep-Alive",
+	"Transfer-Encoding",
+	"Trailer",
+	"Upgrade",
+	NoCacheHeader,
+}
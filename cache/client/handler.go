@@ -5,16 +5,18 @@
 package client
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/go-siris/siris/cache/cfg"
 	"github.com/go-siris/siris/cache/client/rule"
 	"github.com/go-siris/siris/cache/entry"
+	"github.com/go-siris/siris/cache/store"
 	"github.com/go-siris/siris/context"
 )
 
 // Handler the local cache service handler contains
-// the original bodyHandler, the memory cache entry and
+// the original bodyHandler, the store of the cached responses and
 // the validator for each of the incoming requests and post responses
 type Handler struct {
 
@@ -27,21 +29,142 @@ type Handler struct {
 	// See more at ruleset.go
 	rule rule.Rule
 
-	// entry is the memory cache entry
-	entry *entry.Entry
+	// expiration is the life duration of each new cache entry.
+	expiration time.Duration
+
+	// keyFunc returns the key of the cache entry
+	// which the current request belongs to.
+	//
+	// See key.go for more.
+	keyFunc KeyFunc
+
+	// ignoredHeaders are the response headers which are not cached.
+	//
+	// Defaults to the `cfg.IgnoredHeaders`.
+	ignoredHeaders []string
+
+	// store keeps the cached responses, one per key.
+	//
+	// Defaults to an in-memory store.
+	store store.Store
+
+	// staleWhileRevalidate is the duration after the expiration
+	// which a stale response is served while it's refreshed in the background.
+	staleWhileRevalidate time.Duration
+	// staleIfError is the duration after the expiration
+	// which a stale response is served when the handler fails.
+	staleIfError time.Duration
+
+	// flight coalesces the concurrent executions of the handler per key.
+	flight flightGroup
+	// revalidating keeps one background refresh per key.
+	revalidating flightGroup
 }
 
 // NewHandler returns a new cached handler
 func NewHandler(bodyHandler context.Handler,
 	expireDuration time.Duration) *Handler {
 
-	e := entry.NewEntry(expireDuration)
+	h := &Handler{
+		bodyHandler:    bodyHandler,
+		rule:           DefaultRuleSet,
+		expiration:     expireDuration,
+		keyFunc:        DefaultKeyFunc,
+		ignoredHeaders: cfg.IgnoredHeaders,
+		store:          store.NewMemory(cfg.MaxCacheEntries),
+	}
+	registerStore(nil, h.store)
+	return h
+}
 
-	return &Handler{
-		bodyHandler: bodyHandler,
-		rule:        DefaultRuleSet,
-		entry:       e,
+// Key sets the function which decides the cache key of each request,
+// requests with the same key are being served by the same cached response.
+//
+// Defaults to the `DefaultKeyFunc`, use the `NewKeyBuilder`
+// to select the query parameters and the request headers which are part of the key.
+//
+// returns itself.
+func (h *Handler) Key(fn KeyFunc) *Handler {
+	if fn == nil {
+		fn = DefaultKeyFunc
 	}
+	h.keyFunc = fn
+
+	return h
+}
+
+// MaxEntries sets the maximum number of the cached responses
+// that this handler keeps in memory, the least recently used are evicted first.
+// Zero or negative value means no limit.
+//
+// Should be called before serve, it replaces the store
+// with a new in-memory one, see `Store` for more.
+//
+// returns itself.
+func (h *Handler) MaxEntries(n int) *Handler {
+	return h.Store(store.NewMemory(n))
+}
+
+// Store sets the storage backend of the cached responses,
+// i.e a filesystem or a redis store in order to share
+// the cached responses between instances.
+//
+// Defaults to an in-memory store which keeps up to `cfg.MaxCacheEntries` responses.
+//
+// returns itself.
+func (h *Handler) Store(s store.Store) *Handler {
+	if s == nil {
+		s = store.NewMemory(cfg.MaxCacheEntries)
+	}
+	registerStore(h.store, s)
+	h.store = s
+
+	return h
+}
+
+// IgnoreHeaders sets the response headers which are not cached,
+// all the other headers of the handler's response are replayed with the cached body.
+//
+// Defaults to the `cfg.IgnoredHeaders`, which excludes the "Set-Cookie",
+// call it without arguments in order to cache all the headers.
+//
+// returns itself.
+func (h *Handler) IgnoreHeaders(names ...string) *Handler {
+	h.ignoredHeaders = names
+
+	return h
+}
+
+// StaleWhileRevalidate sets the duration, after a cached response's expiration,
+// which the stale response is still served while a background request refreshes it,
+// as the "stale-while-revalidate" Cache-Control extension describes.
+//
+// The background request is a copy of the client's one which is served by the whole application,
+// the middleware that registered before the cache handler are executed as well.
+// Its Authorization, Proxy-Authorization and Cookie headers are removed,
+// unless the cache key depends on them, i.e by the `KeyBuilder#Vary`,
+// and it's canceled after the `cfg.RevalidationTimeout`.
+//
+// Defaults to zero, the clients are waiting for the refresh.
+//
+// returns itself.
+func (h *Handler) StaleWhileRevalidate(d time.Duration) *Handler {
+	h.staleWhileRevalidate = d
+
+	return h
+}
+
+// StaleIfError sets the duration, after a cached response's expiration,
+// which the stale response is served instead of a handler's
+// 5xx response, as the "stale-if-error" Cache-Control extension describes.
+//
+// Defaults to zero, the handler's failures are sent to the clients.
+//
+// returns itself.
+func (h *Handler) StaleIfError(d time.Duration) *Handler {
+	h.staleIfError = d
+
+	return h
 }
 
 // Rule sets the ruleset for this handler.
@@ -93,40 +216,117 @@ func (h *Handler) ServeHTTP(ctx context.Context) {
 		return
 	}
 
-	// check if we have a stored response( it is not expired)
-	res, exists := h.entry.Response()
-	if !exists {
+	key := h.keyFunc(ctx)
 
-		// if it's not exists, then execute the original handler
-		// with our custom response recorder response writer
-		// because the net/http doesn't give us
-		// a built'n way to get the status code & body
-		recorder := ctx.Recorder()
-		bodyHandler(ctx)
-
-		// now that we have recordered the response,
-		// we are ready to check if that specific response is valid to be stored.
+	// the background requests are always refreshing the cached response.
+	if !isRevalidation(ctx.Request()) {
+		// check if we have a stored response( it is not expired)
+		if res, exists := h.store.Get(key); exists {
+			staleness := res.Staleness()
+			if staleness == 0 {
+				h.serve(ctx, res)
+				return
+			}
 
-		// check if it's a valid response, if it's not then just return.
-		if !h.rule.Valid(ctx) {
-			return
+			// serve the stale response while a background request refreshes it.
+			if staleness <= h.staleWhileRevalidate {
+				h.revalidating.Go(key, revalidation(ctx, isPerClient(ctx, h.keyFunc, key)))
+				h.serve(ctx, res)
+				return
+			}
 		}
+	}
+
+	// only one of the concurrent requests executes the handler,
+	// the rest are waiting for its response to be stored.
+	if h.flight.Do(key, func() { h.refresh(ctx, bodyHandler, key) }) {
+		return
+	}
 
-		// no need to copy the body, its already done inside
-		body := recorder.Body()
-		if len(body) == 0 {
-			// if no body then just exit
+	if res, exists := h.store.Get(key); exists {
+		if staleness := res.Staleness(); staleness == 0 || staleness <= h.staleIfError {
+			h.serve(ctx, res)
 			return
 		}
+	}
+
+	// the response of the in-flight request was not valid to be stored.
+	bodyHandler(ctx)
+}
+
+// refresh executes the original handler and stores its response.
+func (h *Handler) refresh(ctx context.Context, bodyHandler context.Handler, key string) {
+	// keep the stale response, if any, in order to be served on handler's failure.
+	stale, hasStale := h.store.Get(key)
+
+	// execute the original handler
+	// with our custom response recorder response writer
+	// because the net/http doesn't give us
+	// a built'n way to get the status code & body
+	recorder := ctx.Recorder()
+	bodyHandler(ctx)
+
+	if hasStale && recorder.StatusCode() >= http.StatusInternalServerError &&
+		stale.Staleness() <= h.staleIfError {
+		recorder.Reset()
+		h.serve(ctx, stale)
+		return
+	}
+
+	// now that we have recordered the response,
+	// we are ready to check if that specific response is valid to be stored.
+
+	// check if it's a valid response, if it's not then just return.
+	if !h.rule.Valid(ctx) {
+		return
+	}
+
+	if len(recorder.Body()) == 0 {
+		// if no body then just exit
+		return
+	}
+	// copy the body, the recorder's buffer is re-used by the next requests.
+	body := append([]byte(nil), recorder.Body()...)
+
+	// check for an expiration time if the
+	// given expiration was not valid then check for GetMaxAge &
+	// update the response & release the recorder
+	e := entry.NewEntry(h.expiration)
+	headers := filterHeaders(recorder.Header(), h.ignoredHeaders)
+	e.Reset(recorder.StatusCode(), headers, body, GetMaxAge(ctx.Request()))
+	res, _ := e.Response()
+	res.SetPath(ctx.Path())
+	res.SetTags(tagsOf(ctx))
+	res.SetExpires(e.ExpiresAt())
+
+	// the store keeps the response after its expiration
+	// in order to be served as stale.
+	ttl := e.Life() + h.staleWhileRevalidate
+	if h.staleIfError > h.staleWhileRevalidate {
+		ttl = e.Life() + h.staleIfError
+	}
+
+	if err := h.store.Set(key, res, ttl); err != nil {
+		ctx.Application().Logger().Warnf("cache: %v", err)
+	}
+}
+
+// serve writes the cached response to the client.
+func (h *Handler) serve(ctx context.Context, res *entry.Response) {
+	header := ctx.ResponseWriter().Header()
+	for name, values := range res.Headers() {
+		header[name] = append([]string(nil), values...)
+	}
 
-		// check for an expiration time if the
-		// given expiration was not valid then check for GetMaxAge &
-		// update the response & release the recorder
-		h.entry.Reset(recorder.StatusCode(), recorder.Header().Get(cfg.ContentTypeHeader), body, GetMaxAge(ctx.Request()))
+	// the client has the same response already,
+	// the headers are sent without the body.
+	if notModified(ctx, res) {
+		header.Del(cfg.ContentTypeHeader)
+		header.Del("Content-Length")
+		ctx.StatusCode(http.StatusNotModified)
 		return
 	}
 
-	// if it's valid then just write the cached results
 	ctx.ContentType(res.ContentType())
 	ctx.StatusCode(res.StatusCode())
 	ctx.Write(res.Body())
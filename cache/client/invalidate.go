@@ -0,0 +1,130 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/go-siris/siris/cache/cfg"
+	"github.com/go-siris/siris/cache/entry"
+	"github.com/go-siris/siris/cache/store"
+	"github.com/go-siris/siris/context"
+)
+
+// stores are the stores of all the cache handlers,
+// the invalidation functions are applied to each one of them.
+//
+// A store can be shared between handlers, the value is the number of its handlers.
+var stores = struct {
+	mu  sync.RWMutex
+	set map[store.Store]int
+}{set: make(map[store.Store]int)}
+
+// registerStore adds the "s" to the invalidation targets,
+// the "old" one is removed if it's not used by any other handler.
+func registerStore(old store.Store, s store.Store) {
+	stores.mu.Lock()
+	if old != nil {
+		if stores.set[old]--; stores.set[old] <= 0 {
+			delete(stores.set, old)
+		}
+	}
+	stores.set[s]++
+	stores.mu.Unlock()
+}
+
+// eachStore calls the "fn" for each one of the stores
+// and returns the first error, if any.
+func eachStore(fn func(s store.Store) error) error {
+	stores.mu.RLock()
+	list := make([]store.Store, 0, len(stores.set))
+	for s := range stores.set {
+		list = append(list, s)
+	}
+	stores.mu.RUnlock()
+
+	var firstErr error
+	for _, s := range list {
+		if err := fn(s); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Tag tags the cached response of the current request,
+// the tags can be used later on to invalidate all the cached responses
+// that share a tag, see `InvalidateTag`.
+//
+// It should be called inside a handler which is wrapped by a cache handler.
+func Tag(ctx context.Context, tags ...string) {
+	existing, _ := ctx.Values().Get(cfg.TagsContextKey).([]string)
+	ctx.Values().Set(cfg.TagsContextKey, append(existing, tags...))
+}
+
+// tagsOf returns the tags that the handler added to the current request.
+func tagsOf(ctx context.Context) []string {
+	tags, _ := ctx.Values().Get(cfg.TagsContextKey).([]string)
+	return tags
+}
+
+// Invalidate removes the cached response of the "key" from all the stores,
+// the key is the result of the cache handler's `KeyFunc`.
+func Invalidate(key string) error {
+	return eachStore(func(s store.Store) error {
+		return s.Delete(key)
+	})
+}
+
+// InvalidatePrefix removes the cached responses of all the requests
+// whose path starts with the "prefix", i.e "/api/users".
+func InvalidatePrefix(prefix string) error {
+	return invalidateWhere(func(res *entry.Response) bool {
+		return strings.HasPrefix(res.Path(), prefix)
+	})
+}
+
+// InvalidateTag removes all the cached responses which are tagged
+// with at least one of the "tags", see `Tag`.
+func InvalidateTag(tags ...string) error {
+	return invalidateWhere(func(res *entry.Response) bool {
+		for _, tag := range tags {
+			if res.HasTag(tag) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Purge removes all the cached responses from all the stores.
+func Purge() error {
+	return eachStore(func(s store.Store) error {
+		return s.Purge()
+	})
+}
+
+func invalidateWhere(matches func(res *entry.Response) bool) error {
+	return eachStore(func(s store.Store) error {
+		var keys []string
+		err := s.Visit(func(key string, res *entry.Response) bool {
+			if matches(res) {
+				keys = append(keys, key)
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if err = s.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
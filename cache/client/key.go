@@ -0,0 +1,114 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/go-siris/siris/context"
+)
+
+// KeyFunc is the function which returns the key
+// that a cached response is being stored to and retrieved from,
+// based on the incoming request.
+//
+// Requests that produce the same key share the same cached response.
+type KeyFunc func(ctx context.Context) string
+
+// KeyBuilder builds the cache keys of the incoming requests.
+// A key is derived from the request's method, host, path,
+// the selected url query parameters and the values of the "vary" request headers.
+//
+// Use its `Key` as the `Handler#Key`'s input argument.
+type KeyBuilder struct {
+	// if nil then all query parameters are part of the key.
+	queryParams []string
+	ignoreQuery bool
+	varyHeaders []string
+}
+
+// NewKeyBuilder returns a new KeyBuilder which by-default
+// takes all the url query parameters into the key.
+func NewKeyBuilder() *KeyBuilder {
+	return &KeyBuilder{}
+}
+
+// Query sets the url query parameters which are part of the key,
+// the rest are ignored.
+func (k *KeyBuilder) Query(names ...string) *KeyBuilder {
+	k.queryParams = append(k.queryParams, names...)
+	k.ignoreQuery = false
+	return k
+}
+
+// IgnoreQuery excludes the url query from the key.
+func (k *KeyBuilder) IgnoreQuery() *KeyBuilder {
+	k.queryParams = nil
+	k.ignoreQuery = true
+	return k
+}
+
+// Vary adds request header names whose values are part of the key,
+// i.e "Accept-Language", "Accept-Encoding".
+func (k *KeyBuilder) Vary(headers ...string) *KeyBuilder {
+	k.varyHeaders = append(k.varyHeaders, headers...)
+	return k
+}
+
+// Key returns the cache key of the current request, it's a valid `KeyFunc`.
+func (k *KeyBuilder) Key(ctx context.Context) string {
+	r := ctx.Request()
+
+	key := r.Method + " " + ctx.Host() + r.URL.Path
+
+	if !k.ignoreQuery {
+		if q := k.query(r.URL.Query()); q != "" {
+			key += "?" + q
+		}
+	}
+
+	for _, h := range k.varyHeaders {
+		key += "\n" + h + ":" + r.Header.Get(h)
+	}
+
+	return key
+}
+
+func (k *KeyBuilder) query(values url.Values) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	names := k.queryParams
+	if names == nil {
+		names = make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+	} else {
+		names = append([]string(nil), names...)
+	}
+	// the order of the query parameters should not produce different keys.
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		vals, ok := values[name]
+		if !ok {
+			continue
+		}
+		for _, v := range vals {
+			parts = append(parts, url.QueryEscape(name)+"="+url.QueryEscape(v))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// DefaultKeyFunc is the default `KeyFunc` of the cache handlers,
+// it takes the method, host, path and all the url query parameters into the key.
+var DefaultKeyFunc KeyFunc = NewKeyBuilder().Key
@@ -0,0 +1,110 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	stdContext "context"
+	"net/http"
+
+	"github.com/go-siris/siris/cache/cfg"
+	"github.com/go-siris/siris/context"
+)
+
+// revalidationKey is the request's context key which
+// marks the background requests that are refreshing a stale response,
+// it can't be set by a client.
+type revalidationKey struct{}
+
+// isRevalidation reports whether the "r" is a background
+// request which should refresh its cached response.
+func isRevalidation(r *http.Request) bool {
+	v, _ := r.Context().Value(revalidationKey{}).(bool)
+	return v
+}
+
+// credentialHeaders are the request headers which identify a client,
+// they are not sent by the background requests of the shared cached responses.
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// withoutCredentials returns a copy of the "header" without the credentialHeaders.
+func withoutCredentials(header http.Header) http.Header {
+	h := make(http.Header, len(header))
+	for name, values := range header {
+		h[name] = append([]string(nil), values...)
+	}
+	for _, name := range credentialHeaders {
+		h.Del(name)
+	}
+	return h
+}
+
+// isPerClient reports whether the cache key of the current request depends on its credentials,
+// if so the cached response is per-client and its background request keeps them.
+func isPerClient(ctx context.Context, keyFunc KeyFunc, key string) bool {
+	r := ctx.Request()
+	header := r.Header
+	r.Header = withoutCredentials(header)
+	sharedKey := keyFunc(ctx)
+	r.Header = header
+
+	return sharedKey != key
+}
+
+// revalidationRequest returns a copy of the "r" that can be served
+// after the original request's end, as a background request.
+// The credentials of the client are removed unless "keepCredentials" is true.
+func revalidationRequest(r *http.Request, keepCredentials bool) *http.Request {
+	req := new(http.Request)
+	*req = *r
+
+	u := *r.URL
+	req.URL = &u
+	if keepCredentials {
+		req.Header = make(http.Header, len(r.Header))
+		for name, values := range r.Header {
+			req.Header[name] = append([]string(nil), values...)
+		}
+	} else {
+		req.Header = withoutCredentials(r.Header)
+	}
+	req.Body = http.NoBody
+	req.ContentLength = 0
+
+	return req
+}
+
+// revalidation returns a function which serves a copy of the current request
+// through the whole application, so its cached response is refreshed
+// by the cache handler. It's safe to be called after the request's end.
+//
+// The background request is canceled after the cfg.RevalidationTimeout.
+func revalidation(ctx context.Context, keepCredentials bool) func() {
+	app, req := ctx.Application(), revalidationRequest(ctx.Request(), keepCredentials)
+	return func() {
+		reqCtx, cancel := stdContext.WithTimeout(stdContext.Background(), cfg.RevalidationTimeout)
+		defer cancel()
+
+		app.ServeHTTP(&discardResponseWriter{}, req.WithContext(stdContext.WithValue(reqCtx, revalidationKey{}, true)))
+	}
+}
+
+// discardResponseWriter is the response writer of the background requests,
+// the response is only stored to the cache.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = make(http.Header)
+	}
+	return w.header
+}
+
+func (w *discardResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *discardResponseWriter) WriteHeader(int) {}
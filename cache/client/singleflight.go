@@ -0,0 +1,69 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import "sync"
+
+// flightGroup coalesces the concurrent executions of the same key,
+// only one of them is executed and the rest are waiting for it to finish.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*sync.WaitGroup
+}
+
+// start marks the "key" as in-flight and returns true,
+// if it's already in-flight then it returns its wait group and false.
+func (g *flightGroup) start(key string) (*sync.WaitGroup, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.calls == nil {
+		g.calls = make(map[string]*sync.WaitGroup)
+	}
+	if wg, ok := g.calls[key]; ok {
+		return wg, false
+	}
+
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	g.calls[key] = wg
+	return wg, true
+}
+
+func (g *flightGroup) done(key string, wg *sync.WaitGroup) {
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	wg.Done()
+}
+
+// Do executes the "fn" if there is no other execution for the "key" in-flight,
+// otherwise it waits for the in-flight one to finish.
+// Returns true if the "fn" was executed by the caller.
+func (g *flightGroup) Do(key string, fn func()) bool {
+	wg, started := g.start(key)
+	if !started {
+		wg.Wait()
+		return false
+	}
+
+	defer g.done(key, wg)
+	fn()
+	return true
+}
+
+// Go executes the "fn" in its own goroutine if there is no other
+// execution for the "key" in-flight, it never waits.
+func (g *flightGroup) Go(key string, fn func()) {
+	wg, started := g.start(key)
+	if !started {
+		return
+	}
+
+	go func() {
+		defer g.done(key, wg)
+		fn()
+	}()
+}
@@ -6,9 +6,11 @@ package client
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-siris/siris/cache/entry"
+	"github.com/go-siris/siris/context"
 )
 
 // GetMaxAge parses the "Cache-Control" header
@@ -22,3 +24,65 @@ func GetMaxAge(r *http.Request) entry.LifeChanger {
 		return time.Duration(headerCacheDur) * time.Second
 	}
 }
+
+// filterHeaders returns a copy of the "headers" without the "ignored" ones.
+func filterHeaders(headers http.Header, ignored []string) http.Header {
+	filtered := make(http.Header, len(headers))
+	for name, values := range headers {
+		filtered[name] = append([]string(nil), values...)
+	}
+
+	for _, name := range ignored {
+		filtered.Del(name)
+	}
+	return filtered
+}
+
+// notModified reports whether the client's conditional request headers
+// match the cached response's validators, the "ETag" and the "Last-Modified".
+//
+// The "If-None-Match" takes precedence over the "If-Modified-Since", as the RFC 7232 describes.
+func notModified(ctx context.Context, res *entry.Response) bool {
+	method := ctx.Method()
+	if method != http.MethodGet && method != http.MethodHead {
+		return false
+	}
+
+	if inm := ctx.GetHeader("If-None-Match"); inm != "" {
+		etag := res.Headers().Get("ETag")
+		return etag != "" && etagMatch(inm, etag)
+	}
+
+	if ims := ctx.GetHeader("If-Modified-Since"); ims != "" {
+		lastModified := res.Headers().Get("Last-Modified")
+		if lastModified == "" {
+			return false
+		}
+
+		timeFormat := ctx.Application().ConfigurationReadOnly().GetTimeFormat()
+		since, err := time.Parse(timeFormat, ims)
+		if err != nil {
+			return false
+		}
+		modtime, err := time.Parse(timeFormat, lastModified)
+		if err != nil {
+			return false
+		}
+		return !modtime.After(since)
+	}
+
+	return false
+}
+
+// etagMatch reports whether the "etag" is one of the
+// "If-None-Match" header's list, using the weak comparison.
+func etagMatch(ifNoneMatch string, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
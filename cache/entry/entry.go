@@ -5,6 +5,7 @@
 package entry
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/go-siris/siris/cache/cfg"
@@ -19,9 +20,6 @@ type Entry struct {
 
 	// Response the response should be served to the client
 	response *Response
-	// but we need the key to invalidate manually...xmm
-	// let's see for that later, maybe we make a slice instead
-	// of store map
 }
 
 // NewEntry returns a new cache entry
@@ -40,6 +38,29 @@ func NewEntry(duration time.Duration) *Entry {
 	}
 }
 
+// NewEntryWithResponse returns a new cache entry
+// which serves the "res" for the next "life" duration.
+//
+// Unlike `NewEntry` the "life" is not checked against the MinimumCacheDuration,
+// it's used by the stores which are already know the final expiration of their entries.
+func NewEntryWithResponse(res *Response, life time.Duration) *Entry {
+	return &Entry{
+		life:      life,
+		expiresAt: time.Now().Add(life),
+		response:  res,
+	}
+}
+
+// Life returns the life duration of the cached response.
+func (e *Entry) Life() time.Duration {
+	return e.life
+}
+
+// ExpiresAt returns the time which the cached response expires.
+func (e *Entry) ExpiresAt() time.Time {
+	return e.expiresAt
+}
+
 // Response gets the cache response contents
 // if it's valid returns them with a true value
 // otherwise returns nil, false
@@ -84,8 +105,11 @@ func (e *Entry) ChangeLifetime(fdur LifeChanger) {
 
 // Reset called each time the entry is expired
 // and the handler calls this after the original handler executed
-// to re-set the response with the new handler's content result
-func (e *Entry) Reset(statusCode int, contentType string,
+// to re-set the response with the new handler's content result.
+//
+// The "headers" are stored as they are, the caller
+// is responsible to filter and copy them.
+func (e *Entry) Reset(statusCode int, headers http.Header,
 	body []byte, lifeChanger LifeChanger) {
 
 	if e.response == nil {
@@ -95,10 +119,7 @@ func (e *Entry) Reset(statusCode int, contentType string,
 		e.response.statusCode = statusCode
 	}
 
-	if contentType != "" {
-		e.response.contentType = contentType
-	}
-
+	e.response.headers = headers
 	e.response.body = body
 	// check if a given life changer provided
 	// and if it does then execute the change life time
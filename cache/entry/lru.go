@@ -0,0 +1,130 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package entry
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LRU is a bounded, keyed, collection of cache entries.
+// When the collection is full the least recently used entry
+// is evicted in order to make room for the new one.
+//
+// It's safe for concurrent use.
+type LRU struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	entries    map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	entry *Entry
+}
+
+// NewLRU returns a new empty LRU which
+// can keep up to "maxEntries" entries,
+// if "maxEntries" <= 0 then the collection is unbounded.
+func NewLRU(maxEntries int) *LRU {
+	return &LRU{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry based on its key
+// and marks it as the most recently used one.
+// Returns nil, false if not found.
+func (l *LRU) Get(key string) (*Entry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.entries[key]; ok {
+		l.ll.MoveToFront(el)
+		return el.Value.(*lruItem).entry, true
+	}
+	return nil, false
+}
+
+// Set stores the "e" entry under the key, it replaces any existing entry
+// and marks it as the most recently used one.
+// The least recently used entry is evicted if the collection is full.
+func (l *LRU) Set(key string, e *Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.entries[key]; ok {
+		el.Value.(*lruItem).entry = e
+		l.ll.MoveToFront(el)
+		return
+	}
+
+	l.entries[key] = l.ll.PushFront(&lruItem{key: key, entry: e})
+	if l.maxEntries > 0 && l.ll.Len() > l.maxEntries {
+		l.removeOldest()
+	}
+}
+
+// Remove removes an entry based on its key.
+// Returns true if removed, otherwise false.
+func (l *LRU) Remove(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.entries[key]; ok {
+		l.removeElement(el)
+		return true
+	}
+	return false
+}
+
+// Visit calls the "visitor" for each one of the entries,
+// from the most to the least recently used one, until it returns false.
+//
+// The "visitor" is allowed to modify the collection.
+func (l *LRU) Visit(visitor func(key string, e *Entry) bool) {
+	l.mu.Lock()
+	items := make([]lruItem, 0, l.ll.Len())
+	for el := l.ll.Front(); el != nil; el = el.Next() {
+		items = append(items, *el.Value.(*lruItem))
+	}
+	l.mu.Unlock()
+
+	for _, item := range items {
+		if !visitor(item.key, item.entry) {
+			return
+		}
+	}
+}
+
+// Purge removes all the entries.
+func (l *LRU) Purge() {
+	l.mu.Lock()
+	l.ll.Init()
+	l.entries = make(map[string]*list.Element)
+	l.mu.Unlock()
+}
+
+// Len returns the number of the stored entries.
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	n := l.ll.Len()
+	l.mu.Unlock()
+	return n
+}
+
+func (l *LRU) removeOldest() {
+	if el := l.ll.Back(); el != nil {
+		l.removeElement(el)
+	}
+}
+
+func (l *LRU) removeElement(el *list.Element) {
+	l.ll.Remove(el)
+	delete(l.entries, el.Value.(*lruItem).key)
+}
@@ -4,6 +4,15 @@
 
 package entry
 
+import (
+	"bytes"
+	"encoding/gob"
+	"net/http"
+	"time"
+
+	"github.com/go-siris/siris/cache/cfg"
+)
+
 // Response is the cached response will be send to the clients
 // its fields set at runtime on each of the non-cached executions
 // non-cached executions = first execution, and each time after
@@ -11,10 +20,31 @@ package entry
 type Response struct {
 	// statusCode for the response cache handler
 	statusCode int
-	// contentType for the response cache handler
-	contentType string
+	// headers for the response cache handler,
+	// the content type is one of them
+	headers http.Header
 	// body is the contents will be served by the cache handler
 	body []byte
+	// path is the request path which produced the response,
+	// used to invalidate the cached responses by a path prefix
+	path string
+	// tags are the custom tags of the response,
+	// used to invalidate the cached responses by tag
+	tags []string
+	// expires is the time which the response becomes stale,
+	// a store may keep it longer in order to be served while it's revalidated
+	expires time.Time
+}
+
+// NewResponse returns a new cached response
+// based on the status code, the headers and the body of
+// a handler's response.
+func NewResponse(statusCode int, headers http.Header, body []byte) *Response {
+	return &Response{
+		statusCode: statusCode,
+		headers:    headers,
+		body:       body,
+	}
 }
 
 // StatusCode returns a valid status code
@@ -27,13 +57,116 @@ func (r *Response) StatusCode() int {
 
 // ContentType returns a valid content type
 func (r *Response) ContentType() string {
-	if r.contentType == "" {
-		r.contentType = "text/html; charset=utf-8"
+	if contentType := r.headers.Get(cfg.ContentTypeHeader); contentType != "" {
+		return contentType
 	}
-	return r.contentType
+	return cfg.ContentHTML
+}
+
+// Headers returns the headers will be served by the cache handler,
+// they should not be modified.
+func (r *Response) Headers() http.Header {
+	return r.headers
 }
 
 // Body returns contents will be served by the cache handler
 func (r *Response) Body() []byte {
 	return r.body
 }
+
+// Path returns the request path which produced the response.
+func (r *Response) Path() string {
+	return r.path
+}
+
+// SetPath sets the request path which produced the response,
+// it should be called before the response is stored.
+func (r *Response) SetPath(path string) {
+	r.path = path
+}
+
+// Tags returns the tags of the response.
+func (r *Response) Tags() []string {
+	return r.tags
+}
+
+// SetTags sets the tags of the response,
+// it should be called before the response is stored.
+func (r *Response) SetTags(tags []string) {
+	r.tags = tags
+}
+
+// HasTag reports whether the response is tagged with the "tag".
+func (r *Response) HasTag(tag string) bool {
+	for _, t := range r.tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Expires returns the time which the response becomes stale.
+func (r *Response) Expires() time.Time {
+	return r.expires
+}
+
+// SetExpires sets the time which the response becomes stale,
+// it should be called before the response is stored.
+func (r *Response) SetExpires(expires time.Time) {
+	r.expires = expires
+}
+
+// Staleness returns the duration since the response became stale,
+// zero means that the response is still fresh.
+func (r *Response) Staleness() time.Duration {
+	if r.expires.IsZero() {
+		return 0
+	}
+	if d := time.Since(r.expires); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// binaryResponse is the exported form of the Response,
+// used to encode and decode it for the remote and the persistent stores.
+type binaryResponse struct {
+	StatusCode int
+	Headers    http.Header
+	Body       []byte
+	Path       string
+	Tags       []string
+	Expires    time.Time
+}
+
+// MarshalBinary encodes the response, it implements the encoding.BinaryMarshaler.
+func (r *Response) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(binaryResponse{
+		StatusCode: r.statusCode,
+		Headers:    r.headers,
+		Body:       r.body,
+		Path:       r.path,
+		Tags:       r.tags,
+		Expires:    r.expires,
+	})
+	return buf.Bytes(), err
+}
+
+// UnmarshalBinary decodes a response which was encoded by the MarshalBinary,
+// it implements the encoding.BinaryUnmarshaler.
+func (r *Response) UnmarshalBinary(data []byte) error {
+	var b binaryResponse
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&b); err != nil {
+		return err
+	}
+
+	r.statusCode = b.StatusCode
+	r.headers = b.Headers
+	r.body = b.Body
+	r.path = b.Path
+	r.tags = b.Tags
+	r.expires = b.Expires
+	return nil
+}
@@ -0,0 +1,197 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package store
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-siris/siris/cache/entry"
+)
+
+// FileExtension is the extension of the files
+// which the filesystem stores are writing the cached responses to.
+var FileExtension = ".cache"
+
+// the file starts with the expiration time, as unix nanoseconds,
+// and the length of the key, followed by the key and the encoded response.
+const fileHeaderLen = 8 + 4
+
+type filesystemStore struct {
+	dir string
+}
+
+var _ Store = (*filesystemStore)(nil)
+
+// NewFilesystem returns a new store which keeps
+// each cached response to its own file inside the "dir" directory,
+// the directory is created if it does not exist.
+//
+// The cached responses are kept between restarts and they
+// can be shared between instances that have access to the same directory.
+func NewFilesystem(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
+		return nil, err
+	}
+	return &filesystemStore{dir: dir}, nil
+}
+
+func (s *filesystemStore) filename(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+FileExtension)
+}
+
+// readFile returns the key, the expiration time and
+// the encoded response of a cache file.
+func readFile(filename string) (string, time.Time, []byte, bool) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil || len(b) < fileHeaderLen {
+		return "", time.Time{}, nil, false
+	}
+
+	expiresAt := time.Unix(0, int64(binary.BigEndian.Uint64(b[:8])))
+	keyLen := int(binary.BigEndian.Uint32(b[8:fileHeaderLen]))
+	if len(b) < fileHeaderLen+keyLen {
+		return "", time.Time{}, nil, false
+	}
+
+	key := string(b[fileHeaderLen : fileHeaderLen+keyLen])
+	return key, expiresAt, b[fileHeaderLen+keyLen:], true
+}
+
+func (s *filesystemStore) read(key string) (time.Time, []byte, bool) {
+	storedKey, expiresAt, b, ok := readFile(s.filename(key))
+	if !ok || storedKey != key {
+		return time.Time{}, nil, false
+	}
+
+	if !time.Now().Before(expiresAt) {
+		s.Delete(key)
+		return time.Time{}, nil, false
+	}
+	return expiresAt, b, true
+}
+
+func (s *filesystemStore) Get(key string) (*entry.Response, bool) {
+	_, b, ok := s.read(key)
+	if !ok {
+		return nil, false
+	}
+
+	res := new(entry.Response)
+	if err := res.UnmarshalBinary(b); err != nil {
+		return nil, false
+	}
+	return res, true
+}
+
+func (s *filesystemStore) Set(key string, res *entry.Response, ttl time.Duration) error {
+	b, err := res.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, fileHeaderLen, fileHeaderLen+len(key)+len(b))
+	binary.BigEndian.PutUint64(header[:8], uint64(time.Now().Add(ttl).UnixNano()))
+	binary.BigEndian.PutUint32(header[8:], uint32(len(key)))
+	header = append(header, key...)
+
+	// write to a temporary file first and rename it after,
+	// so readers never see a partially written response.
+	f, err := ioutil.TempFile(s.dir, "tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(header, b...))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), s.filename(key))
+}
+
+func (s *filesystemStore) Delete(key string) error {
+	err := os.Remove(s.filename(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (s *filesystemStore) Purge() error {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// nothing is stored yet or the directory was removed.
+			return nil
+		}
+		return err
+	}
+
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), FileExtension) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, f.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *filesystemStore) TTL(key string) (time.Duration, bool) {
+	expiresAt, _, ok := s.read(key)
+	if !ok {
+		return 0, false
+	}
+	return expiresAt.Sub(time.Now()), true
+}
+
+func (s *filesystemStore) Visit(visitor func(key string, res *entry.Response) bool) error {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// nothing is stored yet or the directory was removed.
+			return nil
+		}
+		return err
+	}
+
+	now := time.Now()
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), FileExtension) {
+			continue
+		}
+
+		filename := filepath.Join(s.dir, f.Name())
+		key, expiresAt, b, ok := readFile(filename)
+		if !ok {
+			continue
+		}
+		if !now.Before(expiresAt) {
+			os.Remove(filename)
+			continue
+		}
+
+		res := new(entry.Response)
+		if err = res.UnmarshalBinary(b); err != nil {
+			continue
+		}
+		if !visitor(key, res) {
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package store
+
+import (
+	"time"
+
+	"github.com/go-siris/siris/cache/entry"
+)
+
+type memoryStore struct {
+	entries *entry.LRU
+}
+
+var _ Store = (*memoryStore)(nil)
+
+// NewMemory returns a new in-memory store which
+// keeps up to "maxEntries" responses, the least recently used are evicted first.
+// Zero or negative value means no limit.
+func NewMemory(maxEntries int) Store {
+	return &memoryStore{entries: entry.NewLRU(maxEntries)}
+}
+
+func (s *memoryStore) Get(key string) (*entry.Response, bool) {
+	e, ok := s.entries.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	res, valid := e.Response()
+	if !valid {
+		s.entries.Remove(key)
+		return nil, false
+	}
+	return res, true
+}
+
+func (s *memoryStore) Set(key string, res *entry.Response, ttl time.Duration) error {
+	s.entries.Set(key, entry.NewEntryWithResponse(res, ttl))
+	return nil
+}
+
+func (s *memoryStore) Delete(key string) error {
+	s.entries.Remove(key)
+	return nil
+}
+
+func (s *memoryStore) Purge() error {
+	s.entries.Purge()
+	return nil
+}
+
+func (s *memoryStore) TTL(key string) (time.Duration, bool) {
+	e, ok := s.entries.Get(key)
+	if !ok {
+		return 0, false
+	}
+
+	ttl := e.ExpiresAt().Sub(time.Now())
+	if ttl <= 0 {
+		return 0, false
+	}
+	return ttl, true
+}
+
+func (s *memoryStore) Visit(visitor func(key string, res *entry.Response) bool) error {
+	s.entries.Visit(func(key string, e *entry.Entry) bool {
+		res, valid := e.Response()
+		if !valid {
+			s.entries.Remove(key)
+			return true
+		}
+		return visitor(key, res)
+	})
+	return nil
+}
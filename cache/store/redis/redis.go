@@ -0,0 +1,175 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package redis provides a cache store which keeps the cached responses
+// to a redis server, so they can be shared between instances.
+//
+// depend on github.com/garyburd/redigo/redis
+//
+// Usage:
+//
+//	pool := &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", "127.0.0.1:6379") }}
+//	app.Get("/", cache.Cache(h, 10*time.Second).Store(cacheredis.New(pool, "")).ServeHTTP)
+package redis
+
+import (
+	"strings"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/go-siris/siris/cache/entry"
+	"github.com/go-siris/siris/cache/store"
+)
+
+// DefaultPrefix is the prefix of the redis keys
+// which the cached responses are stored to, if not any other passed on `New`.
+var DefaultPrefix = "siris.cache:"
+
+// Store is the redis cache store.
+type Store struct {
+	pool   *redis.Pool
+	prefix string
+}
+
+var _ store.Store = (*Store)(nil)
+
+// New returns a new redis cache store which uses the "pool"'s connections,
+// the same pool can be shared with the redis session provider.
+//
+// All keys are prefixed by the "prefix", if empty then the `DefaultPrefix` is used.
+func New(pool *redis.Pool, prefix string) *Store {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	return &Store{pool: pool, prefix: prefix}
+}
+
+// Get returns the cached response based on its key.
+func (s *Store) Get(key string) (*entry.Response, bool) {
+	c := s.pool.Get()
+	defer c.Close()
+
+	b, err := redis.Bytes(c.Do("GET", s.prefix+key))
+	if err != nil {
+		return nil, false
+	}
+
+	res := new(entry.Response)
+	if err = res.UnmarshalBinary(b); err != nil {
+		return nil, false
+	}
+	return res, true
+}
+
+// Set stores the "res" under the key for the next "ttl" duration.
+func (s *Store) Set(key string, res *entry.Response, ttl time.Duration) error {
+	b, err := res.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	ms := int64(ttl / time.Millisecond)
+	if ms <= 0 {
+		return nil
+	}
+
+	c := s.pool.Get()
+	defer c.Close()
+
+	_, err = c.Do("SET", s.prefix+key, b, "PX", ms)
+	return err
+}
+
+// Delete removes the cached response based on its key.
+func (s *Store) Delete(key string) error {
+	c := s.pool.Get()
+	defer c.Close()
+
+	_, err := c.Do("DEL", s.prefix+key)
+	return err
+}
+
+// scan calls the "fn" for each batch of the redis keys
+// which are stored under the store's prefix, until it returns false.
+func (s *Store) scan(c redis.Conn, fn func(keys []interface{}) (bool, error)) error {
+	cursor := 0
+	for {
+		values, err := redis.Values(c.Do("SCAN", cursor, "MATCH", s.prefix+"*", "COUNT", 100))
+		if err != nil {
+			return err
+		}
+
+		var keys []interface{}
+		if _, err = redis.Scan(values, &cursor, &keys); err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if next, err := fn(keys); err != nil || !next {
+				return err
+			}
+		}
+
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// Purge removes all the cached responses which are stored under the store's prefix.
+func (s *Store) Purge() error {
+	c := s.pool.Get()
+	defer c.Close()
+
+	return s.scan(c, func(keys []interface{}) (bool, error) {
+		_, err := c.Do("DEL", keys...)
+		return err == nil, err
+	})
+}
+
+// TTL returns the remaining life of the cached response based on its key.
+func (s *Store) TTL(key string) (time.Duration, bool) {
+	c := s.pool.Get()
+	defer c.Close()
+
+	ms, err := redis.Int64(c.Do("PTTL", s.prefix+key))
+	// -2 means that the key does not exist and -1 that it has no expiration,
+	// the latter should never happen because the keys are always stored with a ttl.
+	if err != nil || ms < 0 {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// Visit calls the "visitor" for each one of the cached responses
+// which are stored under the store's prefix, until it returns false.
+func (s *Store) Visit(visitor func(key string, res *entry.Response) bool) error {
+	c := s.pool.Get()
+	defer c.Close()
+
+	return s.scan(c, func(keys []interface{}) (bool, error) {
+		values, err := redis.ByteSlices(c.Do("MGET", keys...))
+		if err != nil {
+			return false, err
+		}
+
+		for i, b := range values {
+			// expired between the SCAN and the MGET.
+			if b == nil {
+				continue
+			}
+
+			res := new(entry.Response)
+			if err = res.UnmarshalBinary(b); err != nil {
+				continue
+			}
+
+			key, _ := redis.String(keys[i], nil)
+			if !visitor(strings.TrimPrefix(key, s.prefix), res) {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
@@ -0,0 +1,39 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package store contains the storage backends of the cached responses.
+package store
+
+import (
+	"time"
+
+	"github.com/go-siris/siris/cache/entry"
+)
+
+// Store is the storage backend of the cached responses.
+// The memory store is the default one,
+// an application which runs on more than one instance can
+// use a persistent or a remote store in order to share the cached responses between them.
+//
+// Implementations should be safe for concurrent use.
+type Store interface {
+	// Get returns the cached response based on its key.
+	// Returns nil, false if not found or expired.
+	Get(key string) (*entry.Response, bool)
+	// Set stores the "res" under the key for the next "ttl" duration.
+	Set(key string, res *entry.Response, ttl time.Duration) error
+	// Delete removes the cached response based on its key.
+	Delete(key string) error
+	// Purge removes all the cached responses of the store.
+	Purge() error
+	// TTL returns the remaining life of the cached response based on its key.
+	// Returns 0, false if not found or expired.
+	TTL(key string) (time.Duration, bool)
+	// Visit calls the "visitor" for each one of the non-expired
+	// cached responses until it returns false.
+	// The "visitor" is allowed to delete the visited responses.
+	//
+	// Used to invalidate the cached responses by request path prefix or by tag.
+	Visit(visitor func(key string, res *entry.Response) bool) error
+}
@@ -39,6 +39,11 @@ var WithoutBanner = func(app *Application) {
 	app.config.DisableBanner = true
 }
 
+// WithoutRouteTable turns off the write of the route table on server startup.
+var WithoutRouteTable = func(app *Application) {
+	app.config.DisableRouteTable = true
+}
+
 // WithoutInterruptHandler disables the automatic graceful server shutdown
 // when control/cmd+C pressed.
 var WithoutInterruptHandler = func(app *Application) {
@@ -87,6 +92,20 @@ var WithFireMethodNotAllowed = func(app *Application) {
 	app.config.FireMethodNotAllowed = true
 }
 
+// WithoutAutoHead disables the AutoHead setting.
+//
+// See `Configuration`.
+var WithoutAutoHead = func(app *Application) {
+	app.config.DisableAutoHead = true
+}
+
+// WithoutAutoOptions disables the AutoOptions setting.
+//
+// See `Configuration`.
+var WithoutAutoOptions = func(app *Application) {
+	app.config.DisableAutoOptions = true
+}
+
 // WithTimeFormat sets the TimeFormat setting.
 //
 // See `Configuration`.
@@ -141,6 +160,25 @@ func WithoutRemoteAddrHeader(headerName string) Configurator {
 	}
 }
 
+// WithRemoteAddrHeadersOrder sets the precedence of the enabled remote address headers.
+//
+// Look `context.RemoteAddr()` for more.
+func WithRemoteAddrHeadersOrder(headerNames ...string) Configurator {
+	return func(app *Application) {
+		app.config.RemoteAddrHeadersOrder = headerNames
+	}
+}
+
+// WithTrustedProxies adds IPs or CIDR ranges, i.e "10.0.0.0/8",
+// of the reverse proxies in front of the server to the TrustedProxies setting.
+//
+// Look `context.RemoteAddr()`, `context.Scheme()` and `context.Host()` for more.
+func WithTrustedProxies(proxies ...string) Configurator {
+	return func(app *Application) {
+		app.config.TrustedProxies = append(app.config.TrustedProxies, proxies...)
+	}
+}
+
 // WithOtherValue adds a value based on a key to the Other setting.
 //
 // See `Configuration`.
@@ -177,6 +215,10 @@ func WithConfiguration(c configuration.Configuration) Configurator {
 			main.DisableBanner = v
 		}
 
+		if v := c.DisableRouteTable; v {
+			main.DisableRouteTable = v
+		}
+
 		if v := c.DisableInterruptHandler; v {
 			main.DisableInterruptHandler = v
 		}
@@ -193,6 +235,14 @@ func WithConfiguration(c configuration.Configuration) Configurator {
 			main.FireMethodNotAllowed = v
 		}
 
+		if v := c.DisableAutoHead; v {
+			main.DisableAutoHead = v
+		}
+
+		if v := c.DisableAutoOptions; v {
+			main.DisableAutoOptions = v
+		}
+
 		if v := c.DisableBodyConsumptionOnUnmarshal; v {
 			main.DisableBodyConsumptionOnUnmarshal = v
 		}
@@ -234,6 +284,14 @@ func WithConfiguration(c configuration.Configuration) Configurator {
 			}
 		}
 
+		if v := c.RemoteAddrHeadersOrder; len(v) > 0 {
+			main.RemoteAddrHeadersOrder = v
+		}
+
+		if v := c.TrustedProxies; len(v) > 0 {
+			main.TrustedProxies = v
+		}
+
 		if v := c.Other; len(v) > 0 {
 			if main.Other == nil {
 				main.Other = make(map[string]interface{})
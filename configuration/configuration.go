@@ -6,7 +6,9 @@ package configuration
 
 import (
 	"io/ioutil"
+	"net"
 	"path/filepath"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v2"
@@ -14,7 +16,10 @@ import (
 	"github.com/go-siris/siris/core/errors"
 )
 
-var errConfigurationDecode = errors.New("error while trying to decode configuration")
+var (
+	errConfigurationDecode = errors.New("error while trying to decode configuration")
+	errTrustedProxy        = errors.New("invalid trusted proxy '%s', expected an IP or a CIDR range")
+)
 
 // YAML reads Configuration from a configuration.yml file.
 //
@@ -112,6 +117,12 @@ type Configuration struct {
 	// Defaults to false.
 	DisableBanner bool `yaml:"DisableBanner" toml:"DisableBanner"`
 
+	// DisableRouteTable if set to true then it turns off the write of the route table
+	// next to the banner on server startup.
+	//
+	// Defaults to false.
+	DisableRouteTable bool `yaml:"DisableRouteTable" toml:"DisableRouteTable"`
+
 	// DisableInterruptHandler if set to true then it disables the automatic graceful server shutdown
 	// when control/cmd+C pressed.
 	// Turn this to true if you're planning to handle this by your own via a custom host.Task.
@@ -145,6 +156,24 @@ type Configuration struct {
 	// Defaults to false.
 	FireMethodNotAllowed bool `yaml:"FireMethodNotAllowed" toml:"FireMethodNotAllowed"`
 
+	// DisableAutoHead if set to true then the router stops serving the HEAD requests
+	// through the GET routes, a HEAD route should be registered explicitly.
+	//
+	// By-default a HEAD request to a path that has only a GET route
+	// is served by the GET handlers, the headers are sent without the body.
+	//
+	// Defaults to false.
+	DisableAutoHead bool `yaml:"DisableAutoHead" toml:"DisableAutoHead"`
+
+	// DisableAutoOptions if set to true then the router stops answering the OPTIONS requests
+	// automatically, an OPTIONS route should be registered explicitly.
+	//
+	// By-default an OPTIONS request to a path that has no OPTIONS route
+	// is answered with 200 and an "Allow" header of all the registered methods for that path.
+	//
+	// Defaults to false.
+	DisableAutoOptions bool `yaml:"DisableAutoOptions" toml:"DisableAutoOptions"`
+
 	// DisableBodyConsumptionOnUnmarshal manages the reading behavior of the context's body readers/binders.
 	// If set to true then it
 	// disables the body consumption by the `context.UnmarshalBody/ReadJSON/ReadXML`.
@@ -226,6 +255,33 @@ type Configuration struct {
 	// Look `context.RemoteAddr()` for more.
 	RemoteAddrHeaders map[string]bool `yaml:"RemoteAddrHeaders" toml:"RemoteAddrHeaders"`
 
+	// RemoteAddrHeadersOrder is the precedence of the enabled RemoteAddrHeaders,
+	// the first enabled header which resolves to an IP wins.
+	// Enabled headers that are missing from this list are checked after, by name.
+	//
+	// Defaults to:
+	// "CF-Connecting-IP", "X-Real-Ip", "X-Forwarded-For", "Forwarded"
+	//
+	// Look `context.RemoteAddr()` for more.
+	RemoteAddrHeadersOrder []string `yaml:"RemoteAddrHeadersOrder" toml:"RemoteAddrHeadersOrder"`
+
+	// TrustedProxies are the IPs or CIDR ranges of the reverse proxies
+	// in front of the server, i.e "10.0.0.0/8" or "127.0.0.1".
+	//
+	// If not empty then the RemoteAddrHeaders are used only when the request comes from a trusted proxy,
+	// the "X-Forwarded-For" and "Forwarded" headers are walked from right to left skipping the trusted hops,
+	// and the "X-Forwarded-Proto"/"X-Forwarded-Host" headers are used by the `context.Scheme()` and `context.Host()`.
+	//
+	// If empty then the enabled RemoteAddrHeaders are trusted from any peer
+	// and the "X-Forwarded-Proto"/"X-Forwarded-Host" headers are ignored.
+	//
+	// They're parsed once, on the Application's Build, which fails on an invalid entry.
+	//
+	// Defaults to empty.
+	TrustedProxies []string `yaml:"TrustedProxies" toml:"TrustedProxies"`
+	// trustedProxyNets are the parsed TrustedProxies, see `ParseTrustedProxies`.
+	trustedProxyNets []*net.IPNet
+
 	// Other are the custom, dynamic options, can be empty.
 	// This field used only by you to set any app's options you want
 	// or by custom adaptors, it's a way to simple communicate between your adaptors (if any)
@@ -241,12 +297,15 @@ type Configuration struct {
 func (c Configuration) GetVHost() string {
 	return c.vhost
 }
-func (c Configuration) SetVHost(vhost string) {
-	if !c.vhostSet && c.vhost != "" {
+
+// SetVHost sets the non-exported vhost config field, once.
+// It's being called automatically by the `Application#Run` and it's
+// used by the router for wildcard subdomains and by the `Application#URL`.
+func (c *Configuration) SetVHost(vhost string) {
+	if !c.vhostSet && vhost != "" {
 		c.vhost = vhost
 		c.vhostSet = true
 	}
-	return
 }
 
 // GetDisablePathCorrection returns the configuration.DisablePathCorrection,
@@ -281,6 +340,18 @@ func (c Configuration) GetFireMethodNotAllowed() bool {
 	return c.FireMethodNotAllowed
 }
 
+// GetDisableAutoHead returns the configuration.DisableAutoHead,
+// returns true when the HEAD requests are not served by the GET routes.
+func (c Configuration) GetDisableAutoHead() bool {
+	return c.DisableAutoHead
+}
+
+// GetDisableAutoOptions returns the configuration.DisableAutoOptions,
+// returns true when the OPTIONS requests are not answered automatically.
+func (c Configuration) GetDisableAutoOptions() bool {
+	return c.DisableAutoOptions
+}
+
 // GetDisableBodyConsumptionOnUnmarshal returns the configuration.DisableBodyConsumptionOnUnmarshal,
 // manages the reading behavior of the context's body readers/binders.
 // If returns true then the body consumption by the `context.UnmarshalBody/ReadJSON/ReadXML`
@@ -362,6 +433,85 @@ func (c Configuration) GetRemoteAddrHeaders() map[string]bool {
 	return c.RemoteAddrHeaders
 }
 
+// GetRemoteAddrHeadersOrder returns the precedence of the enabled RemoteAddrHeaders.
+//
+// Look `context.RemoteAddr()` for more.
+func (c Configuration) GetRemoteAddrHeadersOrder() []string {
+	return c.RemoteAddrHeadersOrder
+}
+
+// GetTrustedProxies returns the IPs or CIDR ranges of the trusted reverse proxies.
+//
+// Look `context.RemoteAddr()`, `context.Scheme()` and `context.Host()` for more.
+func (c Configuration) GetTrustedProxies() []string {
+	return c.TrustedProxies
+}
+
+// GetTrustedProxyNets returns the parsed TrustedProxies,
+// they're parsed once by the `ParseTrustedProxies` which the Application's Build calls.
+//
+// Look `context.RemoteAddr()`, `context.Scheme()` and `context.Host()` for more.
+func (c Configuration) GetTrustedProxyNets() []*net.IPNet {
+	if c.trustedProxyNets == nil && len(c.TrustedProxies) > 0 {
+		// not parsed yet, the invalid entries are skipped.
+		nets, _ := parseTrustedProxies(c.TrustedProxies)
+		return nets
+	}
+	return c.trustedProxyNets
+}
+
+// ParseTrustedProxies parses the IPs and CIDR ranges of the TrustedProxies,
+// it's called by the Application's Build, after the configuration is applied.
+//
+// Returns an error for the first invalid entry.
+func (c *Configuration) ParseTrustedProxies() error {
+	nets, err := parseTrustedProxies(c.TrustedProxies)
+	if err != nil {
+		return err
+	}
+	c.trustedProxyNets = nets
+	return nil
+}
+
+// parseTrustedProxies returns the networks of the valid "proxies"
+// and the error of the first invalid one, if any.
+func parseTrustedProxies(proxies []string) (nets []*net.IPNet, err error) {
+	for _, p := range proxies {
+		n, parseErr := parseTrustedProxy(p)
+		if parseErr != nil {
+			if err == nil {
+				err = parseErr
+			}
+			continue
+		}
+		nets = append(nets, n)
+	}
+	return
+}
+
+// parseTrustedProxy parses an IP or a CIDR range,
+// a single IP is a network of its own.
+func parseTrustedProxy(proxy string) (*net.IPNet, error) {
+	cidr := strings.TrimSpace(proxy)
+	if !strings.Contains(cidr, "/") {
+		ip := net.ParseIP(cidr)
+		if ip == nil {
+			return nil, errTrustedProxy.Format(proxy)
+		}
+		if ip.To4() != nil {
+			cidr += "/32"
+		} else {
+			cidr += "/128"
+		}
+	}
+
+	_, n, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, errTrustedProxy.Format(proxy)
+	}
+	return n, nil
+}
+
 // GetOther returns the configuration.Other map.
 func (c Configuration) GetOther() map[string]interface{} {
 	return c.Other
@@ -373,10 +523,13 @@ func DefaultConfiguration() Configuration {
 		EnableReuseport:                   false,
 		EnableQUICSupport:                 false,
 		DisableBanner:                     false,
+		DisableRouteTable:                 false,
 		DisableInterruptHandler:           false,
 		DisablePathCorrection:             false,
 		EnablePathEscape:                  false,
 		FireMethodNotAllowed:              false,
+		DisableAutoHead:                   false,
+		DisableAutoOptions:                false,
 		DisableBodyConsumptionOnUnmarshal: false,
 		DisableAutoFireStatusCode:         false,
 		TimeFormat:                        "Mon, Jan 02 2006 15:04:05 GMT",
@@ -390,6 +543,7 @@ func DefaultConfiguration() Configuration {
 			"X-Forwarded-For":  false,
 			"CF-Connecting-IP": false,
 		},
-		Other: make(map[string]interface{}),
+		RemoteAddrHeadersOrder: []string{"CF-Connecting-IP", "X-Real-Ip", "X-Forwarded-For", "Forwarded"},
+		Other:                  make(map[string]interface{}),
 	}
 }
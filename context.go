@@ -51,4 +51,10 @@ type (
 	//
 	// A shortcut for the `core/router#Party`, useful when `PartyFunc` is being used.
 	Party = router.Party
+
+	// URLParams contains the named parameters' values of a route,
+	// the rest of its keys are appended as query string.
+	//
+	// A shortcut for the `core/router#URLParams`, useful when `URL` or `URLPath` is being used.
+	URLParams = router.URLParams
 )
@@ -0,0 +1,370 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/monoculum/formam"
+)
+
+//  +------------------------------------------------------------+
+//  | Request binding                                            |
+//  +------------------------------------------------------------+
+
+const (
+	contentFormHeaderValue          = "application/x-www-form-urlencoded"
+	contentFormMultipartHeaderValue = "multipart/form-data"
+
+	// bindMultipartMemory is the maximum memory of a multipart body's files,
+	// the rest are stored on temporary files, same as the net/http's default.
+	bindMultipartMemory = 32 << 20
+)
+
+// The struct tags of the Bind's sources.
+const (
+	BindParamTag  = "param"
+	BindQueryTag  = "query"
+	BindHeaderTag = "header"
+	BindCookieTag = "cookie"
+	BindFormTag   = "form"
+)
+
+// ErrUnsupportedMediaType is returned by the Bind when the request body's Content-Type
+// is not one of the JSON, XML, form or multipart form.
+var ErrUnsupportedMediaType = errors.New("bind: unsupported Content-Type of the request body")
+
+// BindError is the conversion error of a field's value, see `BindErrors`.
+type BindError struct {
+	// Field is the Go name of the struct field.
+	Field string
+	// Source is the tag of the value's source: param, query, header or cookie.
+	Source string
+	// Name is the name of the value on its source, i.e the query key.
+	Name  string
+	Value string
+	Err   error
+}
+
+func (e BindError) Error() string {
+	return fmt.Sprintf("bind: %s %q of the field %s: cannot convert %q: %v", e.Source, e.Name, e.Field, e.Value, e.Err)
+}
+
+// BindErrors is returned by the Bind when a value can't be converted to the type of its field,
+// it contains one error per field.
+type BindErrors []BindError
+
+func (errs BindErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+	durationType    = reflect.TypeOf(time.Duration(0))
+)
+
+// Bind fills the fields of a pointer to a struct from the request body and
+// the path parameters, the url query, the headers and the cookies of their tags:
+//
+//	type UpdateUserRequest struct {
+//		ID     int64                 `param:"id"`
+//		Page   int                   `query:"page"`
+//		Tenant string                `header:"X-Tenant"`
+//		SID    string                `cookie:"sid"`
+//		Name   string                `json:"name" form:"name"`
+//		Avatar *multipart.FileHeader `form:"avatar"`
+//	}
+//
+// The body decoder is chosen by the request's Content-Type: JSON, through the jsoniter if
+// the JSONInteratorReplacement is enabled, XML, form, through the formam with the "form" tag,
+// and multipart form, its files are set to the *multipart.FileHeader and []*multipart.FileHeader fields.
+// An unsupported Content-Type fails with the ErrUnsupportedMediaType.
+//
+// The tagged values are set after the body, the missing ones are skipped,
+// the tagged fields are never decoded from the body, i.e a "sid" of a JSON body to the SID field.
+// The strings, bools, numbers, time.Duration, encoding.TextUnmarshaler
+// and the pointers and slices of them are supported, the values which can't be converted
+// are returned as `BindErrors`.
+//
+// The struct is validated by the Application's Validator, if attached, at the end.
+func (ctx *context) Bind(ptr interface{}) error {
+	val := reflect.ValueOf(ptr)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return errors.New("bind: expected a pointer to a struct")
+	}
+
+	// the body is decoded into a copy of the struct without the tagged fields.
+	body := reflect.New(val.Elem().Type())
+	body.Elem().Set(val.Elem())
+	tagged := taggedFields(body.Elem())
+	for _, field := range tagged {
+		field.Set(reflect.Zero(field.Type()))
+	}
+	if err := ctx.bindBody(body.Interface()); err != nil {
+		return err
+	}
+	for i, field := range taggedFields(val.Elem()) {
+		tagged[i].Set(field)
+	}
+	val.Elem().Set(body.Elem())
+
+	var errs BindErrors
+	ctx.bindFields(val.Elem(), &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return ctx.validate(ptr)
+}
+
+func (ctx *context) bindBody(ptr interface{}) error {
+	if ctx.request.Body == nil || ctx.request.ContentLength == 0 {
+		return nil
+	}
+
+	header := ctx.GetHeader(contentTypeHeaderKey)
+	if header == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return ErrUnsupportedMediaType
+	}
+
+	switch {
+	case mediaType == contentJSONHeaderValue || strings.HasSuffix(mediaType, "+json"):
+		if ctx.Application().ConfigurationReadOnly().GetJSONInteratorReplacement() {
+			return ctx.unmarshalBody(ptr, UnmarshalerFunc(jsoniter.Unmarshal))
+		}
+		return ctx.unmarshalBody(ptr, UnmarshalerFunc(json.Unmarshal))
+	case mediaType == contentXMLApplicationHeaderValue || mediaType == contentXMLHeaderValue || strings.HasSuffix(mediaType, "+xml"):
+		return ctx.unmarshalBody(ptr, UnmarshalerFunc(xml.Unmarshal))
+	case mediaType == contentFormHeaderValue:
+		if err := ctx.request.ParseForm(); err != nil {
+			return err
+		}
+		return decodeForm(ctx.request.PostForm, ptr)
+	case mediaType == contentFormMultipartHeaderValue:
+		if err := ctx.request.ParseMultipartForm(bindMultipartMemory); err != nil {
+			return err
+		}
+		return decodeForm(ctx.request.MultipartForm.Value, ptr)
+	}
+
+	return ErrUnsupportedMediaType
+}
+
+func decodeForm(values map[string][]string, ptr interface{}) error {
+	dec := formam.NewDecoder(&formam.DecoderOptions{TagName: BindFormTag, IgnoreUnknownKeys: true})
+	return dec.Decode(values, ptr)
+}
+
+func (ctx *context) bindFields(v reflect.Value, errs *BindErrors) {
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if sf.PkgPath != "" { // unexported.
+			continue
+		}
+		field := v.Field(i)
+
+		if sf.Anonymous && field.Kind() == reflect.Struct {
+			ctx.bindFields(field, errs)
+			continue
+		}
+
+		if field.Type() == fileHeaderType || field.Type() == fileHeadersType {
+			ctx.bindFiles(sf, field)
+			continue
+		}
+
+		source, name, values := ctx.bindValues(sf)
+		if len(values) == 0 {
+			continue
+		}
+
+		// the typed value of a path parameter, i.e int64 of the {id:int64}.
+		if source == BindParamTag {
+			if tv := ctx.params.values.Get(name); tv != nil && reflect.TypeOf(tv).AssignableTo(field.Type()) {
+				field.Set(reflect.ValueOf(tv))
+				continue
+			}
+		}
+
+		if err := setField(field, values); err != nil {
+			*errs = append(*errs, BindError{
+				Field:  sf.Name,
+				Source: source,
+				Name:   name,
+				Value:  strings.Join(values, ","),
+				Err:    err,
+			})
+		}
+	}
+}
+
+// taggedFields returns the fields of the struct, and of its embedded structs,
+// which have a param, query, header or cookie tag.
+func taggedFields(v reflect.Value) (fields []reflect.Value) {
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if sf.PkgPath != "" { // unexported.
+			continue
+		}
+		field := v.Field(i)
+
+		if sf.Anonymous && field.Kind() == reflect.Struct {
+			fields = append(fields, taggedFields(field)...)
+			continue
+		}
+
+		for _, tag := range []string{BindParamTag, BindQueryTag, BindHeaderTag, BindCookieTag} {
+			if sf.Tag.Get(tag) != "" {
+				fields = append(fields, field)
+				break
+			}
+		}
+	}
+	return
+}
+
+// bindValues returns the source, the name and the values of the field's tag,
+// the first of the param, query, header and cookie tags is used.
+func (ctx *context) bindValues(sf reflect.StructField) (source string, name string, values []string) {
+	if name = sf.Tag.Get(BindParamTag); name != "" {
+		if value := ctx.params.Get(name); value != "" {
+			values = []string{value}
+		}
+		return BindParamTag, name, values
+	}
+	if name = sf.Tag.Get(BindQueryTag); name != "" {
+		return BindQueryTag, name, ctx.request.URL.Query()[name]
+	}
+	if name = sf.Tag.Get(BindHeaderTag); name != "" {
+		return BindHeaderTag, name, ctx.request.Header[http.CanonicalHeaderKey(name)]
+	}
+	if name = sf.Tag.Get(BindCookieTag); name != "" {
+		if cookie, err := ctx.request.Cookie(name); err == nil {
+			values = []string{cookie.Value}
+		}
+		return BindCookieTag, name, values
+	}
+	return "", "", nil
+}
+
+// bindFiles sets the files of the multipart form's field, its name is the form tag or the Go name.
+func (ctx *context) bindFiles(sf reflect.StructField, field reflect.Value) {
+	if ctx.request.MultipartForm == nil {
+		return
+	}
+
+	name := strings.Split(sf.Tag.Get(BindFormTag), ",")[0]
+	if name == "" {
+		name = sf.Name
+	}
+
+	files := ctx.request.MultipartForm.File[name]
+	if len(files) == 0 {
+		return
+	}
+	if field.Type() == fileHeaderType {
+		field.Set(reflect.ValueOf(files[0]))
+		return
+	}
+	field.Set(reflect.ValueOf(files))
+}
+
+// setField sets the values to a slice field, or the first value to any other field.
+func setField(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Slice && !isTextUnmarshaler(field) {
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(slice.Index(i), value); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	return setValue(field, values[0])
+}
+
+func isTextUnmarshaler(v reflect.Value) bool {
+	_, ok := v.Addr().Interface().(encoding.TextUnmarshaler)
+	return ok
+}
+
+func setValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Ptr {
+		ptr := reflect.New(v.Type().Elem())
+		if err := setValue(ptr.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	var err error
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			v.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if v.Type() == durationType {
+			var d time.Duration
+			if d, err = time.ParseDuration(s); err == nil {
+				v.SetInt(int64(d))
+			}
+		} else if n, err = strconv.ParseInt(s, 10, v.Type().Bits()); err == nil {
+			v.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(s, 10, v.Type().Bits()); err == nil {
+			v.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	// strip the "strconv.ParseInt: parsing ..." prefix, the BindError has the value.
+	if numErr, ok := err.(*strconv.NumError); ok {
+		return numErr.Err
+	}
+	return err
+}
@@ -0,0 +1,296 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zlib"
+)
+
+// The content codings of the compression, see `Context#Compress` and `Context#NegotiateEncoding`.
+const (
+	EncodingBrotli  = "br"
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+)
+
+// compressionEncodings are the content codings of the Compress, in the server's order of preference.
+var compressionEncodings = []string{EncodingBrotli, EncodingGzip, EncodingDeflate}
+
+// CompressMinSize is the minimum size of a response body, in bytes, which is compressed
+// by the `Context#Compress`, the smaller ones are written as they are,
+// as the compression's overhead is bigger than its gain.
+// The `Context#Gzip` and the `Context#GzipResponseWriter` compress the bodies of any size.
+var CompressMinSize = 1024
+
+// compressedContentTypes are the prefixes of the Content-Types which are already compressed,
+// their responses are written as they are.
+var compressedContentTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"application/font-woff",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-brotli",
+	"application/x-bzip2",
+	"application/x-xz",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+	"application/pdf",
+}
+
+// isCompressedContentType reports whether the Content-Type is of an already compressed content,
+// the svg images are text so they are compressed.
+func isCompressedContentType(contentType string) bool {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if strings.HasPrefix(contentType, "image/svg") {
+		return false
+	}
+	for _, prefix := range compressedContentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// addVaryHeader adds the value to the Vary header, unless it's already there.
+func addVaryHeader(header http.Header, value string) {
+	for _, v := range header[varyHeaderKey] {
+		for _, existing := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(existing), value) {
+				return
+			}
+		}
+	}
+	header.Add(varyHeaderKey, value)
+}
+
+//  +------------------------------------------------------------+
+//  |Compression raw io.writers, the response writer uses them.  |
+//  +------------------------------------------------------------+
+
+// compressWriter is the writer of a content coding, i.e the *gzip.Writer.
+type compressWriter interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// compressionPool is a wrapper of sync.Pool, to initialize a new compression writer pool
+type compressionPool struct {
+	sync.Pool
+	Level     int
+	newWriter func(w io.Writer, level int) (compressWriter, error)
+}
+
+var compressionPools = map[string]*compressionPool{
+	EncodingBrotli: {
+		Level: brotli.DefaultCompression,
+		newWriter: func(w io.Writer, level int) (compressWriter, error) {
+			return brotli.NewWriterLevel(w, level), nil
+		},
+	},
+	// default writer pools with Compressor's level set to -1
+	EncodingGzip: {
+		Level: -1,
+		newWriter: func(w io.Writer, level int) (compressWriter, error) {
+			return gzip.NewWriterLevel(w, level)
+		},
+	},
+	// the "deflate" content coding is the zlib format.
+	EncodingDeflate: {
+		Level: -1,
+		newWriter: func(w io.Writer, level int) (compressWriter, error) {
+			return zlib.NewWriterLevel(w, level)
+		},
+	},
+}
+
+var errUnsupportedEncoding = errors.New("unsupported content coding")
+
+// acquireCompressWriter prepares a writer of the content coding and returns it.
+//
+// see releaseCompressWriter too.
+func acquireCompressWriter(encoding string, w io.Writer) (compressWriter, error) {
+	p, ok := compressionPools[encoding]
+	if !ok {
+		return nil, errUnsupportedEncoding
+	}
+	if v := p.Get(); v != nil {
+		cw := v.(compressWriter)
+		cw.Reset(w)
+		return cw, nil
+	}
+	return p.newWriter(w, p.Level)
+}
+
+// releaseCompressWriter called when flush/close and put the writer back to its pool.
+//
+// see acquireCompressWriter too.
+func releaseCompressWriter(encoding string, cw compressWriter) error {
+	err := cw.Close()
+	compressionPools[encoding].Put(cw)
+	return err
+}
+
+var cpool = sync.Pool{New: func() interface{} { return &CompressResponseWriter{} }}
+
+// AcquireCompressResponseWriter returns a new *CompressResponseWriter from the pool.
+// Releasing is done automatically when request and response is done.
+func AcquireCompressResponseWriter() *CompressResponseWriter {
+	return cpool.Get().(*CompressResponseWriter)
+}
+
+func releaseCompressResponseWriter(w *CompressResponseWriter) {
+	cpool.Put(w)
+}
+
+// CompressResponseWriter is an upgraded response writer which writes compressed data,
+// of the br, gzip or deflate content coding, to the underline ResponseWriter.
+//
+// It's a separate response writer because Siris gives you the ability to "fallback" and "roll-back" the compression if something
+// went wrong with the response, and write http errors in plain form instead.
+//
+// The body is written as it is if it's smaller than the CompressMinSize, when it's enabled by the `Context#Compress`,
+// if its Content-Type is already compressed, i.e an image, or if the Content-Encoding is already set.
+type CompressResponseWriter struct {
+	ResponseWriter
+	encoding string
+	chunks   []byte
+	disabled bool
+	minSize  int // the minimum size of the compressed body, see the CompressMinSize.
+}
+
+var _ ResponseWriter = &CompressResponseWriter{}
+
+// BeginCompressResponse accepts a ResponseWriter and the content coding, i.e "br",
+// and prepares the new compress response writer.
+// It's being called per-handler, when caller decide
+// to change the response writer type.
+func (w *CompressResponseWriter) BeginCompressResponse(underline ResponseWriter, encoding string) {
+	w.ResponseWriter = underline
+	w.encoding = encoding
+	w.chunks = w.chunks[0:0]
+	w.disabled = false
+	w.minSize = 0
+}
+
+// Encoding returns the content coding of the compression, i.e "br".
+func (w *CompressResponseWriter) Encoding() string {
+	return w.encoding
+}
+
+// EndResponse called right before the contents of this
+// response writer are flushed to the client.
+func (w *CompressResponseWriter) EndResponse() {
+	underline := w.ResponseWriter
+	releaseCompressResponseWriter(w)
+	underline.EndResponse()
+}
+
+// Write saves the data, they're compressed and written to the underline response writer on FlushResponse.
+func (w *CompressResponseWriter) Write(contents []byte) (int, error) {
+	w.chunks = append(w.chunks, contents...)
+	return len(contents), nil
+}
+
+// Writef formats according to a format specifier and saves the data as the Write.
+func (w *CompressResponseWriter) Writef(format string, a ...interface{}) (n int, err error) {
+	return fmt.Fprintf(w, format, a...)
+}
+
+// WriteString saves the string's data as the Write.
+func (w *CompressResponseWriter) WriteString(s string) (int, error) {
+	w.chunks = append(w.chunks, s...)
+	return len(s), nil
+}
+
+// shouldCompress reports whether the body should be compressed.
+func (w *CompressResponseWriter) shouldCompress() bool {
+	if w.disabled || len(w.chunks) == 0 || len(w.chunks) < w.minSize {
+		return false
+	}
+	// the Content-Range is of the uncompressed content.
+	if w.ResponseWriter.StatusCode() == http.StatusPartialContent {
+		return false
+	}
+	header := w.ResponseWriter.Header()
+	// i.e a precompressed file.
+	if header.Get(contentEncodingHeaderKey) != "" {
+		return false
+	}
+	return !isCompressedContentType(header.Get(contentTypeHeaderKey))
+}
+
+// FlushResponse validates the response headers in order to be compatible with the compressed data
+// and writes the data to the underline ResponseWriter.
+func (w *CompressResponseWriter) FlushResponse() {
+	if !w.shouldCompress() {
+		w.ResponseWriter.Write(w.chunks)
+		w.ResponseWriter.FlushResponse()
+		return
+	}
+
+	header := w.ResponseWriter.Header()
+	// the net/http would sniff the compressed data otherwise.
+	if header.Get(contentTypeHeaderKey) == "" {
+		header.Set(contentTypeHeaderKey, http.DetectContentType(w.chunks))
+	}
+	addVaryHeader(header, acceptEncodingHeaderKey)
+	header.Set(contentEncodingHeaderKey, w.encoding)
+	header.Del(contentLengthHeaderKey)
+
+	cw, err := acquireCompressWriter(w.encoding, w.ResponseWriter)
+	if err != nil {
+		header.Del(contentEncodingHeaderKey)
+		w.ResponseWriter.Write(w.chunks)
+	} else {
+		cw.Write(w.chunks) // it writes to the underline ResponseWriter.
+		releaseCompressWriter(w.encoding, cw)
+	}
+	w.ResponseWriter.FlushResponse()
+}
+
+// ResetBody resets the response body.
+func (w *CompressResponseWriter) ResetBody() {
+	w.chunks = w.chunks[0:0]
+}
+
+// Disable turns off the compression for the next .Write's data,
+// if called then the contents are being written in plain form.
+func (w *CompressResponseWriter) Disable() {
+	w.disabled = true
+}
+
+//  +------------------------------------------------------------+
+//  | Gzip response writer, the CompressResponseWriter of gzip.  |
+//  +------------------------------------------------------------+
+
+// GzipResponseWriter is the CompressResponseWriter,
+// it's kept for the existing code which uses the gzip response writer.
+type GzipResponseWriter = CompressResponseWriter
+
+// AcquireGzipResponseWriter returns a new *GzipResponseWriter from the pool.
+// Releasing is done automatically when request and response is done.
+func AcquireGzipResponseWriter() *GzipResponseWriter {
+	return AcquireCompressResponseWriter()
+}
+
+// BeginGzipResponse accepts a ResponseWriter
+// and prepares the response writer of the gzip content coding.
+func (w *CompressResponseWriter) BeginGzipResponse(underline ResponseWriter) {
+	w.BeginCompressResponse(underline, EncodingGzip)
+}
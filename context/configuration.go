@@ -4,6 +4,8 @@
 
 package context
 
+import "net"
+
 // ConfigurationReadOnly can be implemented
 // by Configuration, it's being used inside the Context.
 // All methods that it contains should be "safe" to be called by the context
@@ -31,6 +33,14 @@ type ConfigurationReadOnly interface {
 	// GetFireMethodNotAllowed returns the configuration.FireMethodNotAllowed.
 	GetFireMethodNotAllowed() bool
 
+	// GetDisableAutoHead returns the configuration.DisableAutoHead,
+	// returns true when the HEAD requests are not served by the GET routes.
+	GetDisableAutoHead() bool
+
+	// GetDisableAutoOptions returns the configuration.DisableAutoOptions,
+	// returns true when the OPTIONS requests are not answered automatically.
+	GetDisableAutoOptions() bool
+
 	// GetDisableBodyConsumptionOnUnmarshal returns the configuration.GetDisableBodyConsumptionOnUnmarshal,
 	// manages the reading behavior of the context's body readers/binders.
 	// If returns true then the body consumption by the `context.UnmarshalBody/ReadJSON/ReadXML`
@@ -90,6 +100,21 @@ type ConfigurationReadOnly interface {
 	// Look `context.RemoteAddr()` for more.
 	GetRemoteAddrHeaders() map[string]bool
 
+	// GetRemoteAddrHeadersOrder returns the precedence of the enabled RemoteAddrHeaders.
+	//
+	// Look `context.RemoteAddr()` for more.
+	GetRemoteAddrHeadersOrder() []string
+
+	// GetTrustedProxies returns the IPs or CIDR ranges of the trusted reverse proxies.
+	//
+	// Look `context.RemoteAddr()`, `context.Scheme()` and `context.Host()` for more.
+	GetTrustedProxies() []string
+
+	// GetTrustedProxyNets returns the parsed TrustedProxies.
+	//
+	// Look `context.RemoteAddr()`, `context.Scheme()` and `context.Host()` for more.
+	GetTrustedProxyNets() []*net.IPNet
+
 	// GetOther returns the configuration.Other map.
 	GetOther() map[string]interface{}
 }
@@ -21,7 +21,6 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
-	"runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -83,6 +82,9 @@ func (u UnmarshalerFunc) Unmarshal(data []byte, v interface{}) error {
 // time, stores the dynamic named parameters, can be empty if the route is static.
 type RequestParams struct {
 	store memstore.Store
+	// the typed values of the parameters, i.e int64 for {id:int64},
+	// they're set by the route's macro evaluator.
+	values memstore.Store
 }
 
 // Set shouldn't be used as a local storage, context's values store
@@ -109,11 +111,57 @@ func (r RequestParams) GetInt(key string) (int, error) {
 	return r.store.GetInt(key)
 }
 
+// SetValue sets the typed value of a parameter,
+// it's being called by the route's macro evaluator,
+// i.e an int64 for the {id:int64} parameter.
+func (r *RequestParams) SetValue(key string, value interface{}) {
+	r.values.Set(key, value)
+}
+
+// GetValue returns the typed value of a parameter based on its route's dynamic path key,
+// i.e int64 for {id:int64}, uint64 for {id:uint64}, bool for {enabled:bool}
+// and time.Time for {day:date}.
+// Returns the string value if the parameter's type has no typed value.
+func (r RequestParams) GetValue(key string) interface{} {
+	if v := r.values.Get(key); v != nil {
+		return v
+	}
+	return r.store.Get(key)
+}
+
 // GetInt64 returns the user's value as int64, based on its key.
 func (r RequestParams) GetInt64(key string) (int64, error) {
+	if v, ok := r.values.Get(key).(int64); ok {
+		return v, nil
+	}
 	return r.store.GetInt64(key)
 }
 
+// GetUint64 returns the param's value as uint64, based on its key.
+func (r RequestParams) GetUint64(key string) (uint64, error) {
+	if v, ok := r.values.Get(key).(uint64); ok {
+		return v, nil
+	}
+	return strconv.ParseUint(r.Get(key), 10, 64)
+}
+
+// GetBool returns the param's value as bool, based on its key.
+func (r RequestParams) GetBool(key string) (bool, error) {
+	if v, ok := r.values.Get(key).(bool); ok {
+		return v, nil
+	}
+	return strconv.ParseBool(r.Get(key))
+}
+
+// GetTime returns the param's value as time.Time, based on its key,
+// the value should be in the form of year-month-day, like the {day:date} parameter type.
+func (r RequestParams) GetTime(key string) (time.Time, error) {
+	if v, ok := r.values.Get(key).(time.Time); ok {
+		return v, nil
+	}
+	return time.Parse("2006-01-02", r.Get(key))
+}
+
 // GetDecoded returns the url-query-decoded user's value based on its key.
 func (r RequestParams) GetDecoded(key string) string {
 	return DecodeQuery(DecodeQuery(r.Get(key)))
@@ -170,8 +218,9 @@ type Context interface {
 	// EndRequest is executing once after a response to the request was sent and this context is useless or released.
 	//
 	// To follow the Siris' flow, developer should:
-	// 1. flush the response writer's result
-	// 2. release the response writer
+	// 1. release the session, if modified
+	// 2. flush the response writer's result
+	// 3. release the response writer
 	// and any other optional steps, depends on dev's application type.
 	EndRequest()
 
@@ -270,7 +319,15 @@ type Context interface {
 	RequestPath(escape bool) string
 
 	// Host returns the host part of the current url.
+	//
+	// If the request comes from a trusted proxy then the "X-Forwarded-Host"
+	// or the "Forwarded" header's host is used instead.
 	Host() string
+	// Scheme returns the request's scheme, "https" or "http".
+	//
+	// If the request comes from a trusted proxy then the "X-Forwarded-Proto"
+	// or the "Forwarded" header's proto is used instead.
+	Scheme() string
 	// Subdomain returns the subdomain of this request, if any.
 	// Note that this is a fast method which does not cover all cases.
 	Subdomain() (subdomain string)
@@ -364,6 +421,9 @@ type Context interface {
 
 	// UnmarshalBody reads the request's body and binds it to a value or pointer of any type
 	// Examples of usage: context.ReadJSON, context.ReadXML.
+	//
+	// The value is validated by the Application's Validator, if attached, after that,
+	// its error, if any, is returned as it is, see the siris/validator package.
 	UnmarshalBody(v interface{}, unmarshaler Unmarshaler) error
 	// ReadJSON reads JSON from request's body and binds it to a value of any json-valid type.
 	ReadJSON(jsonObject interface{}) error
@@ -371,6 +431,7 @@ type Context interface {
 	ReadXML(xmlObject interface{}) error
 	// ReadForm binds the formObject  with the form data
 	// it supports any kind of struct.
+	// The formObject is validated by the Application's Validator, if attached, after that.
 	ReadForm(formObject interface{}) error
 
 	//  +------------------------------------------------------------+
@@ -380,11 +441,24 @@ type Context interface {
 	// ReadQuery binds the queryObject with the query data
 	// it supports any kind of struct.
 	// Support for standard types and: pointer, slice, array, map, struct
+	// The queryObject is validated by the Application's Validator, if attached, after that.
 	ReadQuery(queryObject interface{}) error
 
 	// CreateQuery creates a query-string from a struct object
 	CreateQuery(queryObject interface{}) (string, error)
 
+	//  +------------------------------------------------------------+
+	//  | Request binding                                            |
+	//  +------------------------------------------------------------+
+
+	// Bind fills the fields of a pointer to a struct from the request body, decoded by its Content-Type,
+	// and from the path parameters, the url query, the headers and the cookies
+	// of their `param`, `query`, `header` and `cookie` tags.
+	//
+	// The values which can't be converted to their fields are returned as BindErrors,
+	// the struct is validated by the Application's Validator, if attached, at the end.
+	Bind(ptr interface{}) error
+
 	//  +------------------------------------------------------------+
 	//  | Body (raw) Writers                                         |
 	//  +------------------------------------------------------------+
@@ -435,10 +509,23 @@ type Context interface {
 	// receives a function which receives the response writer
 	// and returns false when it should stop writing, otherwise true in order to continue
 	StreamWriter(writer func(w io.Writer) bool)
+	// SSE prepares the response for Server-Sent Events and returns its event writer,
+	// which sends the events, the retry hints and the comments.
+	//
+	// The headers are sent immediately and the response compression is turned off.
+	// The event writer sends a comment every SSEHeartbeat while idle
+	// and its `Done` channel is closed when the client is gone.
+	//
+	// See `NewBroadcaster` too.
+	SSE() *EventWriter
 
 	//  +------------------------------------------------------------+
-	//  | Body Writers with compression                              |
+	//  | Body Writers with compression                           |
 	//  +------------------------------------------------------------+
+	// NegotiateEncoding returns the content coding, of the "offers", which is the most acceptable by the client,
+	// based on the Accept-Encoding header's q-values, the earliest of the offers wins on equal q-values.
+	// Returns an empty string if none of the offers is acceptable or if the header is missing.
+	NegotiateEncoding(offers ...string) string
 	// ClientSupportsGzip retruns true if the client supports gzip compression.
 	ClientSupportsGzip() bool
 	// WriteGzip accepts bytes, which are compressed to gzip format and sent to the client.
@@ -460,6 +547,12 @@ type Context interface {
 	// supports gzip compression, so the following response data will
 	// be sent as compressed gzip data to the client.
 	Gzip(enable bool)
+	// Compress enables or disables (if enabled before) the compress response writer,
+	// the following response data will be sent as compressed data to the client,
+	// in the br, gzip or deflate content coding, whichever is the most acceptable by the client.
+	//
+	// The bodies which are smaller than the CompressMinSize or already compressed, i.e images, are sent as they are.
+	Compress(enable bool)
 
 	//  +------------------------------------------------------------+
 	//  | Rich Body Content Writers/Renderers                        |
@@ -533,6 +626,17 @@ type Context interface {
 	XML(v interface{}, options ...XML) (int, error)
 	// Markdown parses the markdown to html and renders to client.
 	Markdown(markdownB []byte, options ...Markdown) (int, error)
+	// Negotiate writes the data as the media type, of the "offers", which is the most acceptable by the client,
+	// based on the Accept header's media ranges and their q-values.
+	// The offers are the media types of the registered renderers, see `RegisterRenderer`,
+	// in the server's order of preference; all of them are offered if empty.
+	//
+	// The "Vary: Accept" header is always set.
+	// If none of the offers is acceptable then it fires the 406 error code handler and returns the ErrNotAcceptable.
+	Negotiate(v interface{}, offers ...string) (int, error)
+	// NegotiateView sets the template which renders the data of the `Negotiate` as HTML,
+	// through the attached view engine. Without it the HTML is offered only if the data is a string.
+	NegotiateView(filename string)
 
 	//  +------------------------------------------------------------+
 	//  | Serve files                                                |
@@ -543,6 +647,9 @@ type Context interface {
 	//
 	// You can define your own "Content-Type" header also, after this function call
 	// Doesn't implements resuming (by range), use ctx.SendFile instead
+	//
+	// If gzipCompression is true then the content is compressed in the br, gzip or deflate content coding,
+	// whichever is the most acceptable by the client, unless it's already compressed, i.e an image.
 	ServeContent(content io.ReadSeeker, filename string, modtime time.Time, gzipCompression bool) error
 	// ServeFile serves a view file, to send a file ( zip for example) to the client you should use the SendFile(serverfilename,clientfilename)
 	// receives two parameters
@@ -580,11 +687,21 @@ type Context interface {
 	VisitAllCookies(visitor func(name string, value string))
 
 	// Session returns the current user's Session.
+	//
+	// The session is released, its values are saved, at the end of the request if they were modified.
 	Session() sessions.Store
+	// RequestSession returns the current user's Session, like the `Session`,
+	// with its typed getters and flash messages.
+	RequestSession() *sessions.Session
 	// SessionDestroy destroys the whole session and removes the session id cookie.
 	SessionDestroy()
 	// SessionRegenerateID gernerates a new session ID and removes the old session id.
 	SessionRegenerateID() sessions.Store
+	// SessionLogin binds the session to the user of the "uid", its session ID is regenerated.
+	// The user's oldest sessions are destroyed if the session manager's MaxSessionsPerUser is exceeded.
+	//
+	// See the `sessions.Manager#DestroyOtherSessions` to log out the user's other devices.
+	SessionLogin(uid string) *sessions.Session
 
 	// MaxAge returns the "cache-control" request header's value
 	// seconds as int64
@@ -794,7 +911,7 @@ type context struct {
 	// the route's handlers
 	handlers Handlers
 	// the session, can be nil if never acquired
-	session sessions.Store
+	session *sessions.Session
 	// the current position of the handler's chain
 	currentHandlerIndex int
 }
@@ -823,6 +940,7 @@ func (ctx *context) BeginRequest(w http.ResponseWriter, r *http.Request) {
 	ctx.session = nil            // >>      >>     by sessions.Session()
 	ctx.values = ctx.values[0:0] // >>      >>     by context.Values().Set
 	ctx.params.store = ctx.params.store[0:0]
+	ctx.params.values = ctx.params.values[0:0]
 	ctx.request = r
 	ctx.currentHandlerIndex = 0
 	ctx.writer = AcquireResponseWriter()
@@ -832,8 +950,9 @@ func (ctx *context) BeginRequest(w http.ResponseWriter, r *http.Request) {
 // EndRequest is executing once after a response to the request was sent and this context is useless or released.
 //
 // To follow the Siris' flow, developer should:
-// 1. flush the response writer's result
-// 2. release the response writer
+// 1. release the session, if modified
+// 2. flush the response writer's result
+// 3. release the response writer
 // and any other optional steps, depends on dev's application type.
 func (ctx *context) EndRequest() {
 	if ctx.GetStatusCode() >= 400 &&
@@ -850,6 +969,10 @@ func (ctx *context) EndRequest() {
 		}
 	}
 
+	if ctx.session != nil {
+		ctx.releaseSession()
+	}
+
 	ctx.writer.FlushResponse()
 	ctx.writer.EndResponse()
 }
@@ -919,7 +1042,7 @@ func (ctx *context) HandlerIndex(n int) (currentIndex int) {
 
 // HandlerName returns the current handler's name, helpful for debugging.
 func (ctx *context) HandlerName() string {
-	return runtime.FuncForPC(reflect.ValueOf(ctx.handlers[ctx.currentHandlerIndex]).Pointer()).Name()
+	return HandlerName(ctx.handlers[ctx.currentHandlerIndex])
 }
 
 // Do sets the handler index to zero, executes the first handler
@@ -1076,7 +1199,18 @@ func (ctx *context) RequestPath(escape bool) string {
 // } no, it will not work because map is a random peek data structure.
 
 // Host returns the host part of the current url.
+//
+// If the request comes from a trusted proxy then the "X-Forwarded-Host"
+// or the "Forwarded" header's host is used instead.
+//
+// Look `Configuration.TrustedProxies` for more.
 func (ctx *context) Host() string {
+	if ctx.fromTrustedProxy() {
+		if h := ctx.forwardedValue("X-Forwarded-Host", "host"); h != "" {
+			return h
+		}
+	}
+
 	h := ctx.request.URL.Host
 	if h == "" {
 		h = ctx.request.Host
@@ -1084,6 +1218,49 @@ func (ctx *context) Host() string {
 	return h
 }
 
+// Scheme returns the request's scheme, "https" or "http".
+//
+// If the request comes from a trusted proxy then the "X-Forwarded-Proto"
+// or the "Forwarded" header's proto is used instead.
+//
+// Look `Configuration.TrustedProxies` for more.
+func (ctx *context) Scheme() string {
+	if ctx.fromTrustedProxy() {
+		if proto := strings.ToLower(ctx.forwardedValue("X-Forwarded-Proto", "proto")); proto == "https" || proto == "http" {
+			return proto
+		}
+	}
+
+	if ctx.request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// fromTrustedProxy reports whether the request's peer is one of the TrustedProxies,
+// it's false when there are no TrustedProxies.
+func (ctx *context) fromTrustedProxy() bool {
+	proxies := trustedProxies(ctx.Application().ConfigurationReadOnly().GetTrustedProxyNets())
+	return len(proxies) > 0 && proxies.contains(net.ParseIP(ctx.peerAddr()))
+}
+
+// forwardedValue returns the right-most, the one set by the closest proxy,
+// value of the "X-Forwarded-*" "headerName" or, if missing, of the "Forwarded" header's "param".
+func (ctx *context) forwardedValue(headerName string, param string) string {
+	if values := splitHeaderList(ctx.request.Header[http.CanonicalHeaderKey(headerName)]); len(values) > 0 {
+		return values[len(values)-1]
+	}
+
+	if v := ctx.request.Header["Forwarded"]; len(v) > 0 {
+		params, _ := httpforwarded.Parse(v)
+		if values := params[param]; len(values) > 0 {
+			return strings.TrimSpace(values[len(values)-1])
+		}
+	}
+
+	return ""
+}
+
 // Subdomain returns the subdomain of this request, if any.
 // Note that this is a fast method which does not cover all cases.
 func (ctx *context) Subdomain() (subdomain string) {
@@ -1104,42 +1281,60 @@ func (ctx *context) Subdomain() (subdomain string) {
 
 // RemoteAddr tries to parse and return the real client's request IP.
 //
-// Based on allowed headers names that can be modified from Configuration.RemoteAddrHeaders.
+// Based on allowed headers names that can be modified from Configuration.RemoteAddrHeaders,
+// checked in the Configuration.RemoteAddrHeadersOrder.
+//
+// If Configuration.TrustedProxies is not empty then the headers are used only
+// when the request comes from a trusted proxy and the "X-Forwarded-For" and "Forwarded" headers
+// are walked from right to left, the first IP which is not a trusted proxy is the client's one.
+// If it's empty then the headers are trusted from any peer and the left-most IP is returned.
 //
 // If parse based on these headers fail then it will return the Request's `RemoteAddr` field
 // which is filled by the server before the HTTP handler.
 //
 // Look `Configuration.RemoteAddrHeaders`,
+//      `Configuration.RemoteAddrHeadersOrder`,
+//      `Configuration.TrustedProxies`,
 //      `Configuration.WithRemoteAddrHeader(...)`,
 //      `Configuration.WithoutRemoteAddrHeader(...)` for more.
 func (ctx *context) RemoteAddr() string {
+	cfg := ctx.Application().ConfigurationReadOnly()
+	addr := ctx.peerAddr()
 
-	remoteHeaders := ctx.Application().ConfigurationReadOnly().GetRemoteAddrHeaders()
-
-	for headerName, enabled := range remoteHeaders {
-		if enabled {
-			headerName = http.CanonicalHeaderKey(headerName)
-			headerValue := ctx.GetHeader(headerName)
-			// exception needed for 'X-Forwarded-For' only , if enabled.
-			if headerName == http.CanonicalHeaderKey("X-Forwarded-For") {
-				idx := strings.IndexByte(headerValue, ',')
-				if idx >= 0 {
-					headerValue = headerValue[0:idx]
-				}
-			} else if headerName == http.CanonicalHeaderKey("forwarded") {
-				params, _ := httpforwarded.Parse([]string{headerValue})
-				if len(params["for"]) >= 1 {
-					headerValue = params["for"][len(params["for"])-1]
-				}
-			}
+	proxies := trustedProxies(cfg.GetTrustedProxyNets())
+	if len(proxies) > 0 && !proxies.contains(net.ParseIP(addr)) {
+		// the peer is the client, the headers can't be trusted.
+		return addr
+	}
+
+	for _, headerName := range remoteAddrHeaders(cfg.GetRemoteAddrHeaders(), cfg.GetRemoteAddrHeadersOrder()) {
+		var ips []string
 
-			realIP := strings.TrimSpace(headerValue)
-			if realIP != "" {
+		switch headerName {
+		case "X-Forwarded-For":
+			ips = splitHeaderList(ctx.request.Header[headerName])
+		case "Forwarded":
+			params, _ := httpforwarded.Parse(ctx.request.Header[headerName])
+			for _, v := range params["for"] {
+				ips = append(ips, stripIPPort(v))
+			}
+		default:
+			if realIP := strings.TrimSpace(ctx.GetHeader(headerName)); realIP != "" {
 				return realIP
 			}
+			continue
+		}
+
+		if realIP := proxies.clientIP(ips); realIP != "" {
+			return realIP
 		}
 	}
 
+	return addr
+}
+
+// peerAddr returns the IP of the Request's `RemoteAddr` field, without the port.
+func (ctx *context) peerAddr() string {
 	addr := strings.TrimSpace(ctx.request.RemoteAddr)
 	if addr != "" {
 		// if addr has port use the net.SplitHostPort otherwise(error occurs) take as it is
@@ -1356,6 +1551,14 @@ func (ctx *context) SetMaxRequestBodySize(limitOverBytes int64) {
 // UnmarshalBody reads the request's body and binds it to a value or pointer of any type
 // Examples of usage: context.ReadJSON, context.ReadXML.
 func (ctx *context) UnmarshalBody(v interface{}, unmarshaler Unmarshaler) error {
+	if err := ctx.unmarshalBody(v, unmarshaler); err != nil {
+		return err
+	}
+	return ctx.validate(v)
+}
+
+// unmarshalBody is the UnmarshalBody without the validation, the Bind validates after all of its sources.
+func (ctx *context) unmarshalBody(v interface{}, unmarshaler Unmarshaler) error {
 	if ctx.request.Body == nil {
 		return errors.New("unmarshal: empty body")
 	}
@@ -1389,6 +1592,15 @@ func (ctx *context) UnmarshalBody(v interface{}, unmarshaler Unmarshaler) error
 	return unmarshaler.Unmarshal(rawData, &v)
 }
 
+// validate validates the value which is read from the request by the Application's Validator, if any.
+func (ctx *context) validate(v interface{}) error {
+	validator := ctx.Application().Validator()
+	if validator == nil {
+		return nil
+	}
+	return validator.Validate(v)
+}
+
 // ReadJSON reads JSON from request's body and binds it to a value of any json-valid type.
 func (ctx *context) ReadJSON(jsonObject interface{}) error {
 	if ctx.Application().ConfigurationReadOnly().GetJSONInteratorReplacement() {
@@ -1405,7 +1617,10 @@ func (ctx *context) ReadXML(xmlObject interface{}) error {
 
 // ReadQuery reads URL.Query from request's and binds it to a value of any valid standard types.
 func (ctx *context) ReadQuery(queryObject interface{}) error {
-	return qs.Unmarshal(queryObject, ctx.request.URL.RawQuery)
+	if err := qs.Unmarshal(queryObject, ctx.request.URL.RawQuery); err != nil {
+		return err
+	}
+	return ctx.validate(queryObject)
 }
 
 // CreateQuery create a URL.Query from a struct of any valid standard types.
@@ -1428,7 +1643,10 @@ func (ctx *context) ReadForm(formObject interface{}) error {
 	// or dec := formam.NewDecoder(&formam.DecoderOptions{TagName: "form"})
 	// somewhere at the framework level. I did change the tagName to "form"
 	// inside its source code, so it's not needed for now.
-	return errReadBody.With(formam.Decode(values, formObject))
+	if err := formam.Decode(values, formObject); err != nil {
+		return errReadBody.With(err)
+	}
+	return ctx.validate(formObject)
 }
 
 //  +------------------------------------------------------------+
@@ -1547,16 +1765,16 @@ func (ctx *context) StreamWriter(writer func(w io.Writer) bool) {
 //  | Body Writers with compression                              |
 //  +------------------------------------------------------------+
 
+// NegotiateEncoding returns the content coding, of the "offers", which is the most acceptable by the client,
+// based on the Accept-Encoding header's q-values, the earliest of the offers wins on equal q-values.
+// Returns an empty string if none of the offers is acceptable or if the header is missing.
+func (ctx *context) NegotiateEncoding(offers ...string) string {
+	return negotiateEncoding(ctx.GetHeader(acceptEncodingHeaderKey), offers)
+}
+
 // ClientSupportsGzip retruns true if the client supports gzip compression.
 func (ctx *context) ClientSupportsGzip() bool {
-	if h := ctx.GetHeader(acceptEncodingHeaderKey); h != "" {
-		for _, v := range strings.Split(h, ";") {
-			if strings.Contains(v, "gzip") { // we do Contains because sometimes browsers has the q=, we don't use it atm. || strings.Contains(v,"deflate"){
-				return true
-			}
-		}
-	}
-	return false
+	return ctx.NegotiateEncoding(EncodingGzip) == EncodingGzip
 }
 
 var (
@@ -1571,8 +1789,11 @@ func (ctx *context) WriteGzip(b []byte) (int, error) {
 	if ctx.ClientSupportsGzip() {
 		ctx.writer.Header().Add(varyHeaderKey, acceptEncodingHeaderKey)
 
-		gzipWriter := acquireGzipWriter(ctx.writer)
-		defer releaseGzipWriter(gzipWriter)
+		gzipWriter, err := acquireCompressWriter(EncodingGzip, ctx.writer)
+		if err != nil {
+			return 0, err
+		}
+		defer releaseCompressWriter(EncodingGzip, gzipWriter)
 		n, err := gzipWriter.Write(b)
 
 		if err == nil {
@@ -1605,16 +1826,22 @@ func (ctx *context) TryWriteGzip(b []byte) (int, error) {
 //
 // Can be also disabled with its .Disable and .ResetBody to rollback to the usual response writer.
 func (ctx *context) GzipResponseWriter() *GzipResponseWriter {
-	// if it's already a gzip response writer then just return it
-	if gzipResWriter, ok := ctx.writer.(*GzipResponseWriter); ok {
-		return gzipResWriter
+	return ctx.compressResponseWriter(EncodingGzip)
+}
+
+// compressResponseWriter converts the current response writer into a compress response writer
+// of the content coding, if it's not already one.
+func (ctx *context) compressResponseWriter(encoding string) *CompressResponseWriter {
+	// if it's already a compress response writer then just return it
+	if compressResWriter, ok := ctx.writer.(*CompressResponseWriter); ok {
+		return compressResWriter
 	}
 	// if it's not acquire a new from a pool
 	// and register that as the ctx.ResponseWriter.
-	gzipResWriter := AcquireGzipResponseWriter()
-	gzipResWriter.BeginGzipResponse(ctx.writer)
-	ctx.ResetResponseWriter(gzipResWriter)
-	return gzipResWriter
+	compressResWriter := AcquireCompressResponseWriter()
+	compressResWriter.BeginCompressResponse(ctx.writer, encoding)
+	ctx.ResetResponseWriter(compressResWriter)
+	return compressResWriter
 }
 
 // Gzip enables or disables (if enabled before) the gzip response writer,if the client
@@ -1632,6 +1859,26 @@ func (ctx *context) Gzip(enable bool) {
 	}
 }
 
+// Compress enables or disables (if enabled before) the compress response writer,
+// the following response data will be sent as compressed data to the client,
+// in the br, gzip or deflate content coding, whichever is the most acceptable by the client.
+//
+// The bodies which are smaller than the CompressMinSize or already compressed, i.e images, are sent as they are.
+func (ctx *context) Compress(enable bool) {
+	if !enable {
+		if compressResWriter, ok := ctx.writer.(*CompressResponseWriter); ok {
+			compressResWriter.Disable()
+		}
+		return
+	}
+
+	// the response depends on the Accept-Encoding, even if it's not compressed for this client.
+	addVaryHeader(ctx.writer.Header(), acceptEncodingHeaderKey)
+	if encoding := ctx.NegotiateEncoding(compressionEncodings...); encoding != "" {
+		ctx.compressResponseWriter(encoding).minSize = CompressMinSize
+	}
+}
+
 //  +------------------------------------------------------------+
 //  | Rich Body Content Writers/Renderers                        |
 //  +------------------------------------------------------------+
@@ -2043,16 +2290,16 @@ func (ctx *context) ServeContent(content io.ReadSeeker, filename string, modtime
 	ctx.ContentType(filename)
 	ctx.writer.Header().Set(lastModifiedHeaderKey, modtime.UTC().Format(ctx.Application().ConfigurationReadOnly().GetTimeFormat()))
 	ctx.StatusCode(http.StatusOK)
-	var out io.Writer
-	if gzipCompression && ctx.ClientSupportsGzip() {
-		ctx.writer.Header().Add(varyHeaderKey, acceptEncodingHeaderKey)
-		ctx.Header(contentEncodingHeaderKey, "gzip")
-
-		gzipWriter := acquireGzipWriter(ctx.writer)
-		defer releaseGzipWriter(gzipWriter)
-		out = gzipWriter
-	} else {
-		out = ctx.writer
+	var out io.Writer = ctx.writer
+	if gzipCompression && !isCompressedContentType(ctx.GetContentType()) {
+		addVaryHeader(ctx.writer.Header(), acceptEncodingHeaderKey)
+		if encoding := ctx.NegotiateEncoding(compressionEncodings...); encoding != "" {
+			if compressWriter, err := acquireCompressWriter(encoding, ctx.writer); err == nil {
+				ctx.Header(contentEncodingHeaderKey, encoding)
+				defer releaseCompressWriter(encoding, compressWriter)
+				out = compressWriter
+			}
+		}
 	}
 	_, err := io.Copy(out, content)
 	return errServeContent.With(err)
@@ -2158,17 +2405,32 @@ func (ctx *context) VisitAllCookies(visitor func(name string, value string)) {
 }
 
 // Session returns the current user's Session.
+//
+// It returns nil if the session can not be started,
+// the provider's error is logged, i.e when the session storage is unreachable.
 func (ctx *context) Session() sessions.Store {
+	if sess := ctx.RequestSession(); sess != nil {
+		return sess
+	}
+	return nil
+}
+
+// RequestSession returns the current user's Session, like the `Session`,
+// with its typed getters and flash messages.
+func (ctx *context) RequestSession() *sessions.Session {
 	sessmanager, err := ctx.Application().SessionManager()
 	if err != nil {
 		return nil
 	}
 
 	if ctx.session == nil {
-		ctx.session, err = sessmanager.SessionStart(ctx.writer, ctx.request)
+		// the session's fingerprint is bound to the client's IP behind the trusted proxies.
+		sess, err := sessmanager.StartSession(sessions.WithClientIP(ctx.request.Context(), ctx.RemoteAddr()), ctx.writer, ctx.request)
 		if err != nil {
+			ctx.Application().Logger().Errorf("session: start: %v", err)
 			return nil
 		}
+		ctx.session = sess
 	}
 
 	return ctx.session
@@ -2182,20 +2444,80 @@ func (ctx *context) SessionRegenerateID() sessions.Store {
 	}
 
 	if ctx.session != nil {
-		ctx.session = sessmanager.SessionRegenerateID(ctx.writer, ctx.request)
+		// save the modified values before move them to the new session id.
+		ctx.releaseSession()
+		store, err := sessmanager.Regenerate(ctx.request.Context(), ctx.writer, ctx.request)
+		if err != nil {
+			ctx.Application().Logger().Errorf("session: regenerate: %v", err)
+		} else if store != nil {
+			ctx.session = sessions.NewSession(store)
+		}
+	}
+
+	if ctx.session == nil {
+		return nil
+	}
+	return ctx.session
+}
+
+// SessionLogin binds the session to the user of the "uid", its session ID is regenerated.
+// The user's oldest sessions are destroyed if the session manager's MaxSessionsPerUser is exceeded.
+//
+// See the `sessions.Manager#DestroyOtherSessions` to log out the user's other devices.
+func (ctx *context) SessionLogin(uid string) *sessions.Session {
+	sessmanager, err := ctx.Application().SessionManager()
+	if err != nil {
+		return nil
+	}
+
+	if ctx.RequestSession() == nil {
+		return nil
 	}
+	// save the modified values before move them to the new session id.
+	ctx.releaseSession()
 
+	sess, err := sessmanager.Login(sessions.WithClientIP(ctx.request.Context(), ctx.RemoteAddr()), ctx.writer, ctx.request, uid)
+	if err != nil {
+		ctx.Application().Logger().Errorf("session: login: %v", err)
+		return nil
+	}
+	// the user id is saved at the end of the request.
+	ctx.session = sess
 	return ctx.session
 }
 
 // SessionDestroy destroys the whole session and removes the session id cookie.
 func (ctx *context) SessionDestroy() {
-	if sess := ctx.Session(); sess != nil {
+	if sess := ctx.RequestSession(); sess != nil {
 		sessmanager, err := ctx.Application().SessionManager()
 		if err != nil {
 			return
 		}
-		sessmanager.SessionDestroy(ctx.writer, ctx.request)
+		if err = sessmanager.Destroy(ctx.request.Context(), ctx.writer, ctx.request); err != nil {
+			ctx.Application().Logger().Errorf("session: destroy: %v", err)
+		}
+		ctx.session = nil
+	}
+}
+
+// releaseSession saves the session's modified values, the provider's error is logged.
+//
+// The cookie of the stores which keep the values on it, like the cookie one,
+// can't be sent after the response's headers, it's logged too.
+func (ctx *context) releaseSession() {
+	sessmanager, err := ctx.Application().SessionManager()
+	if err != nil {
+		return
+	}
+
+	written := ctx.writer.Written() != NoWritten
+	cookies := len(ctx.writer.Header()["Set-Cookie"])
+	if err = sessmanager.Release(ctx.request.Context(), ctx.session, ctx.writer); err != nil {
+		ctx.Application().Logger().Errorf("session: release: %v", err)
+		return
+	}
+	if written && len(ctx.writer.Header()["Set-Cookie"]) != cookies {
+		ctx.Application().Logger().Errorf("session: release: the cookie of the session's values is not sent, the response's headers were already written")
 	}
 }
 
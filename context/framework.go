@@ -52,4 +52,15 @@ type Application interface {
 	// If a handler is not already registered,
 	// then it creates & registers a new trivial handler on the-fly.
 	FireErrorCode(ctx Context)
+
+	// Validator returns the validator of the values which are read by the context's
+	// ReadJSON, ReadXML, ReadForm, ReadQuery and Bind, it's nil if the validation is disabled.
+	Validator() Validator
+}
+
+// Validator validates the values which are read from the request, see `Application#Validator`.
+type Validator interface {
+	// Validate returns nil if the value is valid, otherwise an error which describes the invalid fields,
+	// i.e the validator.ValidationErrors of the siris/validator package.
+	Validate(v interface{}) error
 }
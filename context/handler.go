@@ -4,6 +4,11 @@
 
 package context
 
+import (
+	"reflect"
+	"runtime"
+)
+
 // A Handler responds to an HTTP request.
 // It writes reply headers and data to the Context.ResponseWriter() and then return.
 // Returning signals that the request is finished;
@@ -24,3 +29,9 @@ type Handler func(Context)
 //
 // See `Handler` for more.
 type Handlers []Handler
+
+// HandlerName returns the name of the "h" handler, i.e "main.userHandler",
+// helpful for debugging.
+func HandlerName(h Handler) string {
+	return runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name()
+}
@@ -0,0 +1,356 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/go-siris/siris/core/msgpack"
+)
+
+//  +------------------------------------------------------------+
+//  | Content negotiation                                        |
+//  +------------------------------------------------------------+
+
+// Renderer writes the response body of a negotiated media type, see `RegisterRenderer`.
+// The Content-Type is already set to the media type when the renderer is called.
+type Renderer func(ctx Context, v interface{}) (int, error)
+
+const (
+	acceptHeaderKey = "Accept"
+
+	contentXMLApplicationHeaderValue = "application/xml"
+	contentYAMLHeaderValue           = "application/x-yaml"
+	contentMsgpackHeaderValue        = "application/msgpack"
+)
+
+var (
+	// renderers are the negotiable renderers, by media type.
+	renderers = make(map[string]Renderer)
+	// rendererOffers are the media types of the renderers in the order of their registration,
+	// the default offers of the Negotiate.
+	rendererOffers []string
+
+	// ErrNotAcceptable is returned by the Negotiate when none of the offers is acceptable by the client.
+	ErrNotAcceptable = errors.New("none of the offered media types is acceptable")
+	// ErrInvalidJSONPCallback is returned by the Negotiate when the "callback" url parameter
+	// of the JSONP is not a javascript identifier, i.e "jQuery123" or "app.onData".
+	ErrInvalidJSONPCallback = errors.New("invalid JSONP callback")
+
+	jsonpCallbackExp = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*(\.[a-zA-Z_$][a-zA-Z0-9_$]*)*$`)
+)
+
+// RegisterRenderer registers the renderer of a media type, i.e "application/vnd.api+json",
+// which the Negotiate can offer. A renderer of an existing media type, including the built'n ones, is replaced.
+//
+// The built'n media types are: application/json, application/xml, text/xml, application/javascript (JSONP),
+// application/x-yaml, application/msgpack, text/html and text/plain,
+// they are offered in that order if the Negotiate is called without offers,
+// the renderers registered later are offered after them.
+//
+// It should be called before serving.
+func RegisterRenderer(mediaType string, renderer Renderer) {
+	mediaType = strings.ToLower(mediaType)
+	if _, exists := renderers[mediaType]; !exists {
+		rendererOffers = append(rendererOffers, mediaType)
+	}
+	renderers[mediaType] = renderer
+}
+
+// UnregisterRenderer removes the renderer of a media type, including the built'n ones,
+// the Negotiate doesn't offer it anymore.
+//
+// It should be called before serving.
+func UnregisterRenderer(mediaType string) {
+	mediaType = strings.ToLower(mediaType)
+	if _, exists := renderers[mediaType]; !exists {
+		return
+	}
+	delete(renderers, mediaType)
+	for i, offer := range rendererOffers {
+		if offer == mediaType {
+			rendererOffers = append(rendererOffers[:i:i], rendererOffers[i+1:]...)
+			break
+		}
+	}
+}
+
+func init() {
+	renderJSON := func(ctx Context, v interface{}) (int, error) {
+		return WriteJSON(ctx.ResponseWriter(), v, defaultJSONOptions, ctx.Application().ConfigurationReadOnly().GetJSONInteratorReplacement())
+	}
+	renderXML := func(ctx Context, v interface{}) (int, error) {
+		return WriteXML(ctx.ResponseWriter(), v, defaultXMLOptions)
+	}
+
+	RegisterRenderer(contentJSONHeaderValue, renderJSON)
+	RegisterRenderer(contentXMLApplicationHeaderValue, renderXML)
+	RegisterRenderer(contentXMLHeaderValue, renderXML)
+	RegisterRenderer(contentJavascriptHeaderValue, func(ctx Context, v interface{}) (int, error) {
+		options := JSONP{Callback: ctx.URLParam("callback")}
+		if options.Callback != "" && !jsonpCallbackExp.MatchString(options.Callback) {
+			// the callback is written as it's, it could inject script.
+			ctx.StatusCode(http.StatusBadRequest)
+			return 0, ErrInvalidJSONPCallback
+		}
+		return WriteJSONP(ctx.ResponseWriter(), v, options, ctx.Application().ConfigurationReadOnly().GetJSONInteratorReplacement())
+	})
+	RegisterRenderer(contentYAMLHeaderValue, func(ctx Context, v interface{}) (int, error) {
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return 0, err
+		}
+		return ctx.Write(b)
+	})
+	RegisterRenderer(contentMsgpackHeaderValue, func(ctx Context, v interface{}) (int, error) {
+		b, err := msgpack.Marshal(v)
+		if err != nil {
+			return 0, err
+		}
+		return ctx.Write(b)
+	})
+	RegisterRenderer(contentHTMLHeaderValue, func(ctx Context, v interface{}) (int, error) {
+		filename := ctx.Values().GetString(negotiateViewContextKey)
+		if filename == "" {
+			// see `canRenderHTML`.
+			return ctx.WriteString(fmt.Sprint(v))
+		}
+
+		layout := ctx.Values().GetString(ctx.Application().ConfigurationReadOnly().GetViewLayoutContextKey())
+		if err := ctx.Application().View(ctx.ResponseWriter(), filename, layout, v); err != nil {
+			return 0, err
+		}
+		return ctx.ResponseWriter().Written(), nil
+	})
+	RegisterRenderer(contentTextHeaderValue, func(ctx Context, v interface{}) (int, error) {
+		return ctx.WriteString(fmt.Sprint(v))
+	})
+}
+
+// negotiateViewContextKey is the context's values key of the template of the Negotiate's HTML.
+const negotiateViewContextKey = "siris.negotiate.view"
+
+// NegotiateView sets the template which renders the data of the `Negotiate` as HTML.
+func (ctx *context) NegotiateView(filename string) {
+	ctx.values.Set(negotiateViewContextKey, filename)
+}
+
+// canRenderHTML reports whether the data can be negotiated as HTML,
+// that is if a template is set by the NegotiateView or the data is a string, written as it is.
+func (ctx *context) canRenderHTML(v interface{}) bool {
+	if ctx.values.GetString(negotiateViewContextKey) != "" {
+		return true
+	}
+	_, ok := v.(string)
+	return ok
+}
+
+// Negotiate writes the data as the media type, of the "offers", which is the most acceptable by the client,
+// based on the Accept header's media ranges and their q-values.
+// The offers are the media types of the registered renderers, see `RegisterRenderer`,
+// in the server's order of preference; all of them are offered if empty.
+//
+// The HTML is rendered by the template of the `NegotiateView`, through the attached view engine,
+// otherwise it's offered only if the data is a string.
+//
+// The "Vary: Accept" header is always set.
+// If none of the offers is acceptable then it fires the 406 error code handler and returns the ErrNotAcceptable.
+func (ctx *context) Negotiate(v interface{}, offers ...string) (int, error) {
+	ctx.writer.Header().Add(varyHeaderKey, acceptHeaderKey)
+
+	if len(offers) == 0 {
+		offers = rendererOffers
+	}
+
+	var candidates []string
+	for _, offer := range offers {
+		offer = strings.ToLower(offer)
+		if renderers[offer] == nil {
+			continue
+		}
+		if offer == contentHTMLHeaderValue && !ctx.canRenderHTML(v) {
+			continue
+		}
+		candidates = append(candidates, offer)
+	}
+
+	mediaType := negotiateMediaType(ctx.GetHeader(acceptHeaderKey), candidates)
+	if mediaType == "" {
+		ctx.StatusCode(http.StatusNotAcceptable)
+		ctx.Application().FireErrorCode(ctx)
+		return 0, ErrNotAcceptable
+	}
+
+	if isTextMediaType(mediaType) {
+		ctx.ContentType(mediaType)
+	} else {
+		ctx.writer.Header().Set(contentTypeHeaderKey, mediaType)
+	}
+	n, err := renderers[mediaType](ctx, v)
+	if err != nil {
+		// the renderer may set a client error status code, i.e on invalid parameters.
+		if ctx.GetStatusCode() < http.StatusBadRequest {
+			ctx.StatusCode(http.StatusInternalServerError)
+		}
+		return 0, err
+	}
+	return n, nil
+}
+
+// isTextMediaType reports whether the charset should be appended to the media type's Content-Type.
+func isTextMediaType(mediaType string) bool {
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	for _, suffix := range []string{"json", "xml", "javascript", "yaml"} {
+		if strings.HasSuffix(mediaType, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptRange is a media range of the Accept header.
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// parseAccept parses the media ranges of the Accept header,
+// the ranges with invalid q-values are ignored.
+func parseAccept(header string) (ranges []acceptRange) {
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		mediaRange := strings.ToLower(strings.TrimSpace(params[0]))
+		slash := strings.IndexByte(mediaRange, '/')
+		if slash == -1 {
+			if mediaRange != "*" {
+				continue
+			}
+			// some clients send a single "*".
+			mediaRange, slash = "*/*", 1
+		}
+
+		r := acceptRange{typ: mediaRange[:slash], subtype: mediaRange[slash+1:], q: 1}
+		valid := true
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if len(param) < 2 || (param[0] != 'q' && param[0] != 'Q') || param[1] != '=' {
+				continue
+			}
+			q, err := strconv.ParseFloat(param[2:], 64)
+			if err != nil || q < 0 || q > 1 {
+				valid = false
+				break
+			}
+			r.q = q
+		}
+		if valid {
+			ranges = append(ranges, r)
+		}
+	}
+	return
+}
+
+// negotiateEncoding returns the content coding, of the offers, with the highest q-value of the Accept-Encoding header,
+// the earliest of the offers wins on equal q-values.
+// A coding which is not listed gets the q-value of the "*", if any, and the "x-gzip" is an alias of the "gzip".
+// It returns an empty string if none of the offers is acceptable.
+func negotiateEncoding(header string, offers []string) string {
+	if strings.TrimSpace(header) == "" {
+		return ""
+	}
+
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+		if coding == "" {
+			continue
+		}
+
+		q, valid := 1.0, true
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if len(param) < 2 || (param[0] != 'q' && param[0] != 'Q') || param[1] != '=' {
+				continue
+			}
+			v, err := strconv.ParseFloat(param[2:], 64)
+			if valid = err == nil && v >= 0 && v <= 1; valid {
+				q = v
+			}
+		}
+		if valid {
+			qualities[coding] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q, ok := qualities[offer]
+		if !ok && offer == "gzip" {
+			q, ok = qualities["x-gzip"]
+		}
+		if !ok {
+			q = qualities["*"]
+		}
+
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// negotiateMediaType returns the offer with the highest quality of the Accept header,
+// the earliest of the offers wins on equal qualities.
+// The quality of an offer is the one of its most specific media range.
+// It returns the first offer if the header is empty, or an empty string if none of the offers is acceptable.
+func negotiateMediaType(header string, offers []string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(header) == "" {
+		return offers[0]
+	}
+
+	ranges := parseAccept(header)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		slash := strings.IndexByte(offer, '/')
+		if slash == -1 {
+			continue
+		}
+		typ, subtype := offer[:slash], offer[slash+1:]
+
+		q, specificity := 0.0, -1
+		for _, r := range ranges {
+			s := -1
+			switch {
+			case r.typ == typ && r.subtype == subtype:
+				s = 2
+			case r.typ == typ && r.subtype == "*":
+				s = 1
+			case r.typ == "*" && r.subtype == "*":
+				s = 0
+			}
+			if s > specificity {
+				q, specificity = r.q, s
+			}
+		}
+
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
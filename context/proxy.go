@@ -0,0 +1,96 @@
+package context
+
+import (
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// trustedProxies are the parsed Configuration.TrustedProxies.
+type trustedProxies []*net.IPNet
+
+// contains reports whether the "ip" is a trusted proxy.
+func (t trustedProxies) contains(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, n := range t {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the client's IP of a proxies chain, i.e the "X-Forwarded-For" list.
+//
+// The chain is walked from right to left, the first IP which is not trusted is the client's one,
+// if all of them are trusted, or there are no trusted proxies at all, the left-most IP is returned.
+func (t trustedProxies) clientIP(ips []string) string {
+	if len(ips) == 0 {
+		return ""
+	}
+
+	if len(t) > 0 {
+		for i := len(ips) - 1; i >= 0; i-- {
+			if !t.contains(net.ParseIP(ips[i])) {
+				return ips[i]
+			}
+		}
+	}
+
+	return ips[0]
+}
+
+// splitHeaderList splits comma separated header values, i.e "X-Forwarded-For: ip1, ip2",
+// empty entries are skipped.
+func splitHeaderList(values []string) (list []string) {
+	for _, v := range values {
+		for _, entry := range strings.Split(v, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				list = append(list, entry)
+			}
+		}
+	}
+	return
+}
+
+// stripIPPort returns the IP of a "Forwarded" header's node,
+// i.e "192.0.2.43:47011" or "[2001:db8:cafe::17]:4711".
+func stripIPPort(node string) string {
+	node = strings.TrimSpace(node)
+	if ip, _, err := net.SplitHostPort(node); err == nil {
+		return ip
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(node, "["), "]")
+}
+
+// remoteAddrHeaders returns the canonical names of the enabled "headers",
+// first the ones which are part of the "order" and after the rest, sorted by name.
+func remoteAddrHeaders(headers map[string]bool, order []string) []string {
+	enabled := make(map[string]bool, len(headers))
+	for headerName, ok := range headers {
+		if ok {
+			enabled[http.CanonicalHeaderKey(headerName)] = true
+		}
+	}
+
+	names := make([]string, 0, len(enabled))
+	for _, headerName := range order {
+		headerName = http.CanonicalHeaderKey(headerName)
+		if enabled[headerName] {
+			names = append(names, headerName)
+			delete(enabled, headerName)
+		}
+	}
+
+	rest := make([]string, 0, len(enabled))
+	for headerName := range enabled {
+		rest = append(rest, headerName)
+	}
+	sort.Strings(rest)
+
+	return append(names, rest...)
+}
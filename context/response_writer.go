@@ -10,6 +10,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"strconv"
 	"sync"
 
 	"github.com/go-siris/siris/core/errors"
@@ -113,6 +114,8 @@ type responseWriter struct {
 	// Sometimes is useful to keep the event,
 	// so we keep one func only and let the user decide when he/she wants to override it with an empty func before the FireStatusCode (context's behavior)
 	beforeFlush func()
+	// the body is counted but not sent, see `DiscardBody`.
+	discard bool
 }
 
 var _ ResponseWriter = &responseWriter{}
@@ -135,6 +138,7 @@ func (w *responseWriter) Naive() http.ResponseWriter {
 // and initialize or reset the response writer's field's values.
 func (w *responseWriter) BeginResponse(underline http.ResponseWriter) {
 	w.beforeFlush = nil
+	w.discard = false
 	w.written = NoWritten
 	w.statusCode = defaultStatusCode
 	w.ResponseWriter = underline
@@ -144,9 +148,29 @@ func (w *responseWriter) BeginResponse(underline http.ResponseWriter) {
 //
 // Here is the place which we can make the last checks or do a cleanup.
 func (w *responseWriter) EndResponse() {
+	if w.discard {
+		w.writeDiscardedHeader()
+	}
 	releaseResponseWriter(w)
 }
 
+// DiscardBody drops the next writes of the body, only the headers are sent,
+// with the Content-Length of the discarded body, if not set.
+// It's used by the router to serve the HEAD requests through the GET handlers.
+//
+// The headers are sent at the end of the response, after the body of the wrapping
+// response writers, i.e the ResponseRecorder and the CompressResponseWriter, is written.
+func (w *responseWriter) DiscardBody() {
+	w.discard = true
+}
+
+func (w *responseWriter) writeDiscardedHeader() {
+	if w.written > 0 && w.Header().Get("Content-Length") == "" {
+		w.Header().Set("Content-Length", strconv.Itoa(w.written))
+	}
+	w.ResponseWriter.WriteHeader(w.statusCode)
+}
+
 // Written should returns the total length of bytes that were being written to the client.
 // In addition Siris provides some variables to help low-level actions:
 // NoWritten, means that nothing were written yet and the response writer is still live.
@@ -173,7 +197,9 @@ func (w *responseWriter) WriteHeader(statusCode int) {
 func (w *responseWriter) tryWriteHeader() {
 	if w.written == NoWritten { // before write, once.
 		w.written = StatusCodeWritten
-		w.ResponseWriter.WriteHeader(w.statusCode)
+		if !w.discard { // sent at the end of the response.
+			w.ResponseWriter.WriteHeader(w.statusCode)
+		}
 	}
 }
 
@@ -197,6 +223,10 @@ func (w *responseWriter) tryWriteHeader() {
 // possible to maximize compatibility.
 func (w *responseWriter) Write(contents []byte) (int, error) {
 	w.tryWriteHeader()
+	if w.discard {
+		w.written += len(contents)
+		return len(contents), nil
+	}
 	n, err := w.ResponseWriter.Write(contents)
 	w.written += n
 	return n, err
@@ -214,6 +244,10 @@ func (w *responseWriter) Writef(format string, a ...interface{}) (n int, err err
 // Returns the number of bytes written and any write error encountered.
 func (w *responseWriter) WriteString(s string) (int, error) {
 	w.tryWriteHeader()
+	if w.discard {
+		w.written += len(s)
+		return len(s), nil
+	}
 	n, err := io.WriteString(w.ResponseWriter, s)
 	w.written += n
 	return n, err
@@ -249,6 +283,7 @@ func (w *responseWriter) Clone() ResponseWriter {
 	wc.statusCode = w.statusCode
 	wc.beforeFlush = w.beforeFlush
 	wc.written = w.written
+	wc.discard = w.discard
 	return wc
 }
 
@@ -306,6 +341,9 @@ func (w *responseWriter) Flush() {
 	// if the client is connected through an HTTP proxy,
 	// the buffered data may not reach the client until the response
 	// completes.
+	if w.discard { // the headers are sent at the end of the response.
+		return
+	}
 	if fl, isFlusher := w.ResponseWriter.(http.Flusher); isFlusher {
 		fl.Flush()
 	}
@@ -0,0 +1,411 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//  +------------------------------------------------------------+
+//  | Server-Sent Events                                         |
+//  +------------------------------------------------------------+
+
+const (
+	contentEventStreamHeaderValue = "text/event-stream"
+	lastEventIDHeaderKey          = "Last-Event-ID"
+)
+
+// SSEHeartbeat is the interval of the comments which the EventWriter sends while idle,
+// they keep the connection alive through the proxies and detect the gone clients.
+// Zero disables the heartbeats.
+var SSEHeartbeat = 15 * time.Second
+
+// SSEWriteTimeout is the maximum duration of an event's write, the event stream is closed
+// when its client doesn't read it in time. Zero disables the timeout.
+//
+// The writes are timed out only if the underline response writer can set its write deadline,
+// i.e the net/http's one since Go 1.20.
+var SSEWriteTimeout = 10 * time.Second
+
+var (
+	// ErrEventStreamClosed is returned by the EventWriter's methods
+	// after the client is gone, the `Close` is called or the handler is returned.
+	ErrEventStreamClosed = errors.New("sse: event stream is closed")
+	errEventNewLine      = errors.New("sse: the event's name and id must not contain new lines")
+)
+
+// EventWriter writes the Server-Sent Events of a response, it's returned by the `Context#SSE`.
+//
+// Its methods are safe for concurrent use,
+// but the events should not be sent after the handler is returned.
+type EventWriter struct {
+	writer      ResponseWriter
+	lastEventID string
+
+	mu        sync.Mutex // protects the writes.
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// SSE prepares the response for Server-Sent Events and returns its event writer.
+//
+// The headers are sent immediately, the response compression, if enabled, is turned off.
+// The event writer sends a comment every SSEHeartbeat while idle, each write takes at most SSEWriteTimeout,
+// and it's closed when the client is gone, see `EventWriter#Done`, or when the handler is returned.
+//
+// Usage:
+//	app.Get("/events", func(ctx context.Context) {
+//		events := ctx.SSE()
+//		for {
+//			select {
+//			case <-events.Done():
+//				return
+//			case n := <-notifications:
+//				events.Send("notification", n.ID, n)
+//			}
+//		}
+//	})
+//
+// See the `Broadcaster` too.
+func (ctx *context) SSE() *EventWriter {
+	// the compress response writer sends the body at the end of the request.
+	if compressResWriter, ok := ctx.writer.(*CompressResponseWriter); ok {
+		compressResWriter.Disable()
+		ctx.ResetResponseWriter(compressResWriter.ResponseWriter)
+	}
+
+	header := ctx.writer.Header()
+	header.Set(contentTypeHeaderKey, contentEventStreamHeaderValue)
+	header.Set(cacheControlHeaderKey, "no-cache")
+	// disables the response buffering of the nginx proxies.
+	header.Set("X-Accel-Buffering", "no")
+	header.Del(contentLengthHeaderKey)
+
+	w := &EventWriter{
+		writer:      ctx.writer,
+		lastEventID: ctx.GetHeader(lastEventIDHeaderKey),
+		done:        make(chan struct{}),
+	}
+
+	ctx.StatusCode(http.StatusOK)
+	ctx.writer.Write(nil) // sends the status code and the headers.
+	ctx.writer.Flush()
+
+	// close the event writer when the handler is returned,
+	// no events can be sent after that, the pending write of the heartbeats is waited,
+	// so the response writer is not written after it's released.
+	beforeFlush := ctx.writer.GetBeforeFlush()
+	ctx.writer.SetBeforeFlush(func() {
+		w.Close()
+		if beforeFlush != nil {
+			beforeFlush()
+		}
+	})
+
+	go w.keepAlive(ctx.writer.CloseNotify(), SSEHeartbeat)
+	return w
+}
+
+// keepAlive sends the heartbeats and closes the event writer when the client is gone.
+func (w *EventWriter) keepAlive(notifyClosed <-chan bool, interval time.Duration) {
+	var heartbeat <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-notifyClosed:
+			w.closeStream()
+			return
+		case <-heartbeat:
+			if w.Comment("") != nil {
+				return
+			}
+		}
+	}
+}
+
+// LastEventID returns the id of the last event which the client received before its reconnection,
+// the Last-Event-ID header, it's empty on the first connection.
+func (w *EventWriter) LastEventID() string {
+	return w.lastEventID
+}
+
+// Done returns a channel which is closed when the client is gone,
+// the `Close` is called or the handler is returned.
+func (w *EventWriter) Done() <-chan struct{} {
+	return w.done
+}
+
+// Close closes the event stream, the next events are not sent, it's safe to be called more than once.
+// It waits for a pending write, which takes at most SSEWriteTimeout.
+// The handler should be returned after that, so the response is ended.
+func (w *EventWriter) Close() {
+	w.closeStream()
+	w.mu.Lock()
+	w.mu.Unlock()
+}
+
+// closeStream closes the event stream without waiting for a pending write.
+func (w *EventWriter) closeStream() {
+	w.closeOnce.Do(func() {
+		close(w.done)
+	})
+}
+
+// writeDeadliner is implemented by the response writers which can time out their writes,
+// i.e the net/http's one since Go 1.20.
+type writeDeadliner interface {
+	SetWriteDeadline(deadline time.Time) error
+}
+
+// write writes and flushes the formatted data, it closes the stream on failure.
+func (w *EventWriter) write(b []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return ErrEventStreamClosed
+	default:
+	}
+
+	if timeout := SSEWriteTimeout; timeout > 0 {
+		// the deadline is removed after the write, so the connection can be reused after the response.
+		if d, ok := w.writer.Naive().(writeDeadliner); ok {
+			d.SetWriteDeadline(time.Now().Add(timeout))
+			defer d.SetWriteDeadline(time.Time{})
+		}
+	}
+
+	if _, err := w.writer.Write(b); err != nil {
+		w.closeStream()
+		return err
+	}
+	w.writer.Flush()
+	return nil
+}
+
+// Send sends an event to the client, the "event" is its name, if empty the client fires the "message" event,
+// and the "id" is its id, if not empty the client sends it as the Last-Event-ID on reconnection.
+//
+// The data can be a string, a []byte or any value which is encoded as JSON,
+// the multi-line strings are sent as multiple data lines.
+func (w *EventWriter) Send(event, id string, data interface{}) error {
+	b, err := formatEvent(event, id, data)
+	if err != nil {
+		return err
+	}
+	return w.write(b)
+}
+
+// Retry sends the time which the client should wait before its reconnection.
+func (w *EventWriter) Retry(d time.Duration) error {
+	return w.write([]byte("retry: " + strconv.FormatInt(int64(d/time.Millisecond), 10) + "\n\n"))
+}
+
+// Comment sends a comment, which the client ignores, i.e to keep the connection alive.
+func (w *EventWriter) Comment(text string) error {
+	var b bytes.Buffer
+	for _, line := range splitLines(text) {
+		b.WriteString(":")
+		if line != "" {
+			b.WriteString(" " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	return w.write(b.Bytes())
+}
+
+// formatEvent returns the wire format of an event.
+func formatEvent(event, id string, data interface{}) ([]byte, error) {
+	if strings.ContainsAny(event, "\r\n") || strings.ContainsAny(id, "\r\n") {
+		return nil, errEventNewLine
+	}
+
+	var s string
+	switch v := data.(type) {
+	case nil:
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		s = string(b)
+	}
+
+	var b bytes.Buffer
+	if id != "" {
+		b.WriteString("id: " + id + "\n")
+	}
+	if event != "" {
+		b.WriteString("event: " + event + "\n")
+	}
+	for _, line := range splitLines(s) {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	return b.Bytes(), nil
+}
+
+func splitLines(s string) []string {
+	s = strings.Replace(s, "\r\n", "\n", -1)
+	return strings.Split(strings.Replace(s, "\r", "\n", -1), "\n")
+}
+
+//  +------------------------------------------------------------+
+//  | Server-Sent Events Broadcaster                             |
+//  +------------------------------------------------------------+
+
+// DefaultBroadcasterBuffer is the number of the pending events of a subscriber,
+// a subscriber which falls behind by more events is closed, so its client reconnects.
+const DefaultBroadcasterBuffer = 64
+
+type broadcastEvent struct {
+	id   string
+	data []byte
+}
+
+// Broadcaster sends the same Server-Sent Events to all of its subscribers,
+// i.e the clients of a dashboard.
+//
+// The last events are kept, so the reconnected clients receive the events
+// which were sent after their Last-Event-ID.
+//
+// Usage:
+//	events := context.NewBroadcaster(100)
+//	app.Get("/events", func(ctx context.Context) {
+//		events.Subscribe(ctx.SSE())
+//	})
+//	// somewhere else.
+//	events.Broadcast("stats", id, stats)
+type Broadcaster struct {
+	mu          sync.Mutex
+	subscribers map[*EventWriter]chan broadcastEvent
+	history     []broadcastEvent
+	historySize int
+}
+
+// NewBroadcaster returns a new Broadcaster which keeps the last "historySize" events with an id
+// for the reconnected clients, zero keeps none.
+func NewBroadcaster(historySize int) *Broadcaster {
+	return &Broadcaster{
+		subscribers: make(map[*EventWriter]chan broadcastEvent),
+		historySize: historySize,
+	}
+}
+
+// Broadcast sends an event to all the subscribers, see `EventWriter#Send`.
+// A subscriber which falls behind by more than DefaultBroadcasterBuffer events is closed.
+func (b *Broadcaster) Broadcast(event, id string, data interface{}) error {
+	payload, err := formatEvent(event, id, data)
+	if err != nil {
+		return err
+	}
+	e := broadcastEvent{id: id, data: payload}
+
+	var slow []*EventWriter
+
+	b.mu.Lock()
+	if id != "" && b.historySize > 0 {
+		if len(b.history) == b.historySize {
+			b.history = append(b.history[:0], b.history[1:]...)
+		}
+		b.history = append(b.history, e)
+	}
+
+	for w, ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+			// too slow, it reconnects and gets the missed events from the history.
+			delete(b.subscribers, w)
+			slow = append(slow, w)
+		}
+	}
+	b.mu.Unlock()
+
+	// their pending writes are not waited, they are timed out.
+	for _, w := range slow {
+		w.closeStream()
+	}
+	return nil
+}
+
+// Subscribe sends the broadcasted events to the event writer until its client is gone or it's closed,
+// it blocks, so it should be the last call of the handler.
+//
+// The kept events after the event writer's LastEventID are sent first.
+func (b *Broadcaster) Subscribe(w *EventWriter) {
+	ch := make(chan broadcastEvent, DefaultBroadcasterBuffer)
+
+	b.mu.Lock()
+	missed := b.missed(w.LastEventID())
+	b.subscribers[w] = ch
+	b.mu.Unlock()
+
+	defer b.unsubscribe(w)
+
+	for _, e := range missed {
+		if w.write(e.data) != nil {
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-w.Done():
+			return
+		case e := <-ch:
+			if w.write(e.data) != nil {
+				return
+			}
+		}
+	}
+}
+
+// missed returns the kept events after the lastEventID, none if it's empty or not kept anymore.
+func (b *Broadcaster) missed(lastEventID string) []broadcastEvent {
+	if lastEventID == "" {
+		return nil
+	}
+	for i, e := range b.history {
+		if e.id == lastEventID {
+			return append([]broadcastEvent(nil), b.history[i+1:]...)
+		}
+	}
+	return nil
+}
+
+func (b *Broadcaster) unsubscribe(w *EventWriter) {
+	b.mu.Lock()
+	delete(b.subscribers, w)
+	b.mu.Unlock()
+}
+
+// Len returns the number of the subscribers.
+func (b *Broadcaster) Len() int {
+	b.mu.Lock()
+	n := len(b.subscribers)
+	b.mu.Unlock()
+	return n
+}
@@ -6,6 +6,7 @@ package host
 // supervisor.
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"runtime"
 	"time"
@@ -19,8 +20,9 @@ import (
 // WriteStartupLogOnServe is a task which accepts a logger(io.Writer)
 // and logs the listening address
 // by a generated message based on the host supervisor's server and writes it to the "w".
+// The "routes", if any, are printed right after the banner, i.e the application's route table.
 // This function should be registered on Serve.
-func WriteStartupLogOnServe(logger *zap.SugaredLogger, banner string) func(TaskHost) {
+func WriteStartupLogOnServe(logger *zap.SugaredLogger, banner string, routes ...fmt.Stringer) func(TaskHost) {
 	return func(h TaskHost) {
 		guessScheme := nettools.ResolveScheme(h.Supervisor.manuallyTLS)
 		listeningURI := nettools.ResolveURL(guessScheme, h.Supervisor.Server.Addr)
@@ -28,6 +30,13 @@ func WriteStartupLogOnServe(logger *zap.SugaredLogger, banner string) func(TaskH
 		if runtime.GOOS == "darwin" {
 			interruptkey = "CMD"
 		}
+
+		for _, r := range routes {
+			if table := r.String(); table != "" {
+				banner += "\n\n" + table
+			}
+		}
+
 		logger.Infof("%s\n\nNow listening on: %s\nApplication started. Press %s+C to shut down.\n",
 			banner, listeningURI, interruptkey)
 	}
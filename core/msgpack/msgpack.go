@@ -0,0 +1,523 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package msgpack encodes and decodes the MessagePack format,
+// see https://github.com/msgpack/msgpack/blob/master/spec.md.
+//
+// It's used by the sessions' MsgpackSerializer and the context's Negotiate.
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Marshal encodes the "v" to msgpack.
+//
+// Time values are encoded as RFC3339 strings, structs as maps of their exported fields,
+// named by their "msgpack" or, if missing, "json" tag; the "-" name and the "omitempty" option are respected,
+// the fields of the embedded structs without a name are promoted.
+func Marshal(v interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := encode(buf, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalMap decodes a msgpack map.
+// Integers are decoded as int64 or uint64, the nested maps with string keys as map[string]interface{}.
+func UnmarshalMap(data []byte) (map[interface{}]interface{}, error) {
+	r := bytes.NewReader(data)
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	n, ok, err := mapLen(b, r)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("msgpack: the data is not a map")
+	}
+	if n > r.Len() {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	values := make(map[interface{}]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := decode(r)
+		if err != nil {
+			return nil, err
+		}
+		if !isKey(k) {
+			return nil, fmt.Errorf("msgpack: invalid map key of type %T", k)
+		}
+		v, err := decode(r)
+		if err != nil {
+			return nil, err
+		}
+		values[k] = v
+	}
+	return values, nil
+}
+
+// isKey reports whether the decoded "k" can be a key of a Go map.
+func isKey(k interface{}) bool {
+	return k == nil || reflect.TypeOf(k).Comparable()
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	jsonNumberType = reflect.TypeOf(json.Number(""))
+)
+
+func encode(w *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() {
+		w.WriteByte(0xc0)
+		return nil
+	}
+
+	if v.Type() == timeType {
+		return encode(w, reflect.ValueOf(v.Interface().(time.Time).Format(time.RFC3339Nano)))
+	}
+
+	if v.Type() == jsonNumberType {
+		// integers are kept as integers.
+		n := json.Number(v.String())
+		if i, err := n.Int64(); err == nil {
+			writeInt(w, i)
+			return nil
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return err
+		}
+		return encode(w, reflect.ValueOf(f))
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			w.WriteByte(0xc0)
+			return nil
+		}
+		return encode(w, v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			w.WriteByte(0xc3)
+		} else {
+			w.WriteByte(0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeInt(w, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(w, v.Uint())
+	case reflect.Float32:
+		w.WriteByte(0xca)
+		binary.Write(w, binary.BigEndian, math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		w.WriteByte(0xcb)
+		binary.Write(w, binary.BigEndian, math.Float64bits(v.Float()))
+	case reflect.String:
+		s := v.String()
+		writeHeader(w, len(s), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		w.WriteString(s)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			w.WriteByte(0xc0)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			writeHeader(w, len(b), 0, 0, 0xc4, 0xc5, 0xc6)
+			w.Write(b)
+			return nil
+		}
+		writeHeader(w, v.Len(), 0x90, 16, 0, 0xdc, 0xdd)
+		for i := 0; i < v.Len(); i++ {
+			if err := encode(w, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			w.WriteByte(0xc0)
+			return nil
+		}
+		writeHeader(w, v.Len(), 0x80, 16, 0, 0xde, 0xdf)
+		for _, k := range v.MapKeys() {
+			if err := encode(w, k); err != nil {
+				return err
+			}
+			if err := encode(w, v.MapIndex(k)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		fields := structFields(v)
+		writeHeader(w, len(fields), 0x80, 16, 0, 0xde, 0xdf)
+		for _, f := range fields {
+			writeHeader(w, len(f.name), 0xa0, 32, 0xd9, 0xda, 0xdb)
+			w.WriteString(f.name)
+			if err := encode(w, f.value); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+func writeInt(w *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0:
+		writeUint(w, uint64(i))
+	case i >= -32:
+		w.WriteByte(byte(i))
+	case i >= math.MinInt8:
+		w.WriteByte(0xd0)
+		w.WriteByte(byte(i))
+	case i >= math.MinInt16:
+		w.WriteByte(0xd1)
+		binary.Write(w, binary.BigEndian, int16(i))
+	case i >= math.MinInt32:
+		w.WriteByte(0xd2)
+		binary.Write(w, binary.BigEndian, int32(i))
+	default:
+		w.WriteByte(0xd3)
+		binary.Write(w, binary.BigEndian, i)
+	}
+}
+
+func writeUint(w *bytes.Buffer, u uint64) {
+	switch {
+	case u <= 127:
+		w.WriteByte(byte(u))
+	case u <= math.MaxUint8:
+		w.WriteByte(0xcc)
+		w.WriteByte(byte(u))
+	case u <= math.MaxUint16:
+		w.WriteByte(0xcd)
+		binary.Write(w, binary.BigEndian, uint16(u))
+	case u <= math.MaxUint32:
+		w.WriteByte(0xce)
+		binary.Write(w, binary.BigEndian, uint32(u))
+	default:
+		w.WriteByte(0xcf)
+		binary.Write(w, binary.BigEndian, u)
+	}
+}
+
+// writeHeader writes the format and the length of a string, binary, array or map,
+// "fix" is the fix format's prefix, used if the length is less than the "fixMax".
+func writeHeader(w *bytes.Buffer, n int, fix byte, fixMax int, f8, f16, f32 byte) {
+	switch {
+	case n < fixMax:
+		w.WriteByte(fix | byte(n))
+	case f8 != 0 && n <= math.MaxUint8:
+		w.WriteByte(f8)
+		w.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		w.WriteByte(f16)
+		binary.Write(w, binary.BigEndian, uint16(n))
+	default:
+		w.WriteByte(f32)
+		binary.Write(w, binary.BigEndian, uint32(n))
+	}
+}
+
+func decode(r *bytes.Reader) (interface{}, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xe0 == 0xa0:
+		return readString(r, int(b&0x1f))
+	case b&0xf0 == 0x90:
+		return readArray(r, int(b&0x0f))
+	case b&0xf0 == 0x80:
+		return readMap(r, int(b&0x0f))
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		var u uint64
+		switch b {
+		case 0xcc:
+			var v uint8
+			err = binary.Read(r, binary.BigEndian, &v)
+			u = uint64(v)
+		case 0xcd:
+			var v uint16
+			err = binary.Read(r, binary.BigEndian, &v)
+			u = uint64(v)
+		case 0xce:
+			var v uint32
+			err = binary.Read(r, binary.BigEndian, &v)
+			u = uint64(v)
+		default:
+			err = binary.Read(r, binary.BigEndian, &u)
+			if err == nil && u > math.MaxInt64 {
+				return u, nil
+			}
+		}
+		return int64(u), err
+	case 0xd0:
+		var v int8
+		err = binary.Read(r, binary.BigEndian, &v)
+		return int64(v), err
+	case 0xd1:
+		var v int16
+		err = binary.Read(r, binary.BigEndian, &v)
+		return int64(v), err
+	case 0xd2:
+		var v int32
+		err = binary.Read(r, binary.BigEndian, &v)
+		return int64(v), err
+	case 0xd3:
+		var v int64
+		err = binary.Read(r, binary.BigEndian, &v)
+		return v, err
+	case 0xca:
+		var v uint32
+		err = binary.Read(r, binary.BigEndian, &v)
+		return float64(math.Float32frombits(v)), err
+	case 0xcb:
+		var v uint64
+		err = binary.Read(r, binary.BigEndian, &v)
+		return math.Float64frombits(v), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := readLen(r, b-0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return readString(r, n)
+	case 0xc4, 0xc5, 0xc6:
+		n, err := readLen(r, b-0xc4)
+		if err != nil {
+			return nil, err
+		}
+		return readBytes(r, n)
+	case 0xdc, 0xdd:
+		n, err := readLen(r, b-0xdc+1)
+		if err != nil {
+			return nil, err
+		}
+		return readArray(r, n)
+	case 0xde, 0xdf:
+		n, err := readLen(r, b-0xde+1)
+		if err != nil {
+			return nil, err
+		}
+		return readMap(r, n)
+	}
+
+	return nil, fmt.Errorf("msgpack: unsupported format 0x%x", b)
+}
+
+// mapLen returns the length of the map which starts with the "b" format.
+func mapLen(b byte, r *bytes.Reader) (int, bool, error) {
+	switch {
+	case b&0xf0 == 0x80:
+		return int(b & 0x0f), true, nil
+	case b == 0xde || b == 0xdf:
+		n, err := readLen(r, b-0xde+1)
+		return n, true, err
+	}
+	return 0, false, nil
+}
+
+// readLen reads a length of 8, 16 or 32 bits, the "size" is 0, 1 or 2 respectively.
+func readLen(r *bytes.Reader, size byte) (int, error) {
+	switch size {
+	case 0:
+		b, err := r.ReadByte()
+		return int(b), err
+	case 1:
+		var n uint16
+		err := binary.Read(r, binary.BigEndian, &n)
+		return int(n), err
+	default:
+		var n uint32
+		err := binary.Read(r, binary.BigEndian, &n)
+		return int(n), err
+	}
+}
+
+func readBytes(r *bytes.Reader, n int) ([]byte, error) {
+	if n > r.Len() {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := make([]byte, n)
+	_, err := io.ReadFull(r, b)
+	return b, err
+}
+
+func readString(r *bytes.Reader, n int) (string, error) {
+	b, err := readBytes(r, n)
+	return string(b), err
+}
+
+func readArray(r *bytes.Reader, n int) ([]interface{}, error) {
+	if n > r.Len() {
+		return nil, io.ErrUnexpectedEOF
+	}
+	arr := make([]interface{}, n)
+	for i := range arr {
+		v, err := decode(r)
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = v
+	}
+	return arr, nil
+}
+
+// readMap returns a map[string]interface{} if all the keys are strings,
+// otherwise a map[interface{}]interface{}.
+func readMap(r *bytes.Reader, n int) (interface{}, error) {
+	if n > r.Len() {
+		return nil, io.ErrUnexpectedEOF
+	}
+	keys := make([]interface{}, n)
+	values := make([]interface{}, n)
+	stringKeys := true
+	for i := 0; i < n; i++ {
+		k, err := decode(r)
+		if err != nil {
+			return nil, err
+		}
+		if !isKey(k) {
+			return nil, fmt.Errorf("msgpack: invalid map key of type %T", k)
+		}
+		v, err := decode(r)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := k.(string); !ok {
+			stringKeys = false
+		}
+		keys[i], values[i] = k, v
+	}
+
+	if stringKeys {
+		m := make(map[string]interface{}, n)
+		for i, k := range keys {
+			m[k.(string)] = values[i]
+		}
+		return m, nil
+	}
+
+	m := make(map[interface{}]interface{}, n)
+	for i, k := range keys {
+		m[k] = values[i]
+	}
+	return m, nil
+}
+
+type field struct {
+	name  string
+	value reflect.Value
+}
+
+// structFields returns the encoded fields of the struct "v", by order of declaration.
+func structFields(v reflect.Value) []field {
+	var fields []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, omitEmpty := fieldTag(sf)
+		if name == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if fv.Kind() == reflect.Ptr {
+					if fv.IsNil() {
+						continue
+					}
+					fv = fv.Elem()
+				}
+				fields = append(fields, structFields(fv)...)
+				continue
+			}
+		}
+
+		if sf.PkgPath != "" { // unexported.
+			continue
+		}
+		if omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, field{name: name, value: fv})
+	}
+	return fields
+}
+
+// fieldTag returns the name and the omitempty option of the "msgpack" or the "json" tag.
+func fieldTag(sf reflect.StructField) (string, bool) {
+	tag, ok := sf.Tag.Lookup("msgpack")
+	if !ok {
+		tag = sf.Tag.Get("json")
+	}
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			return parts[0], true
+		}
+	}
+	return parts[0], false
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
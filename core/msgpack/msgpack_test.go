@@ -0,0 +1,82 @@
+// black-box testing
+package msgpack_test
+
+import (
+	"io"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-siris/siris/core/msgpack"
+)
+
+type base struct {
+	ID int64 `json:"id"`
+}
+
+type user struct {
+	base
+	Name      string    `json:"name"`
+	Nickname  string    `json:"nickname,omitempty"`
+	Password  string    `json:"-"`
+	Admin     bool      `msgpack:"is_admin" json:"admin"`
+	CreatedAt time.Time `json:"created_at"`
+	Tags      []string
+	secret    string
+}
+
+func TestMarshalStruct(t *testing.T) {
+	createdAt := time.Date(2017, 7, 1, 12, 0, 0, 0, time.UTC)
+	u := &user{
+		base:      base{ID: math.MaxInt64},
+		Name:      "siris",
+		Password:  "pass",
+		Admin:     true,
+		CreatedAt: createdAt,
+		Tags:      []string{"a"},
+		secret:    "secret",
+	}
+
+	b, err := msgpack.Marshal(map[string]interface{}{"user": u})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := msgpack.UnmarshalMap(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		// the integers are not rounded through float64.
+		"id":         int64(math.MaxInt64),
+		"name":       "siris",
+		"is_admin":   true,
+		"created_at": createdAt.Format(time.RFC3339Nano),
+		"Tags":       []interface{}{"a"},
+	}
+	if got := values["user"]; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %#v but got %#v", expected, got)
+	}
+}
+
+func TestUnmarshalMap(t *testing.T) {
+	// fixmap of 1, fixstr "a", positive fixint 1.
+	values, err := msgpack.UnmarshalMap([]byte{0x81, 0xa1, 'a', 0x01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["a"] != int64(1) {
+		t.Fatalf("unexpected values %#v", values)
+	}
+
+	if _, err = msgpack.UnmarshalMap([]byte{0x91, 0x01}); err == nil {
+		t.Fatalf("expected an error for a non map")
+	}
+
+	// map32 of 2^32-1 entries without any entry.
+	if _, err = msgpack.UnmarshalMap([]byte{0xdf, 0xff, 0xff, 0xff, 0xff}); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected the io.ErrUnexpectedEOF for a length larger than the data but got: %v", err)
+	}
+}
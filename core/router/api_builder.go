@@ -107,6 +107,7 @@ func NewAPIBuilder() *APIBuilder {
 		relativePath:      "/",
 		routes:            new(repository),
 	}
+	rb.reverser = NewRoutePathReverser(rb)
 
 	return rb
 }
@@ -163,6 +164,14 @@ func (rb *APIBuilder) Handle(method string, registeredPath string, handlers ...c
 		return nil
 	}
 
+	if len(handlers) > 0 {
+		// the route's handlers contain the middleware and the done handlers too,
+		// the main handler is the last one before the done handlers.
+		r.setMainHandler(len(r.Handlers) - len(rb.doneGlobalHandlers) - 1)
+	} else {
+		r.setMainHandler(-1)
+	}
+
 	// global
 	rb.routes.register(r)
 
@@ -209,6 +218,7 @@ func (rb *APIBuilder) Party(relativePath string, handlers ...context.Handler) Pa
 		beginGlobalHandlers: rb.beginGlobalHandlers,
 		doneGlobalHandlers:  rb.doneGlobalHandlers,
 		reporter:            rb.reporter,
+		reverser:            rb.reverser,
 		// per-party/children
 		middleware:   middleware,
 		relativePath: fullpath,
@@ -282,12 +292,31 @@ func (rb *APIBuilder) GetRoutes() []*Route {
 	return rb.routes.getAll()
 }
 
+// RouteTable returns the route table of all the registered routes.
+//
+// See `RouteTableHandler` too.
+func (rb *APIBuilder) RouteTable() RouteTable {
+	return NewRouteTable(rb.GetRoutes())
+}
+
 // GetRoute returns the registered route based on its name, otherwise nil.
 // One note: "routeName" should be case-sensitive.
 func (rb *APIBuilder) GetRoute(routeName string) *Route {
 	return rb.routes.get(routeName)
 }
 
+// URLPath returns the path of a registered route based on its name
+// and the values of its named parameters, the rest of the "params"
+// are appended as query string.
+// Each value is validated against its parameter's macro type and functions.
+//
+// Usage:
+// app.Get("/users/{id:int min(1)}", userHandler).Name = "user"
+// app.URLPath("user", router.URLParams{"id": 42, "tab": "posts"}) // "/users/42?tab=posts", nil
+func (rb *APIBuilder) URLPath(routeName string, params URLParams) (string, error) {
+	return rb.reverser.NamedPath(routeName, params)
+}
+
 // Use appends Handler(s) to the current Party's routes and child routes.
 // If the current Party is the root, then it registers the middleware to all child Parties' routes too.
 //
@@ -184,7 +184,11 @@ func NewStaticHandlerBuilder(dir string) StaticHandlerBuilder {
 	}
 }
 
-// Gzip if enable is true then gzip compression is enabled for this static directory
+// Gzip if enable is true then compression is enabled for this static directory:
+// the precompressed sibling of a file, i.e "app.js.br" or "app.js.gz" of the "app.js", is served
+// if it exists and its content coding is acceptable by the client,
+// otherwise the file is compressed on each request in the br, gzip or deflate content coding,
+// see `context#Compress`.
 // Defaults to false
 func (w *fsHandler) Gzip(enable bool) StaticHandlerBuilder {
 	w.gzip = enable
@@ -247,12 +251,14 @@ func (w *fsHandler) Build() context.Handler {
 				path.Clean(upath),
 				false,
 				w.listDirectories,
-				(w.gzip && ctx.ClientSupportsGzip()),
+				w.gzip,
 			)
 
 			// check for any http errors after the file handler executed
 			if prevStatusCode >= 400 { // error found (404 or 400 or 500 usually)
-				if writer, ok := ctx.ResponseWriter().(*context.GzipResponseWriter); ok && writer != nil {
+				// the content coding of a precompressed file.
+				ctx.ResponseWriter().Header().Del(contentEncodingHeaderKey)
+				if writer, ok := ctx.ResponseWriter().(*context.CompressResponseWriter); ok && writer != nil {
 					writer.ResetBody()
 					writer.Disable()
 					// ctx.ResponseWriter.Header().Del(contentType) // application/x-gzip sometimes lawl
@@ -376,7 +382,7 @@ const sniffLen = 512
 // if modtime.IsZero(), modtime is unknown.
 // content must be seeked to the beginning of the file.
 // The sizeFunc is called at most once. Its error, if any, is sent in the HTTP response.
-func serveContent(ctx context.Context, name string, modtime time.Time, sizeFunc func() (int64, error), content io.ReadSeeker, gzip bool) (string, int) /* we could use the TransactionErrResult but prefer not to create new objects for each of the errors on static file handlers*/ {
+func serveContent(ctx context.Context, name string, modtime time.Time, sizeFunc func() (int64, error), content io.ReadSeeker, compress bool) (string, int) /* we could use the TransactionErrResult but prefer not to create new objects for each of the errors on static file handlers*/ {
 
 	setLastModified(ctx, modtime)
 	done, rangeReq := checkPreconditions(ctx, modtime)
@@ -419,8 +425,8 @@ func serveContent(ctx context.Context, name string, modtime time.Time, sizeFunc
 	sendSize := size
 	var sendContent io.Reader = content
 
-	if gzip {
-		_ = ctx.GzipResponseWriter()
+	if compress {
+		ctx.Compress(true)
 	}
 	if size >= 0 {
 		ranges, err := parseRange(rangeReq, size)
@@ -488,10 +494,9 @@ func serveContent(ctx context.Context, name string, modtime time.Time, sizeFunc
 			}()
 		}
 		ctx.Header("Accept-Ranges", "bytes")
-		if ctx.ResponseWriter().Header().Get(contentEncodingHeaderKey) == "" {
-
-			ctx.Header(contentLengthHeaderKey, strconv.FormatInt(sendSize, 10))
-		}
+		// the compress response writer removes it if the content is compressed,
+		// the partial content is never compressed.
+		ctx.Header(contentLengthHeaderKey, strconv.FormatInt(sendSize, 10))
 	}
 
 	ctx.StatusCode(code)
@@ -747,7 +752,7 @@ func checkPreconditions(ctx context.Context, modtime time.Time) (done bool, rang
 }
 
 // name is '/'-separated, not filepath.Separator.
-func serveFile(ctx context.Context, fs http.FileSystem, name string, redirect bool, showList bool, gzip bool) (string, int) {
+func serveFile(ctx context.Context, fs http.FileSystem, name string, redirect bool, showList bool, compress bool) (string, int) {
 	const indexPage = "/index.html"
 
 	// redirect .../index.html to .../
@@ -803,6 +808,7 @@ func serveFile(ctx context.Context, fs http.FileSystem, name string, redirect bo
 			defer ff.Close()
 			dd, err := ff.Stat()
 			if err == nil {
+				name = index
 				d = dd
 				f = ff
 			}
@@ -823,9 +829,65 @@ func serveFile(ctx context.Context, fs http.FileSystem, name string, redirect bo
 
 	}
 
+	if compress {
+		ctx.ResponseWriter().Header().Add(varyHeaderKey, acceptEncodingHeaderKey)
+		if pf, pd, encoding := openPrecompressed(ctx, fs, name); pf != nil {
+			defer pf.Close()
+			// the Content-Type is of the original file, sniff its content if its extension is unknown.
+			if _, haveType := ctx.ResponseWriter().Header()[contentTypeHeaderKey]; !haveType && TypeByExtension(filepath.Ext(d.Name())) == "" {
+				var buf [sniffLen]byte
+				n, _ := io.ReadFull(f, buf[:])
+				ctx.ContentType(http.DetectContentType(buf[:n]))
+			}
+			ctx.Header(contentEncodingHeaderKey, encoding)
+			sizeFunc := func() (int64, error) { return pd.Size(), nil }
+			return serveContent(ctx, d.Name(), pd.ModTime(), sizeFunc, pf, false)
+		}
+	}
+
 	// serveContent will check modification time
 	sizeFunc := func() (int64, error) { return d.Size(), nil }
-	return serveContent(ctx, d.Name(), d.ModTime(), sizeFunc, f, gzip)
+	return serveContent(ctx, d.Name(), d.ModTime(), sizeFunc, f, compress)
+}
+
+// precompressedEncodings are the content codings of the precompressed siblings of the static files,
+// in the server's order of preference, see `precompressedExtensions`.
+var precompressedEncodings = []string{context.EncodingBrotli, context.EncodingGzip}
+
+// precompressedExtensions are the extensions of the precompressed siblings, by their content coding.
+var precompressedExtensions = map[string]string{
+	context.EncodingBrotli: ".br",
+	context.EncodingGzip:   ".gz",
+}
+
+// openPrecompressed opens the precompressed sibling of the file, i.e the "app.js.br" of the "app.js",
+// of the content coding which is the most acceptable by the client.
+// Returns a nil file if there is no sibling of an acceptable content coding.
+func openPrecompressed(ctx context.Context, fs http.FileSystem, name string) (http.File, os.FileInfo, string) {
+	offers := append([]string(nil), precompressedEncodings...)
+	for len(offers) > 0 {
+		encoding := ctx.NegotiateEncoding(offers...)
+		if encoding == "" {
+			break
+		}
+
+		if f, err := fs.Open(name + precompressedExtensions[encoding]); err == nil {
+			if d, err := f.Stat(); err == nil && !d.IsDir() {
+				return f, d, encoding
+			}
+			f.Close()
+		}
+
+		// try the next acceptable content coding.
+		for i := range offers {
+			if offers[i] == encoding {
+				offers = append(offers[:i], offers[i+1:]...)
+				break
+			}
+		}
+	}
+
+	return nil, nil, ""
 }
 
 // toHTTPError returns a non-specific HTTP error message and status code
@@ -165,70 +165,148 @@ func (h *routerHandler) HandleRequest(ctx context.Context) {
 		}
 	}
 
+	if h.serve(ctx, method, path) {
+		return
+	}
+
+	config := ctx.Application().ConfigurationReadOnly()
+
+	// HEAD is served by the GET handlers, the headers are sent without the body.
+	if method == http.MethodHead && !config.GetDisableAutoHead() {
+		if handlers := h.find(ctx, http.MethodGet, path); len(handlers) > 0 {
+			if w, ok := ctx.ResponseWriter().(interface {
+				DiscardBody()
+			}); ok {
+				w.DiscardBody()
+			}
+			ctx.Do(handlers)
+			return
+		}
+	}
+
+	if method == http.MethodOptions && !config.GetDisableAutoOptions() {
+		if allow := h.allowedMethods(ctx, path); len(allow) > 0 {
+			ctx.Header("Allow", strings.Join(allow, ", "))
+			ctx.StatusCode(http.StatusOK)
+			return
+		}
+	}
+
+	if config.GetFireMethodNotAllowed() {
+		// a bit slower than previous implementation but @kataras let me to apply this change
+		// because it's more reliable.
+		//
+		// if `Configuration#FireMethodNotAllowed` is kept as defaulted(false) then this function will not
+		// run, therefore performance kept as before.
+		if allow := h.allowedMethods(ctx, path); len(allow) > 0 {
+			// RCF rfc2616 https://www.w3.org/Protocols/rfc2616/rfc2616-sec10.html
+			// The response MUST include an Allow header containing a list of valid methods for the requested resource.
+			ctx.Header("Allow", strings.Join(allow, ", "))
+			ctx.StatusCode(http.StatusMethodNotAllowed)
+			return
+		}
+	}
+
+	ctx.StatusCode(http.StatusNotFound)
+}
+
+// find returns the handlers of the route which matches the "method" and the "path",
+// returns nil if not found.
+func (h *routerHandler) find(ctx context.Context, method, path string) context.Handlers {
 	for i := range h.trees {
 		t := h.trees[i]
 		if method != t.Method {
 			continue
 		}
 
-		if h.hosts && t.Subdomain != "" {
-			requestHost := ctx.Host()
-			if nettools.IsLoopbackSubdomain(requestHost) {
-				// this fixes a bug when listening on
-				// 127.0.0.1:8080 for example
-				// and have a wildcard subdomain and a route registered to root domain.
-				continue // it's not a subdomain, it's something like 127.0.0.1 probably
-			}
-			// it's a dynamic wildcard subdomain, we have just to check if ctx.subdomain is not empty
-			if t.Subdomain == SubdomainWildcardIndicator {
-				// mydomain.com -> invalid
-				// localhost -> invalid
-				// sub.mydomain.com -> valid
-				// sub.localhost -> valid
-				serverHost := ctx.Application().ConfigurationReadOnly().GetVHost()
-				if serverHost == requestHost {
-					continue // it's not a subdomain, it's a full domain (with .com...)
-				}
-
-				dotIdx := strings.IndexByte(requestHost, '.')
-				slashIdx := strings.IndexByte(requestHost, '/')
-				if dotIdx > 0 && (slashIdx == -1 || slashIdx > dotIdx) {
-					// if "." was found anywhere but not at the first path segment (host).
-				} else {
-					continue
-				}
-				// continue to that, any subdomain is valid.
-			} else if !strings.HasPrefix(requestHost, t.Subdomain) { // t.Subdomain contains the dot.
-				continue
-			}
+		if !h.matchSubdomain(ctx, t) {
+			continue
 		}
-		handlers := t.Nodes.Find(path, ctx.Params())
-		if len(handlers) > 0 {
-			ctx.Do(handlers)
-			// found
-			return
+
+		// nil if not found or method not allowed.
+		return t.Nodes.Find(path, ctx.Params())
+	}
+
+	return nil
+}
+
+// serve executes the handlers of the route which matches the "method" and the "path",
+// returns false if not found.
+func (h *routerHandler) serve(ctx context.Context, method, path string) bool {
+	handlers := h.find(ctx, method, path)
+	if len(handlers) == 0 {
+		return false
+	}
+
+	ctx.Do(handlers)
+	return true
+}
+
+// matchSubdomain reports whether the request's host matches the subdomain of the "t".
+func (h *routerHandler) matchSubdomain(ctx context.Context, t *tree) bool {
+	if !h.hosts || t.Subdomain == "" {
+		return true
+	}
+
+	requestHost := ctx.Host()
+	if nettools.IsLoopbackSubdomain(requestHost) {
+		// this fixes a bug when listening on
+		// 127.0.0.1:8080 for example
+		// and have a wildcard subdomain and a route registered to root domain.
+		return false // it's not a subdomain, it's something like 127.0.0.1 probably
+	}
+	// it's a dynamic wildcard subdomain, we have just to check if ctx.subdomain is not empty
+	if t.Subdomain == SubdomainWildcardIndicator {
+		// mydomain.com -> invalid
+		// localhost -> invalid
+		// sub.mydomain.com -> valid
+		// sub.localhost -> valid
+		serverHost := ctx.Application().ConfigurationReadOnly().GetVHost()
+		if serverHost == requestHost {
+			return false // it's not a subdomain, it's a full domain (with .com...)
 		}
-		// not found or method not allowed.
-		break
-	}
-
-	if ctx.Application().ConfigurationReadOnly().GetFireMethodNotAllowed() {
-		for i := range h.trees {
-			t := h.trees[i]
-			// a bit slower than previous implementation but @kataras let me to apply this change
-			// because it's more reliable.
-			//
-			// if `Configuration#FireMethodNotAllowed` is kept as defaulted(false) then this function will not
-			// run, therefore performance kept as before.
-			if t.Nodes.Exists(path) {
-				// RCF rfc2616 https://www.w3.org/Protocols/rfc2616/rfc2616-sec10.html
-				// The response MUST include an Allow header containing a list of valid methods for the requested resource.
-				ctx.Header("Allow", t.Method)
-				ctx.StatusCode(http.StatusMethodNotAllowed)
+
+		dotIdx := strings.IndexByte(requestHost, '.')
+		slashIdx := strings.IndexByte(requestHost, '/')
+		// if "." was found anywhere but not at the first path segment (host).
+		// continue to that, any subdomain is valid.
+		return dotIdx > 0 && (slashIdx == -1 || slashIdx > dotIdx)
+	}
+
+	return strings.HasPrefix(requestHost, t.Subdomain) // t.Subdomain contains the dot.
+}
+
+// allowedMethods returns the sorted methods of all the routes which match the "path",
+// including the automatic HEAD and OPTIONS ones, if enabled.
+func (h *routerHandler) allowedMethods(ctx context.Context, path string) []string {
+	config := ctx.Application().ConfigurationReadOnly()
+
+	var allow []string
+	add := func(method string) {
+		for _, m := range allow {
+			if m == method {
 				return
 			}
 		}
+		allow = append(allow, method)
 	}
 
-	ctx.StatusCode(http.StatusNotFound)
+	for i := range h.trees {
+		t := h.trees[i]
+		if !h.matchSubdomain(ctx, t) || !t.Nodes.Exists(path) {
+			continue
+		}
+
+		add(t.Method)
+		if t.Method == http.MethodGet && !config.GetDisableAutoHead() {
+			add(http.MethodHead)
+		}
+	}
+
+	if len(allow) > 0 && !config.GetDisableAutoOptions() {
+		add(http.MethodOptions)
+	}
+
+	sort.Strings(allow)
+	return allow
 }
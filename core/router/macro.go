@@ -39,6 +39,8 @@ func registerBuiltinsMacroFuncs(out *macro.Map) {
 	registerAlphabeticalMacroFuncs(out.Alphabetical)
 	registerFileMacroFuncs(out.File)
 	registerPathMacroFuncs(out.Path)
+	registerInt64MacroFuncs(out.Int64)
+	registerUint64MacroFuncs(out.Uint64)
 }
 
 // String
@@ -51,6 +53,16 @@ func registerStringMacroFuncs(out *macro.Macro) {
 		return regexpEvaluator
 	})
 
+	// checks if param value is one of the comma separated values,
+	// i.e {status:enum(active,closed)} or {status:string enum(active,closed)}
+	out.RegisterFunc("enum", func(values string) macro.EvaluatorFunc {
+		fields := strings.Split(values, ",")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		return macro.NewEnumEvaluator(fields...)
+	})
+
 	// checks if param value starts with the 'prefix' arg
 	out.RegisterFunc("prefix", func(prefix string) macro.EvaluatorFunc {
 		return func(paramValue string) bool {
@@ -130,6 +142,86 @@ func registerIntMacroFuncs(out *macro.Macro) {
 	})
 }
 
+// Int64
+// numbers (0-9) with an optional minus sign, which fit in an int64
+func registerInt64MacroFuncs(out *macro.Macro) {
+	// checks if the param value's int64 representation is
+	// bigger or equal than 'min'
+	out.RegisterFunc("min", func(min int) macro.EvaluatorFunc {
+		return func(paramValue string) bool {
+			n, err := strconv.ParseInt(paramValue, 10, 64)
+			if err != nil {
+				return false
+			}
+			return n >= int64(min)
+		}
+	})
+
+	// checks if the param value's int64 representation is
+	// smaller or equal than 'max'
+	out.RegisterFunc("max", func(max int) macro.EvaluatorFunc {
+		return func(paramValue string) bool {
+			n, err := strconv.ParseInt(paramValue, 10, 64)
+			if err != nil {
+				return false
+			}
+			return n <= int64(max)
+		}
+	})
+
+	// checks if the param value's int64 representation is
+	// between min and max, including 'min' and 'max'
+	out.RegisterFunc("range", func(min, max int) macro.EvaluatorFunc {
+		return func(paramValue string) bool {
+			n, err := strconv.ParseInt(paramValue, 10, 64)
+			if err != nil {
+				return false
+			}
+			return n >= int64(min) && n <= int64(max)
+		}
+	})
+}
+
+// Uint64
+// only numbers (0-9), which fit in an uint64
+func registerUint64MacroFuncs(out *macro.Macro) {
+	// checks if the param value's uint64 representation is
+	// bigger or equal than 'min'
+	out.RegisterFunc("min", func(min int) macro.EvaluatorFunc {
+		return func(paramValue string) bool {
+			n, err := strconv.ParseUint(paramValue, 10, 64)
+			if err != nil {
+				return false
+			}
+			return min <= 0 || n >= uint64(min)
+		}
+	})
+
+	// checks if the param value's uint64 representation is
+	// smaller or equal than 'max'
+	out.RegisterFunc("max", func(max int) macro.EvaluatorFunc {
+		return func(paramValue string) bool {
+			n, err := strconv.ParseUint(paramValue, 10, 64)
+			if err != nil {
+				return false
+			}
+			return max >= 0 && n <= uint64(max)
+		}
+	})
+
+	// checks if the param value's uint64 representation is
+	// between min and max, including 'min' and 'max'
+	out.RegisterFunc("range", func(min, max int) macro.EvaluatorFunc {
+		return func(paramValue string) bool {
+			n, err := strconv.ParseUint(paramValue, 10, 64)
+			if err != nil || max < 0 {
+				return false
+			}
+			return (min <= 0 || n >= uint64(min)) && n <= uint64(max)
+		}
+	})
+}
+
 // Alphabetical
 // letters only (upper or lowercase)
 func registerAlphabeticalMacroFuncs(out *macro.Macro) {
@@ -227,8 +319,17 @@ func convertTmplToHandler(tmpl *macro.Template) context.Handler {
 		return func(ctx context.Context) {
 			for _, p := range tmpl.Params {
 				paramValue := ctx.Params().Get(p.Name)
-				// first, check for type evaluator
-				if !p.TypeEvaluator(paramValue) {
+				// first, check for type converter or evaluator
+				if p.TypeConverter != nil {
+					v, err := p.TypeConverter(paramValue)
+					if err != nil {
+						ctx.StatusCode(p.ErrCode)
+						ctx.StopExecution()
+						return
+					}
+					// store the typed value, handlers don't have to parse it again.
+					ctx.Params().SetValue(p.Name, v)
+				} else if !p.TypeEvaluator(paramValue) {
 					ctx.StatusCode(p.ErrCode)
 					ctx.StopExecution()
 					return
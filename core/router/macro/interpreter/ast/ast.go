@@ -3,6 +3,7 @@ package ast
 import (
 	"fmt"
 	"strconv"
+	"sync"
 )
 
 // ParamType is a specific uint8 type
@@ -39,20 +40,58 @@ const (
 	// Allows anything, should be the last part
 	// Declaration: /mypath/{myparam:path}
 	ParamTypePath
+	// ParamTypeUUID is the universally unique identifier type.
+	// Allows the canonical form of an uuid, i.e 6ba7b810-9dad-11d1-80b4-00c04fd430c8
+	// Declaration: /mypath/{myparam:uuid}
+	ParamTypeUUID
+	// ParamTypeInt64 is the signed 64-bit integer type.
+	// Allows numbers (0-9) with an optional minus sign which fit in an int64
+	// Declaration: /mypath/{myparam:int64}
+	ParamTypeInt64
+	// ParamTypeUint64 is the unsigned 64-bit integer type.
+	// Allows only numbers (0-9) which fit in an uint64
+	// Declaration: /mypath/{myparam:uint64}
+	ParamTypeUint64
+	// ParamTypeBool is the boolean type.
+	// Allows 1, t, T, TRUE, true, True, 0, f, F, FALSE, false, False
+	// Declaration: /mypath/{myparam:bool}
+	ParamTypeBool
+	// ParamTypeDate is the date type.
+	// Allows dates in the form of year-month-day, i.e 2017-07-27
+	// Declaration: /mypath/{myparam:date}
+	ParamTypeDate
+	// ParamTypeEnum is the enumeration type.
+	// Allows only the values of its arguments, separated by commas
+	// Declaration: /mypath/{myparam:enum(active,closed)}
+	ParamTypeEnum
+
+	// paramTypeCustom is the first type of the types registered by the user,
+	// see `RegisterParamType`.
+	paramTypeCustom
 )
 
-var paramTypes = map[string]ParamType{
-	"string":       ParamTypeString,
-	"int":          ParamTypeInt,
-	"alphabetical": ParamTypeAlphabetical,
-	"file":         ParamTypeFile,
-	"path":         ParamTypePath,
-	// could be named also:
-	// "tail":
-	// "wild"
-	// "wildcard"
+var (
+	paramTypes = map[string]ParamType{
+		"string":       ParamTypeString,
+		"int":          ParamTypeInt,
+		"alphabetical": ParamTypeAlphabetical,
+		"file":         ParamTypeFile,
+		"path":         ParamTypePath,
+		"uuid":         ParamTypeUUID,
+		"int64":        ParamTypeInt64,
+		"uint64":       ParamTypeUint64,
+		"bool":         ParamTypeBool,
+		"date":         ParamTypeDate,
+		"enum":         ParamTypeEnum,
+		// could be named also:
+		// "tail":
+		// "wild"
+		// "wildcard"
 
-}
+	}
+	paramTypesMu  sync.RWMutex
+	nextParamType = paramTypeCustom
+)
 
 // LookupParamType accepts the string
 // representation of a parameter type.
@@ -62,13 +101,53 @@ var paramTypes = map[string]ParamType{
 // "alphabetical"
 // "file"
 // "path"
+// "uuid"
+// "int64"
+// "uint64"
+// "bool"
+// "date"
+// "enum"
+// and any type registered by `RegisterParamType`.
 func LookupParamType(ident string) ParamType {
-	if typ, ok := paramTypes[ident]; ok {
+	paramTypesMu.RLock()
+	typ, ok := paramTypes[ident]
+	paramTypesMu.RUnlock()
+	if ok {
 		return typ
 	}
 	return ParamTypeUnExpected
 }
 
+// RegisterParamType registers a new parameter type
+// based on its "ident"ifier, the one which is being used on the route's path,
+// i.e "hex" for /mypath/{myparam:hex}.
+// Returns the existing type if the "ident" is already registered.
+//
+// The types are shared by all the macro maps but their evaluators are not,
+// a path of a map which didn't register the type fails to be parsed.
+//
+// It's being called by the `macro#Map.Register`,
+// which is the one that developers should use to register custom types
+// with their evaluator.
+func RegisterParamType(ident string) ParamType {
+	paramTypesMu.Lock()
+	defer paramTypesMu.Unlock()
+
+	if typ, ok := paramTypes[ident]; ok {
+		return typ
+	}
+
+	typ := nextParamType
+	nextParamType++
+	paramTypes[ident] = typ
+	return typ
+}
+
+// IsCustom reports whether the type is registered by the `RegisterParamType`.
+func (typ ParamType) IsCustom() bool {
+	return typ >= paramTypeCustom
+}
+
 // ParamStatement is a struct
 // which holds all the necessary information about a macro parameter.
 // It holds its type (string, int, alphabetical, file, path),
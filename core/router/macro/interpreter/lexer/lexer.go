@@ -107,6 +107,26 @@ func (l *Lexer) NextToken() (t token.Token) {
 	return
 }
 
+// NextTypeToken same as NextToken but it reads the parameter type's identifier,
+// which starts with a letter but it may contain digits too, i.e int64.
+// Parameter names are not allowed to contain any number, that's why
+// it's not handled by the NextToken.
+//
+// It moves the cursor forward.
+func (l *Lexer) NextTypeToken() (t token.Token) {
+	l.skipWhitespace()
+	if !isLetter(l.ch) {
+		return l.NextToken()
+	}
+
+	pos := l.pos
+	for isLetter(l.ch) || isDigit(l.ch) {
+		l.readChar()
+	}
+
+	return l.newToken(token.IDENT, l.input[pos:l.pos])
+}
+
 // NextDynamicToken doesn't cares about the grammar.
 // It reads numbers or any unknown symbol,
 // it's being used by parser to skip all characters
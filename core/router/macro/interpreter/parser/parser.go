@@ -108,6 +108,9 @@ func (p *ParamParser) Parse() (*ast.ParamStatement, error) {
 	}
 
 	lastParamFunc := ast.ParamFunc{}
+	// the type's identifier, the arguments right after it
+	// are the arguments of its function of the same name, i.e {status:enum(active,closed)}.
+	typeIdent := ""
 
 	for {
 		t := l.NextToken()
@@ -125,16 +128,20 @@ func (p *ParamParser) Parse() (*ast.ParamStatement, error) {
 			stmt.Name = nextTok.Literal
 		case token.COLON:
 			// type
-			nextTok := l.NextToken()
+			nextTok := l.NextTypeToken()
 			paramType := ast.LookupParamType(nextTok.Literal)
 			if paramType == ast.ParamTypeUnExpected {
 				p.appendErr("[%d:%d] unexpected parameter type: %s", t.Start, t.End, nextTok.Literal)
 			}
 			stmt.Type = paramType
+			typeIdent = nextTok.Literal
 			// param func
 		case token.IDENT:
 			lastParamFunc.Name = t.Literal
 		case token.LPAREN:
+			if lastParamFunc.Name == "" {
+				lastParamFunc.Name = typeIdent
+			}
 			// param function without arguments ()
 			if l.PeekNextTokenType() == token.RPAREN {
 				// do nothing, just continue to the RPAREN
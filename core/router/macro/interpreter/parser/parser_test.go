@@ -123,6 +123,30 @@ func TestParseParam(t *testing.T) {
 				},
 				ErrorCode: 404,
 			}}, // 7
+		{true,
+			ast.ParamStatement{
+				Src:  "{id:int64 min(1)}", // test param types which contain digits
+				Name: "id",
+				Type: ast.ParamTypeInt64,
+				Funcs: []ast.ParamFunc{
+					{
+						Name: "min",
+						Args: []ast.ParamFuncArg{1}},
+				},
+				ErrorCode: 404,
+			}}, // 8
+		{true,
+			ast.ParamStatement{
+				Src:  "{status:enum(active,closed)}", // test the arguments of the type's func
+				Name: "status",
+				Type: ast.ParamTypeEnum,
+				Funcs: []ast.ParamFunc{
+					{
+						Name: "enum",
+						Args: []ast.ParamFuncArg{"active,closed"}},
+				},
+				ErrorCode: 404,
+			}}, // 9
 
 	}
 
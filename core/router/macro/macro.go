@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"strconv"
+	"time"
 	"unicode"
 
+	"github.com/go-siris/siris/core/errors"
 	"github.com/go-siris/siris/core/router/macro/interpreter/ast"
 )
 
@@ -14,6 +17,12 @@ import (
 // and return true if validated otherwise false.
 type EvaluatorFunc func(paramValue string) bool
 
+// ConverterFunc is the signature for the param types which have a typed value,
+// i.e int64 or time.Time.
+// It should accepts the param's value as string
+// and return its typed value or a not-nil error if the value is not valid.
+type ConverterFunc func(paramValue string) (interface{}, error)
+
 // NewEvaluatorFromRegexp accepts a regexp "expr" expression
 // and returns an EvaluatorFunc based on that regexp.
 // the regexp is compiled before return.
@@ -140,6 +149,10 @@ type (
 	// to that macro which maps to a parameter type.
 	Macro struct {
 		Evaluator EvaluatorFunc
+		// Converter converts the param's value to its typed value, optionally.
+		// The typed value is stored to the request's params, right after a successful evaluation,
+		// so handlers can retrieve it without re-parsing, see `context#RequestParams.GetValue`.
+		Converter ConverterFunc
 		funcs     []ParamFunc
 	}
 
@@ -165,6 +178,18 @@ func newMacro(evaluator EvaluatorFunc) *Macro {
 	return &Macro{Evaluator: evaluator}
 }
 
+// newConverterMacro returns a macro which its evaluator
+// passes when the "converter" succeed.
+func newConverterMacro(converter ConverterFunc) *Macro {
+	return &Macro{
+		Evaluator: func(paramValue string) bool {
+			_, err := converter(paramValue)
+			return err == nil
+		},
+		Converter: converter,
+	}
+}
+
 // RegisterFunc registers a parameter function
 // to that macro.
 // Accepts the func name ("range")
@@ -230,8 +255,34 @@ type Map struct {
 	// path type
 	// anything, should be the last part
 	Path *Macro
+	// uuid type
+	// the canonical form of an uuid (8-4-4-4-12 hex digits)
+	UUID *Macro
+	// int64 type
+	// numbers (0-9) with an optional minus sign, which fit in an int64
+	Int64 *Macro
+	// uint64 type
+	// only numbers (0-9), which fit in an uint64
+	Uint64 *Macro
+	// bool type
+	// 1, t, T, TRUE, true, True, 0, f, F, FALSE, false, False
+	Bool *Macro
+	// date type
+	// year-month-day, i.e 2017-07-27
+	Date *Macro
+	// enum type
+	// the values of its enum function, i.e {status:enum(active,closed)}
+	Enum *Macro
+
+	// the user-defined types, see `Register`.
+	custom map[ast.ParamType]*Macro
 }
 
+var errInvalidParamValue = errors.New("value '%s' is not a valid '%s'")
+
+// DateLayout is the layout of the date parameter type, see `Map#Date`.
+const DateLayout = "2006-01-02"
+
 // NewMap returns a new macro Map with default
 // type evaluators.
 //
@@ -248,13 +299,95 @@ func NewMap() *Map {
 		// to organise the macro functions based on wildcard or single dynamic named path parameter.
 		// Should be the last.
 		Path: newMacro(func(string) bool { return true }),
+		UUID: newMacro(MustNewEvaluatorFromRegexp("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$")),
+		Int64: newConverterMacro(func(paramValue string) (interface{}, error) {
+			return strconv.ParseInt(paramValue, 10, 64)
+		}),
+		Uint64: newConverterMacro(func(paramValue string) (interface{}, error) {
+			return strconv.ParseUint(paramValue, 10, 64)
+		}),
+		Bool: newConverterMacro(func(paramValue string) (interface{}, error) {
+			return strconv.ParseBool(paramValue)
+		}),
+		Date: newConverterMacro(func(paramValue string) (interface{}, error) {
+			return time.Parse(DateLayout, paramValue)
+		}),
+		// the values are checked by its "enum" func.
+		Enum:   newMacro(func(string) bool { return true }),
+		custom: make(map[ast.ParamType]*Macro),
+	}
+}
+
+// Register registers a new parameter type,
+// "ident" is the type's name on the route's path, i.e "hex" for /mypath/{myparam:hex},
+// it should start with a letter and contain only letters, digits and underscores.
+// The "evaluator" validates the param's value and the "converter", optionally,
+// converts it to its typed value.
+// If "evaluator" is nil then the "converter" is being used to validate the value,
+// if both are given then the value should pass the "evaluator" before its conversion.
+//
+// Returns the new type's Macro, custom param funcs can be registered
+// through its `RegisterFunc`.
+//
+// The type is registered on this Map only, the paths of another Map,
+// i.e of another Application, which use it fail to be parsed.
+//
+// Usage:
+// app.Macros().Register("hex", macro.MustNewEvaluatorFromRegexp("^[0-9a-f]+$"), nil)
+// app.Get("/colors/{color:hex}", colorHandler)
+func (m *Map) Register(ident string, evaluator EvaluatorFunc, converter ConverterFunc) *Macro {
+	var mac *Macro
+	switch {
+	case converter == nil:
+		mac = newMacro(evaluator)
+	case evaluator == nil:
+		mac = newConverterMacro(converter)
+	default:
+		// the converter is the one which is being used at serve time,
+		// make sure that the evaluator runs first.
+		mac = newMacro(evaluator)
+		mac.Converter = func(paramValue string) (interface{}, error) {
+			if !evaluator(paramValue) {
+				return nil, errInvalidParamValue.Format(paramValue, ident)
+			}
+			return converter(paramValue)
+		}
+	}
+
+	if m.custom == nil {
+		m.custom = make(map[ast.ParamType]*Macro)
+	}
+
+	m.custom[ast.RegisterParamType(ident)] = mac
+	return mac
+}
+
+// RegisterEnum registers a new parameter type, like `Register`,
+// which allows only the "values", i.e
+// app.Macros().RegisterEnum("status", "active", "closed")
+// app.Get("/orders/{status:status}", ordersHandler)
+//
+// The inline alternative is the enum type: {status:enum(active,closed)}.
+func (m *Map) RegisterEnum(ident string, values ...string) *Macro {
+	return m.Register(ident, NewEnumEvaluator(values...), nil)
+}
+
+// NewEnumEvaluator returns an evaluator which allows only the "values".
+func NewEnumEvaluator(values ...string) EvaluatorFunc {
+	allowed := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		allowed[v] = struct{}{}
+	}
+	return func(paramValue string) bool {
+		_, ok := allowed[paramValue]
+		return ok
 	}
 }
 
 // Lookup returns the specific Macro from the map
 // based on the parameter type.
 // i.e if ast.ParamTypeInt then it will return the m.Int.
-// Returns the m.String if not matched.
+// Returns the m.String if not matched, neither as a registered custom type.
 func (m *Map) Lookup(typ ast.ParamType) *Macro {
 	switch typ {
 	case ast.ParamTypeInt:
@@ -265,7 +398,22 @@ func (m *Map) Lookup(typ ast.ParamType) *Macro {
 		return m.File
 	case ast.ParamTypePath:
 		return m.Path
+	case ast.ParamTypeUUID:
+		return m.UUID
+	case ast.ParamTypeInt64:
+		return m.Int64
+	case ast.ParamTypeUint64:
+		return m.Uint64
+	case ast.ParamTypeBool:
+		return m.Bool
+	case ast.ParamTypeDate:
+		return m.Date
+	case ast.ParamTypeEnum:
+		return m.Enum
 	default:
+		if mac, ok := m.custom[typ]; ok {
+			return mac
+		}
 		return m.String
 	}
 }
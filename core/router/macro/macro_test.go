@@ -2,7 +2,10 @@ package macro
 
 import (
 	"reflect"
+	"strconv"
 	"testing"
+
+	"github.com/go-siris/siris/core/router/macro/interpreter/ast"
 )
 
 // Most important tests to look:
@@ -167,6 +170,75 @@ func TestPathEvaluatorRaw(t *testing.T) {
 	}
 }
 
+func TestTypedEvaluatorRaw(t *testing.T) {
+	f := NewMap()
+
+	tests := []struct {
+		macro *Macro
+		pass  bool
+		input string
+	}{
+		{f.UUID, true, "6ba7b810-9dad-11d1-80b4-00c04fd430c8"}, // 0
+		{f.UUID, false, "6ba7b810-9dad-11d1-80b4"},             // 1
+		{f.Int64, true, "-9223372036854775808"},                // 2
+		{f.Int64, false, "9223372036854775808"},                // 3
+		{f.Uint64, true, "18446744073709551615"},               // 4
+		{f.Uint64, false, "-1"},                                // 5
+		{f.Bool, true, "true"},                                 // 6
+		{f.Bool, false, "yes"},                                 // 7
+		{f.Date, true, "2017-07-27"},                           // 8
+		{f.Date, false, "2017-13-27"},                          // 9
+	}
+
+	for i, tt := range tests {
+		testEvaluatorRaw(tt.macro, tt.input, tt.pass, i, t)
+	}
+}
+
+func TestMapRegister(t *testing.T) {
+	m := NewMap()
+	hex := m.Register("hex", MustNewEvaluatorFromRegexp("^[0-9a-f]+$"), nil)
+
+	if got := m.Lookup(ast.LookupParamType("hex")); got != hex {
+		t.Fatalf("expected the registered macro to be found by its type")
+	}
+
+	tmpl, err := Parse("/colors/{color:hex}", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !tmpl.Params[0].TypeEvaluator("ff00aa") {
+		t.Fatalf("expected 'ff00aa' to be a valid hex")
+	}
+	if tmpl.Params[0].TypeEvaluator("zz") {
+		t.Fatalf("expected 'zz' to be an invalid hex")
+	}
+
+	m.Register("even", func(paramValue string) bool {
+		return len(paramValue) > 0 && (paramValue[len(paramValue)-1]-'0')%2 == 0
+	}, func(paramValue string) (interface{}, error) {
+		return strconv.Atoi(paramValue)
+	})
+
+	tmpl, err = Parse("/numbers/{n:even}", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := tmpl.Params[0].TypeConverter("42"); err != nil || v != 42 {
+		t.Fatalf("expected '42' to be converted to 42 but got %v (%v)", v, err)
+	}
+	if _, err := tmpl.Params[0].TypeConverter("43"); err == nil {
+		t.Fatalf("expected '43' to fail the evaluator before its conversion")
+	}
+
+	// the types are registered per map.
+	if _, err = Parse("/colors/{color:hex}", NewMap()); err == nil {
+		t.Fatalf("expected an error for a type which is registered by another map")
+	}
+}
+
 // func TestMapRegisterFunc(t *testing.T) {
 // 	m := NewMap()
 // 	m.String.RegisterFunc("prefix", func(prefix string) EvaluatorFunc {
@@ -1,6 +1,8 @@
 package macro
 
 import (
+	"fmt"
+
 	"github.com/go-siris/siris/core/router/macro/interpreter/ast"
 	"github.com/go-siris/siris/core/router/macro/interpreter/parser"
 )
@@ -27,6 +29,9 @@ type TemplateParam struct {
 	Name          string
 	ErrCode       int
 	TypeEvaluator EvaluatorFunc
+	// TypeConverter is the param type's converter, may be nil,
+	// see `Macro#Converter`.
+	TypeConverter ConverterFunc
 	Funcs         []EvaluatorFunc
 }
 
@@ -43,6 +48,11 @@ func Parse(src string, macros *Map) (*Template, error) {
 	t.Src = src
 
 	for _, p := range params {
+		// the custom types are registered per map, i.e per application.
+		if _, ok := macros.custom[p.Type]; p.Type.IsCustom() && !ok {
+			return nil, fmt.Errorf("parameter type of '%s' is not registered on this macro map", p.Src)
+		}
+
 		funcMap := macros.Lookup(p.Type)
 		typEval := funcMap.Evaluator
 
@@ -52,6 +62,7 @@ func Parse(src string, macros *Map) (*Template, error) {
 			Name:          p.Name,
 			ErrCode:       p.ErrorCode,
 			TypeEvaluator: typEval,
+			TypeConverter: funcMap.Converter,
 		}
 		for _, paramfn := range p.Funcs {
 			tmplFn := funcMap.getFunc(paramfn.Name)
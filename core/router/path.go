@@ -1,11 +1,13 @@
 package router
 
 import (
+	"fmt"
 	"net/http"
 	"path"
 	"strconv"
 	"strings"
 
+	"github.com/go-siris/siris/core/errors"
 	"github.com/go-siris/siris/core/nettools"
 )
 
@@ -269,3 +271,114 @@ func (ps *RoutePathReverser) URL(routeName string, paramValues ...interface{}) (
 
 	return
 }
+
+// SubdomainParamName is the `URLParams` key which its value
+// fills the wildcard subdomain of a route, see `RoutePathReverser#NamedURL`.
+const SubdomainParamName = "subdomain"
+
+// URLParams contains the named parameters' values
+// that are being used to reverse a route's path or url.
+// Values of keys that are not parameters of the route
+// are appended as query string.
+//
+// Values can be a string, a []string, a fmt.Stringer or any other value
+// which is converted to string with the `fmt` package.
+//
+// See `RoutePathReverser#NamedPath` and `RoutePathReverser#NamedURL`.
+type URLParams map[string]interface{}
+
+var (
+	errRouteNotFound       = errors.New("route with name '%s' couldn't be found")
+	errURLParamMissing     = errors.New("route '%s': missing value for the '%s' parameter")
+	errURLParamInvalid     = errors.New("route '%s': value '%s' is not valid for the '%s' parameter")
+	errURLHostNotFound     = errors.New("route '%s': subdomain routes can't be reversed without a host")
+	errURLSubdomainMissing = errors.New("route '%s': missing value for the wildcard subdomain")
+)
+
+func toURLValues(v interface{}) []string {
+	switch value := v.(type) {
+	case string:
+		return []string{value}
+	case []string:
+		return value
+	case fmt.Stringer:
+		return []string{value.String()}
+	default:
+		return []string{fmt.Sprint(value)}
+	}
+}
+
+// NamedPath returns a route's path based on a route name and its named parameter's values,
+// the rest of the "params" are appended as query string.
+//
+// Returns a not-nil error if the route couldn't be found or
+// a parameter's value is missing or it's not valid for its macro type and functions.
+func (ps *RoutePathReverser) NamedPath(routeName string, params URLParams) (string, error) {
+	r := ps.provider.GetRoute(routeName)
+	if r == nil {
+		return "", errRouteNotFound.Format(routeName)
+	}
+
+	if r.Subdomain == SubdomainWildcardIndicator {
+		params = params.without(SubdomainParamName)
+	}
+
+	return r.ResolveNamedPath(params)
+}
+
+// NamedURL same as NamedPath but it returns the full uri for subdomain routes,
+// i.e https://mysubdomain.mydomain.com/hello/siris?page=2.
+//
+// Wildcard subdomain's value is being taken from the `SubdomainParamName` key of the "params".
+// Routes without subdomain are being reversed to their path, like NamedPath.
+func (ps *RoutePathReverser) NamedURL(routeName string, params URLParams) (string, error) {
+	r := ps.provider.GetRoute(routeName)
+	if r == nil {
+		return "", errRouteNotFound.Format(routeName)
+	}
+
+	if r.Subdomain == "" {
+		return r.ResolveNamedPath(params)
+	}
+
+	if ps.vhost == "" {
+		return "", errURLHostNotFound.Format(routeName)
+	}
+
+	host := r.Subdomain + ps.vhost // the subdomain contains the dot.
+	if r.Subdomain == SubdomainWildcardIndicator {
+		subdomain, ok := params[SubdomainParamName]
+		if !ok {
+			return "", errURLSubdomainMissing.Format(routeName)
+		}
+		host = strings.Join(toURLValues(subdomain), ".") + "." + ps.vhost
+		params = params.without(SubdomainParamName)
+	}
+
+	parsedPath, err := r.ResolveNamedPath(params)
+	if err != nil {
+		return "", err
+	}
+
+	scheme := ps.vscheme
+	if scheme == "" {
+		scheme = nettools.ResolveSchemeFromVHost(ps.vhost)
+	}
+
+	return scheme + "://" + host + parsedPath, nil
+}
+
+// without returns a copy of the "params" without the "key".
+func (params URLParams) without(key string) URLParams {
+	if _, ok := params[key]; !ok {
+		return params
+	}
+
+	cp := make(URLParams, len(params)-1)
+	for k, v := range params {
+		if k != key {
+			cp[k] = v
+		}
+	}
+	return cp
+}
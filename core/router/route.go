@@ -2,6 +2,7 @@ package router
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/go-siris/siris/context"
@@ -29,6 +30,43 @@ type Route struct {
 	// FormattedPath all dynamic named parameters (if any) replaced with %v,
 	// used by Application to validate param values of a Route based on its name.
 	FormattedPath string
+	// MainHandlerName is the name of the last handler that was passed on registration,
+	// i.e "main.userHandler", the rest of the Handlers are its middleware.
+	MainHandlerName string
+	// mainHandlerIndex is the index of the main handler inside the Handlers,
+	// -1 if unknown, the begin handlers are added to it on build.
+	mainHandlerIndex int
+	// Doc is the optional documentation of the route,
+	// used by generators like the openapi package.
+	Doc RouteDoc
+}
+
+// RouteDoc contains the optional documentation of a Route,
+// i.e its summary and the Go types of its request and response bodies.
+type RouteDoc struct {
+	Summary     string
+	Description string
+	Tags        []string
+	// Request is a value of the request body's type, can be nil.
+	Request interface{}
+	// Responses are values of the response bodies' types per status code,
+	// a nil value describes a response without body.
+	Responses map[int]interface{}
+}
+
+// RouteOption sets an optional field of a Route,
+// see `Route#With`.
+type RouteOption func(*Route)
+
+// With applies the "options" to the route and returns the route itself.
+//
+// Usage:
+// app.Post("/users", createUser).With(openapi.Request(User{}), openapi.Returns(201, User{}))
+func (r *Route) With(options ...RouteOption) *Route {
+	for _, opt := range options {
+		opt(r)
+	}
+	return r
 }
 
 // NewRoute returns a new route based on its method,
@@ -62,9 +100,22 @@ func NewRoute(method, subdomain, unparsedPath string,
 		Handlers:      handlers,
 		FormattedPath: formattedPath,
 	}
+	route.setMainHandler(len(handlers) - 1)
 	return route, nil
 }
 
+// setMainHandler marks the Handlers[index] as the main handler of the route,
+// the rest of the handlers are its middleware.
+func (r *Route) setMainHandler(index int) {
+	if index < 0 || index >= len(r.Handlers) {
+		r.mainHandlerIndex = -1
+		r.MainHandlerName = ""
+		return
+	}
+	r.mainHandlerIndex = index
+	r.MainHandlerName = context.HandlerName(r.Handlers[index])
+}
+
 // use adds explicit begin handlers(middleware) to this route,
 // It's being called internally, it's useless for outsiders
 // because `Handlers` field is exported.
@@ -97,6 +148,9 @@ func (r *Route) done(handlers context.Handlers) {
 func (r *Route) BuildHandlers() {
 	if len(r.beginHandlers) > 0 {
 		r.Handlers = append(r.beginHandlers, r.Handlers...)
+		if r.mainHandlerIndex >= 0 {
+			r.mainHandlerIndex += len(r.beginHandlers)
+		}
 		r.beginHandlers = r.beginHandlers[0:0]
 	}
 
@@ -184,3 +238,74 @@ func (r Route) ResolvePath(args ...string) string {
 	}
 	return formattedPath
 }
+
+// ResolveNamedPath returns the route's path with its dynamic named parameters
+// replaced with the "params" values, the rest of the "params" are appended as query string.
+//
+// Each parameter value is validated against the parameter's macro type and functions,
+// i.e a value of "{id:int min(1)}" should be a number greater or equal than 1.
+func (r Route) ResolveNamedPath(params map[string]interface{}) (string, error) {
+	used := make(map[string]bool, len(r.tmpl.Params))
+
+	parts := strings.Split(r.Path, "/")
+	for i, part := range parts {
+		if len(part) == 0 || (part[0] != ParamStart[0] && part[0] != WildcardParamStart[0]) {
+			continue
+		}
+
+		name := part[1:]
+		v, ok := params[name]
+		if !ok {
+			return "", errURLParamMissing.Format(r.Name, name)
+		}
+		used[name] = true
+
+		values := toURLValues(v)
+		paramValue := strings.Join(values, "/")
+
+		for _, p := range r.tmpl.Params {
+			if p.Name != name {
+				continue
+			}
+			if p.TypeEvaluator != nil && !p.TypeEvaluator(paramValue) {
+				return "", errURLParamInvalid.Format(r.Name, paramValue, p.Src)
+			}
+			for _, evalFn := range p.Funcs {
+				if !evalFn(paramValue) {
+					return "", errURLParamInvalid.Format(r.Name, paramValue, p.Src)
+				}
+			}
+		}
+
+		if part[0] == WildcardParamStart[0] {
+			// wildcard accepts more than one path segment,
+			// escape each one of them but keep the slashes.
+			var segments []string
+			for _, value := range values {
+				for _, segment := range strings.Split(value, "/") {
+					segments = append(segments, url.PathEscape(segment))
+				}
+			}
+			parts[i] = strings.Join(segments, "/")
+			continue
+		}
+
+		parts[i] = url.PathEscape(paramValue)
+	}
+
+	path := strings.Join(parts, "/")
+
+	query := url.Values{}
+	for name, v := range params {
+		if used[name] {
+			continue
+		}
+		query[name] = toURLValues(v)
+	}
+
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	return path, nil
+}
@@ -0,0 +1,133 @@
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-siris/siris/context"
+)
+
+// RouteInfo contains the read-only information of a registered Route,
+// it's the entry of the `RouteTable`.
+type RouteInfo struct {
+	Name      string `json:"name"`
+	Method    string `json:"method"`
+	Subdomain string `json:"subdomain,omitempty"`
+	// Path is the registered path template, i.e "/api/user/{id:int}".
+	Path string `json:"path"`
+	// Handler is the main handler's name, i.e "main.userHandler".
+	Handler string `json:"handler"`
+	// Middleware are the names of the route's handlers, by order of execution,
+	// except the main handler.
+	Middleware []string `json:"middleware"`
+}
+
+// Info returns the read-only information of the route.
+func (r Route) Info() RouteInfo {
+	info := RouteInfo{
+		Name:       r.Name,
+		Method:     r.Method,
+		Subdomain:  r.Subdomain,
+		Path:       r.Tmpl().Src,
+		Handler:    r.MainHandlerName,
+		Middleware: []string{},
+	}
+
+	// begin and done handlers are not part of the Handlers before the build state.
+	handlers := append(append(append(context.Handlers{}, r.beginHandlers...), r.Handlers...), r.doneHandlers...)
+	mainIndex := -1
+	if r.mainHandlerIndex >= 0 {
+		mainIndex = len(r.beginHandlers) + r.mainHandlerIndex
+	}
+	for i, h := range handlers {
+		if i == mainIndex {
+			continue
+		}
+		info.Middleware = append(info.Middleware, context.HandlerName(h))
+	}
+
+	return info
+}
+
+// RouteTable is the list of the registered routes' information,
+// sorted by subdomain, path and method.
+type RouteTable []RouteInfo
+
+// NewRouteTable returns the route table of the "routes".
+func NewRouteTable(routes []*Route) RouteTable {
+	t := make(RouteTable, 0, len(routes))
+	for _, r := range routes {
+		t = append(t, r.Info())
+	}
+
+	sort.SliceStable(t, func(i, j int) bool {
+		if t[i].Subdomain != t[j].Subdomain {
+			return t[i].Subdomain < t[j].Subdomain
+		}
+		if t[i].Path != t[j].Path {
+			return t[i].Path < t[j].Path
+		}
+		return t[i].Method < t[j].Method
+	})
+
+	return t
+}
+
+// String returns the route table as aligned text,
+// one route per line, it's printed at startup next to the banner.
+func (t RouteTable) String() string {
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+	for _, r := range t {
+		fmt.Fprintf(w, "%s\t%s%s\t%s", r.Method, r.Subdomain, r.Path, r.Handler)
+		if len(r.Middleware) > 0 {
+			fmt.Fprintf(w, "\t(%s)", strings.Join(r.Middleware, ", "))
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// HTML returns the route table as an html document.
+func (t RouteTable) HTML() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("<!DOCTYPE html>\n<html>\n<head><title>Routes</title></head>\n<body>\n<table>\n")
+	buf.WriteString("<tr><th>Method</th><th>Subdomain</th><th>Path</th><th>Name</th><th>Handler</th><th>Middleware</th></tr>\n")
+	for _, r := range t {
+		fmt.Fprintf(buf, "<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+			html.EscapeString(r.Method),
+			html.EscapeString(r.Subdomain),
+			html.EscapeString(r.Path),
+			html.EscapeString(r.Name),
+			html.EscapeString(r.Handler),
+			html.EscapeString(strings.Join(r.Middleware, ", ")))
+	}
+	buf.WriteString("</table>\n</body>\n</html>\n")
+
+	return buf.String()
+}
+
+// RouteTableHandler returns a debug handler which renders the route table of the "provider",
+// as JSON by default or as HTML if the client accepts "text/html" or the "format" url parameter is "html".
+//
+// Usage:
+// app.Get("/debug/routes", router.RouteTableHandler(app))
+func RouteTableHandler(provider RoutesProvider) context.Handler {
+	return func(ctx context.Context) {
+		t := NewRouteTable(provider.GetRoutes())
+
+		format := ctx.URLParam("format")
+		if format == "html" || (format == "" && strings.Contains(ctx.GetHeader("Accept"), "text/html")) {
+			ctx.HTML(t.HTML())
+			return
+		}
+
+		ctx.JSON(t)
+	}
+}
@@ -0,0 +1,84 @@
+// black-box testing
+package router_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-siris/siris"
+	"github.com/go-siris/siris/context"
+	"github.com/go-siris/siris/core/router"
+
+	"github.com/go-siris/siris/httptest"
+)
+
+func routeTableMiddleware(ctx context.Context) { ctx.Next() }
+
+func routeTableUsersHandler(ctx context.Context) {}
+
+func TestRouteTable(t *testing.T) {
+	app := siris.New()
+
+	users := app.Party("/users", routeTableMiddleware)
+	users.Get("/{id:int}", routeTableUsersHandler)
+	app.Post("/users", routeTableUsersHandler)
+	app.Get("/debug/routes", router.RouteTableHandler(app))
+
+	table := app.RouteTable()
+	if expected, got := 3, len(table); expected != got {
+		t.Fatalf("expected %d routes but got %d", expected, got)
+	}
+
+	// sorted by path.
+	r := table[2]
+	if r.Method != "GET" || r.Path != "/users/{id:int}" {
+		t.Fatalf("unexpected route: %#v", r)
+	}
+	if !strings.HasSuffix(r.Handler, "routeTableUsersHandler") {
+		t.Fatalf("unexpected main handler name: %s", r.Handler)
+	}
+
+	var middleware []string
+	for _, name := range r.Middleware {
+		if strings.HasSuffix(name, "routeTableMiddleware") {
+			middleware = append(middleware, name)
+		}
+	}
+	if len(middleware) != 1 {
+		t.Fatalf("expected the party's middleware to be part of the route's middleware but got: %v", r.Middleware)
+	}
+
+	if s := table.String(); !strings.Contains(s, "/users/{id:int}") || strings.Count(s, "\n") != 2 {
+		t.Fatalf("unexpected route table text:\n%s", s)
+	}
+
+	e := httptest.New(t, app)
+
+	e.GET("/debug/routes").Expect().Status(siris.StatusOK).
+		JSON().Array().Length().Equal(3)
+	e.GET("/debug/routes").WithQuery("format", "html").Expect().Status(siris.StatusOK).
+		ContentType("text/html").Body().Contains("/users/{id:int}")
+}
+
+func TestRouteTableSharedMainHandler(t *testing.T) {
+	app := siris.New()
+	// the main handler of the route is used as a global middleware too.
+	app.UseGlobal(routeTableMiddleware)
+	app.Get("/shared", routeTableUsersHandler, routeTableMiddleware)
+
+	expectInfo := func(state string) {
+		r := app.RouteTable()[0]
+		if !strings.HasSuffix(r.Handler, "routeTableMiddleware") {
+			t.Fatalf("[%s] unexpected main handler name: %s", state, r.Handler)
+		}
+		if len(r.Middleware) != 2 ||
+			!strings.HasSuffix(r.Middleware[0], "routeTableMiddleware") ||
+			!strings.HasSuffix(r.Middleware[1], "routeTableUsersHandler") {
+			t.Fatalf("[%s] unexpected middleware: %v", state, r.Middleware)
+		}
+	}
+
+	expectInfo("before build")
+	app.Build()
+	expectInfo("after build")
+}
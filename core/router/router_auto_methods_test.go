@@ -0,0 +1,99 @@
+// black-box testing
+package router_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-siris/siris"
+	"github.com/go-siris/siris/cache"
+	"github.com/go-siris/siris/context"
+
+	"github.com/go-siris/siris/httptest"
+)
+
+func registerAutoMethodsRoutes(app *siris.Application) {
+	app.Get("/users", func(ctx context.Context) {
+		ctx.Header("X-Users", "all")
+		ctx.WriteString("users")
+	})
+	app.Post("/users", func(ctx context.Context) {
+		ctx.StatusCode(siris.StatusCreated)
+	})
+	app.Delete("/users", func(ctx context.Context) {})
+	app.Put("/only-put", func(ctx context.Context) {})
+	app.Get("/large", func(ctx context.Context) {
+		chunk := make([]byte, 1024)
+		for i := 0; i < 64; i++ {
+			ctx.Write(chunk)
+		}
+	})
+}
+
+func TestAutoHeadAndOptions(t *testing.T) {
+	app := siris.New()
+	app.Configure(siris.WithFireMethodNotAllowed)
+	registerAutoMethodsRoutes(app)
+
+	e := httptest.New(t, app)
+
+	e.HEAD("/users").Expect().Status(siris.StatusOK).
+		Header("X-Users").Equal("all")
+	e.HEAD("/users").Expect().Body().Empty()
+	e.HEAD("/large").Expect().Status(siris.StatusOK).
+		Header("Content-Length").Equal("65536")
+	e.HEAD("/large").Expect().Body().Empty()
+	e.HEAD("/only-put").Expect().Status(siris.StatusMethodNotAllowed).
+		Header("Allow").Equal("OPTIONS, PUT")
+
+	e.OPTIONS("/users").Expect().Status(siris.StatusOK).
+		Header("Allow").Equal("DELETE, GET, HEAD, OPTIONS, POST")
+	e.OPTIONS("/notfound").Expect().Status(siris.StatusNotFound)
+
+	e.PATCH("/users").Expect().Status(siris.StatusMethodNotAllowed).
+		Header("Allow").Equal("DELETE, GET, HEAD, OPTIONS, POST")
+}
+
+func TestAutoHeadRecordedAndCompressed(t *testing.T) {
+	text := strings.Repeat("siris serves HEAD through GET. ", 64)
+
+	app := siris.New()
+	app.Get("/cached", cache.WrapHandler(func(ctx context.Context) {
+		ctx.WriteString(text)
+	}, time.Minute))
+	app.Get("/gzip", func(ctx context.Context) {
+		ctx.Gzip(true)
+		ctx.WriteString(text)
+	})
+
+	e := httptest.New(t, app)
+
+	for i := 0; i < 2; i++ { // recorded, then served from the cache.
+		resp := e.HEAD("/cached").Expect().Status(siris.StatusOK)
+		resp.Header("Content-Length").Equal(strconv.Itoa(len(text)))
+		resp.Body().Empty()
+	}
+	e.GET("/cached").Expect().Status(siris.StatusOK).Body().Equal(text)
+
+	compressed := e.GET("/gzip").WithHeader("Accept-Encoding", "gzip").Expect().
+		Status(siris.StatusOK).Body().Raw()
+	resp := e.HEAD("/gzip").WithHeader("Accept-Encoding", "gzip").Expect().Status(siris.StatusOK)
+	resp.Header("Content-Encoding").Equal("gzip")
+	resp.Header("Content-Length").Equal(strconv.Itoa(len(compressed)))
+	resp.Body().Empty()
+}
+
+func TestAutoHeadAndOptionsDisabled(t *testing.T) {
+	app := siris.New()
+	app.Configure(siris.WithFireMethodNotAllowed, siris.WithoutAutoHead, siris.WithoutAutoOptions)
+	registerAutoMethodsRoutes(app)
+
+	e := httptest.New(t, app)
+
+	e.HEAD("/users").Expect().Status(siris.StatusMethodNotAllowed).
+		Header("Allow").Equal("DELETE, GET, POST")
+	e.OPTIONS("/users").Expect().Status(siris.StatusMethodNotAllowed).
+		Header("Allow").Equal("DELETE, GET, POST")
+}
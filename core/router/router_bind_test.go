@@ -0,0 +1,95 @@
+// black-box testing
+package router_test
+
+import (
+	"mime/multipart"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-siris/siris"
+	"github.com/go-siris/siris/context"
+	"github.com/go-siris/siris/validator"
+
+	"github.com/go-siris/siris/httptest"
+)
+
+type bindTestRequest struct {
+	ID      int64                   `param:"id"`
+	Page    int                     `query:"page"`
+	Tags    []string                `query:"tag"`
+	Timeout time.Duration           `query:"timeout"`
+	Tenant  *string                 `header:"X-Tenant"`
+	SID     string                  `cookie:"sid"`
+	Name    string                  `json:"name" xml:"name" form:"name" validate:"required"`
+	Avatar  *multipart.FileHeader   `form:"avatar"`
+	Photos  []*multipart.FileHeader `form:"photo"`
+}
+
+func TestBind(t *testing.T) {
+	app := siris.New()
+	app.AttachValidator(validator.New())
+	app.Post("/users/{id:int64}", func(ctx context.Context) {
+		var req bindTestRequest
+		if err := ctx.Bind(&req); err != nil {
+			if err == context.ErrUnsupportedMediaType {
+				ctx.StatusCode(siris.StatusUnsupportedMediaType)
+			} else {
+				ctx.StatusCode(siris.StatusBadRequest)
+			}
+			ctx.WriteString(err.Error())
+			return
+		}
+
+		tenant := "<nil>"
+		if req.Tenant != nil {
+			tenant = *req.Tenant
+		}
+		files := ""
+		if req.Avatar != nil {
+			files = req.Avatar.Filename
+		}
+		for _, f := range req.Photos {
+			files += "," + f.Filename
+		}
+		ctx.Writef("%d %d %s %s %s %s %s %s", req.ID, req.Page, strings.Join(req.Tags, "+"), req.Timeout, tenant, req.SID, req.Name, files)
+	})
+
+	e := httptest.New(t, app)
+
+	e.POST("/users/42").WithQuery("page", 2).WithQuery("tag", "a").WithQuery("tag", "b").WithQuery("timeout", "1m").
+		WithHeader("X-Tenant", "acme").WithCookie("sid", "s3cr3t").
+		WithJSON(map[string]interface{}{"name": "siris", "id": 1}).
+		Expect().Status(siris.StatusOK).Body().Equal("42 2 a+b 1m0s acme s3cr3t siris ")
+
+	e.POST("/users/42").WithHeader("Content-Type", "application/xml").
+		WithBytes([]byte("<bindTestRequest><name>siris</name></bindTestRequest>")).
+		Expect().Status(siris.StatusOK).Body().Equal("42 0  0s <nil>  siris ")
+
+	e.POST("/users/42").WithFormField("name", "siris").WithFormField("unknown", "ignored").
+		Expect().Status(siris.StatusOK).Body().Equal("42 0  0s <nil>  siris ")
+
+	e.POST("/users/42").WithMultipart().WithFormField("name", "siris").
+		WithFileBytes("avatar", "avatar.png", []byte("png")).
+		WithFileBytes("photo", "1.jpg", []byte("jpg")).WithFileBytes("photo", "2.jpg", []byte("jpg")).
+		Expect().Status(siris.StatusOK).Body().Equal("42 0  0s <nil>  siris avatar.png,1.jpg,2.jpg")
+
+	body := e.POST("/users/42").WithQuery("page", "two").WithQuery("timeout", "1 minute").
+		WithJSON(map[string]string{"name": "siris"}).
+		Expect().Status(siris.StatusBadRequest).Body()
+	body.Contains(`bind: query "page" of the field Page: cannot convert "two": invalid syntax; `)
+	body.Contains(`bind: query "timeout" of the field Timeout: cannot convert "1 minute": time: unknown unit`)
+
+	// the tagged fields are not set from the body.
+	e.POST("/users/42").WithJSON(map[string]interface{}{"name": "siris", "page": 9, "tenant": "evil", "sid": "evil"}).
+		Expect().Status(siris.StatusOK).Body().Equal("42 0  0s <nil>  siris ")
+	e.POST("/users/42").WithFormField("name", "siris").WithFormField("SID", "evil").WithFormField("Page", "9").
+		Expect().Status(siris.StatusOK).Body().Equal("42 0  0s <nil>  siris ")
+
+	e.POST("/users/42").WithHeader("Content-Type", "text/csv").WithBytes([]byte("name\nsiris")).
+		Expect().Status(siris.StatusUnsupportedMediaType)
+
+	// validated after binding.
+	e.POST("/users/42").WithJSON(map[string]string{}).
+		Expect().Status(siris.StatusBadRequest).Body().Equal("name is required")
+}
@@ -0,0 +1,188 @@
+// black-box testing
+package router_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/go-siris/siris"
+	"github.com/go-siris/siris/context"
+	"github.com/go-siris/siris/core/router"
+
+	"github.com/go-siris/siris/httptest"
+)
+
+func decompress(t *testing.T, encoding string, body string) string {
+	var (
+		r   io.Reader
+		err error
+	)
+	switch encoding {
+	case "br":
+		r = brotli.NewReader(strings.NewReader(body))
+	case "gzip":
+		r, err = gzip.NewReader(strings.NewReader(body))
+	case "deflate":
+		r, err = zlib.NewReader(strings.NewReader(body))
+	default:
+		return body
+	}
+	if err != nil {
+		t.Fatalf("%s: %v", encoding, err)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("%s: %v", encoding, err)
+	}
+	return string(b)
+}
+
+func TestCompress(t *testing.T) {
+	text := strings.Repeat("siris compresses the responses. ", 64)
+
+	app := siris.New()
+	app.Get("/", func(ctx context.Context) {
+		ctx.Compress(true)
+		ctx.ContentType("text/plain")
+		ctx.WriteString(text)
+	})
+	app.Get("/small", func(ctx context.Context) {
+		ctx.Compress(true)
+		ctx.WriteString("siris")
+	})
+	app.Get("/gzip/small", func(ctx context.Context) {
+		ctx.Gzip(true)
+		ctx.WriteString("siris")
+	})
+	app.Get("/image", func(ctx context.Context) {
+		ctx.Compress(true)
+		ctx.ContentType("image/png")
+		ctx.WriteString(text)
+	})
+	app.Get("/negotiate", func(ctx context.Context) {
+		ctx.WriteString(ctx.NegotiateEncoding("br", "gzip", "deflate"))
+	})
+
+	e := httptest.New(t, app)
+
+	tests := []struct {
+		acceptEncoding string
+		encoding       string
+	}{
+		{"gzip, deflate, br", "br"},
+		{"gzip;q=0.5, br;q=0.4", "gzip"},
+		{"gzip;q=0, deflate", "deflate"},
+		{"br;q=0, *;q=0.1", "gzip"},
+		{"x-gzip", "gzip"},
+		{"identity", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		req := e.GET("/")
+		if tt.acceptEncoding != "" {
+			req = req.WithHeader("Accept-Encoding", tt.acceptEncoding)
+		}
+		resp := req.Expect().Status(siris.StatusOK)
+		resp.Header("Content-Encoding").Equal(tt.encoding)
+		resp.Header("Vary").Equal("Accept-Encoding")
+		resp.ContentType("text/plain", "UTF-8")
+		if got := decompress(t, tt.encoding, resp.Body().Raw()); got != text {
+			t.Fatalf("[%s] unexpected body: %q", tt.acceptEncoding, got)
+		}
+
+		e.GET("/negotiate").WithHeader("Accept-Encoding", tt.acceptEncoding).Expect().Body().Equal(tt.encoding)
+	}
+
+	e.GET("/small").WithHeader("Accept-Encoding", "br").Expect().
+		Status(siris.StatusOK).Header("Content-Encoding").Empty()
+	// the minimum size is applied by the Compress only.
+	resp := e.GET("/gzip/small").WithHeader("Accept-Encoding", "gzip").Expect().Status(siris.StatusOK)
+	resp.Header("Content-Encoding").Equal("gzip")
+	if got := decompress(t, "gzip", resp.Body().Raw()); got != "siris" {
+		t.Fatalf("unexpected body of the gzip response: %q", got)
+	}
+	e.GET("/image").WithHeader("Accept-Encoding", "br").Expect().
+		Status(siris.StatusOK).Header("Content-Encoding").Empty()
+}
+
+func TestStaticPrecompressed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "siris-static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	js := strings.Repeat("console.log('siris');\n", 64)
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write([]byte(js))
+	gw.Close()
+
+	files := map[string]string{
+		"app.js":    js,
+		"app.js.br": "precompressed br",
+		"app.js.gz": gz.String(),
+		"style.css": strings.Repeat("body { color: red; }\n", 64),
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), os.FileMode(0644)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := siris.New()
+	app.Get("/static/{file:path}", router.StripPrefix("/static", app.StaticHandler(dir, false, true)))
+	e := httptest.New(t, app)
+
+	resp := e.GET("/static/app.js").WithHeader("Accept-Encoding", "gzip, br").Expect().Status(siris.StatusOK)
+	resp.Header("Content-Encoding").Equal("br")
+	resp.Header("Content-Length").Equal("16")
+	resp.ContentType("application/javascript")
+	resp.Body().Equal("precompressed br")
+
+	resp = e.GET("/static/app.js").WithHeader("Accept-Encoding", "gzip, br;q=0.1").Expect().Status(siris.StatusOK)
+	resp.Header("Content-Encoding").Equal("gzip")
+	resp.Header("Vary").Equal("Accept-Encoding")
+	if got := decompress(t, "gzip", resp.Body().Raw()); got != js {
+		t.Fatalf("unexpected gzip body: %q", got)
+	}
+
+	// no precompressed sibling of an acceptable content coding, compressed on the fly.
+	resp = e.GET("/static/app.js").WithHeader("Accept-Encoding", "deflate").Expect().Status(siris.StatusOK)
+	resp.Header("Content-Encoding").Equal("deflate")
+	if got := decompress(t, "deflate", resp.Body().Raw()); got != js {
+		t.Fatalf("unexpected deflate body: %q", got)
+	}
+
+	resp = e.GET("/static/style.css").WithHeader("Accept-Encoding", "br").Expect().Status(siris.StatusOK)
+	resp.Header("Content-Encoding").Equal("br")
+	resp.Header("Content-Length").Empty()
+	if got := decompress(t, "br", resp.Body().Raw()); got != files["style.css"] {
+		t.Fatalf("unexpected br body: %q", got)
+	}
+
+	// the range of the uncompressed content.
+	resp = e.GET("/static/style.css").WithHeader("Accept-Encoding", "br").WithHeader("Range", "bytes=0-1099").
+		Expect().Status(siris.StatusPartialContent)
+	resp.Header("Content-Encoding").Empty()
+	resp.Header("Content-Range").Equal("bytes 0-1099/1344")
+	resp.Header("Content-Length").Equal("1100")
+	resp.Body().Equal(files["style.css"][:1100])
+
+	resp = e.GET("/static/app.js").Expect().Status(siris.StatusOK)
+	resp.Header("Content-Encoding").Empty()
+	resp.Body().Equal(js)
+
+	e.GET("/static/missing.js").WithHeader("Accept-Encoding", "br").Expect().
+		Status(siris.StatusNotFound).Header("Content-Encoding").Empty()
+}
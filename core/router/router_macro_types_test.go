@@ -0,0 +1,77 @@
+// black-box testing
+package router_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-siris/siris"
+	"github.com/go-siris/siris/context"
+
+	"github.com/go-siris/siris/httptest"
+)
+
+func TestMacroTypes(t *testing.T) {
+	app := siris.New()
+
+	app.Get("/int64/{id:int64 min(1)}", func(ctx context.Context) {
+		id, _ := ctx.Params().GetInt64("id")
+		ctx.Writef("%T:%d", ctx.Params().GetValue("id"), id)
+	})
+	app.Get("/uint64/{id:uint64}", func(ctx context.Context) {
+		id, _ := ctx.Params().GetUint64("id")
+		ctx.Writef("%d", id)
+	})
+	app.Get("/bool/{enabled:bool}", func(ctx context.Context) {
+		enabled, _ := ctx.Params().GetBool("enabled")
+		ctx.Writef("%v", enabled)
+	})
+	app.Get("/date/{day:date}", func(ctx context.Context) {
+		day, _ := ctx.Params().GetTime("day")
+		ctx.WriteString(day.Weekday().String())
+	})
+	app.Get("/uuid/{id:uuid}", func(ctx context.Context) {
+		ctx.WriteString(ctx.Params().Get("id"))
+	})
+
+	app.Macros().Register("color", nil, func(paramValue string) (interface{}, error) {
+		switch paramValue {
+		case "red", "green", "blue":
+			return paramValue, nil
+		}
+		return nil, fmt.Errorf("%s is not a color", paramValue)
+	})
+	app.Get("/colors/{c:color}", func(ctx context.Context) {
+		ctx.WriteString(ctx.Params().GetValue("c").(string))
+	})
+
+	app.Get("/orders/{status:enum(active, closed)}", func(ctx context.Context) {
+		ctx.WriteString(ctx.Params().Get("status"))
+	})
+	app.Macros().RegisterEnum("size", "small", "large")
+	app.Get("/shirts/{s:size}", func(ctx context.Context) {
+		ctx.WriteString(ctx.Params().Get("s"))
+	})
+
+	e := httptest.New(t, app)
+
+	e.GET("/int64/-1").Expect().Status(siris.StatusNotFound)
+	e.GET("/int64/9223372036854775807").Expect().Status(siris.StatusOK).
+		Body().Equal("int64:9223372036854775807")
+	e.GET("/uint64/18446744073709551615").Expect().Status(siris.StatusOK).
+		Body().Equal("18446744073709551615")
+	e.GET("/uint64/-1").Expect().Status(siris.StatusNotFound)
+	e.GET("/bool/true").Expect().Status(siris.StatusOK).Body().Equal("true")
+	e.GET("/bool/yes").Expect().Status(siris.StatusNotFound)
+	e.GET("/date/2017-07-27").Expect().Status(siris.StatusOK).Body().Equal(time.Thursday.String())
+	e.GET("/date/2017-02-30").Expect().Status(siris.StatusNotFound)
+	e.GET("/uuid/6ba7b810-9dad-11d1-80b4-00c04fd430c8").Expect().Status(siris.StatusOK)
+	e.GET("/uuid/6ba7b810").Expect().Status(siris.StatusNotFound)
+	e.GET("/colors/red").Expect().Status(siris.StatusOK).Body().Equal("red")
+	e.GET("/colors/black").Expect().Status(siris.StatusNotFound)
+	e.GET("/orders/closed").Expect().Status(siris.StatusOK).Body().Equal("closed")
+	e.GET("/orders/pending").Expect().Status(siris.StatusNotFound)
+	e.GET("/shirts/large").Expect().Status(siris.StatusOK).Body().Equal("large")
+	e.GET("/shirts/medium").Expect().Status(siris.StatusNotFound)
+}
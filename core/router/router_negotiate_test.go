@@ -0,0 +1,88 @@
+// black-box testing
+package router_test
+
+import (
+	"testing"
+
+	"github.com/go-siris/siris"
+	"github.com/go-siris/siris/context"
+	"github.com/go-siris/siris/core/msgpack"
+
+	"github.com/go-siris/siris/httptest"
+)
+
+type negotiateTestUser struct {
+	Name string `json:"name" xml:"name" yaml:"name"`
+	Age  int    `json:"age" xml:"age" yaml:"age"`
+}
+
+func TestNegotiate(t *testing.T) {
+	context.RegisterRenderer("application/vnd.siris.user", func(ctx context.Context, v interface{}) (int, error) {
+		u := v.(negotiateTestUser)
+		return ctx.WriteString(u.Name + ":" + "custom")
+	})
+	defer context.UnregisterRenderer("application/vnd.siris.user")
+
+	app := siris.New()
+	user := negotiateTestUser{Name: "siris", Age: 3}
+	app.Get("/", func(ctx context.Context) {
+		ctx.Negotiate(user)
+	})
+	app.Get("/json-or-html", func(ctx context.Context) {
+		ctx.Negotiate(user, "application/json", "text/html")
+	})
+	app.Get("/text", func(ctx context.Context) {
+		ctx.Negotiate("<h1>siris</h1>", "text/html", "text/plain")
+	})
+
+	e := httptest.New(t, app)
+
+	tests := []struct {
+		path        string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"/", "", "application/json; charset=UTF-8", `{"name":"siris","age":3}`},
+		{"/", "application/json", "application/json; charset=UTF-8", `{"name":"siris","age":3}`},
+		{"/", "text/html;q=0.9, application/xml", "application/xml; charset=UTF-8", "<negotiateTestUser><name>siris</name><age>3</age></negotiateTestUser>"},
+		{"/", "application/json;q=0, */*;q=0.1", "application/xml; charset=UTF-8", "<negotiateTestUser><name>siris</name><age>3</age></negotiateTestUser>"},
+		{"/", "application/*;q=0.2, application/x-yaml", "application/x-yaml; charset=UTF-8", "name: siris\nage: 3\n"},
+		{"/", "application/vnd.siris.user", "application/vnd.siris.user", "siris:custom"},
+		// html is not offered without a template.
+		{"/json-or-html", "text/html, */*;q=0.1", "application/json; charset=UTF-8", `{"name":"siris","age":3}`},
+		{"/text", "text/html", "text/html; charset=UTF-8", "<h1>siris</h1>"},
+		{"/text", "text/*;q=0.5, text/plain", "text/plain; charset=UTF-8", "<h1>siris</h1>"},
+	}
+
+	for _, tt := range tests {
+		req := e.GET(tt.path)
+		if tt.accept != "" {
+			req = req.WithHeader("Accept", tt.accept)
+		}
+		res := req.Expect().Status(siris.StatusOK)
+		res.Header("Content-Type").Equal(tt.contentType)
+		res.Header("Vary").Equal("Accept")
+		res.Body().Equal(tt.body)
+	}
+
+	body := e.GET("/").WithHeader("Accept", "application/msgpack").Expect().
+		Status(siris.StatusOK).ContentType("application/msgpack", "").Body().Raw()
+	values, err := msgpack.UnmarshalMap([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["name"] != "siris" || values["age"] != int64(3) {
+		t.Fatalf("unexpected msgpack values %#v", values)
+	}
+
+	e.GET("/").WithQuery("callback", "app.onUser").WithHeader("Accept", "application/javascript").Expect().
+		Status(siris.StatusOK).Body().Equal(`app.onUser({"name":"siris","age":3});`)
+	e.GET("/").WithQuery("callback", "alert(1);x").WithHeader("Accept", "application/javascript").Expect().
+		Status(siris.StatusBadRequest).Body().NotContains("alert")
+
+	e.GET("/").WithHeader("Accept", "image/png").Expect().
+		Status(siris.StatusNotAcceptable).Header("Vary").Equal("Accept")
+	e.GET("/json-or-html").WithHeader("Accept", "application/xml").Expect().
+		Status(siris.StatusNotAcceptable)
+}
@@ -0,0 +1,77 @@
+// black-box testing
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-siris/siris"
+	"github.com/go-siris/siris/context"
+)
+
+func TestRemoteAddrTrustedProxies(t *testing.T) {
+	app := siris.New()
+	app.Get("/", func(ctx context.Context) {
+		ctx.Writef("%s %s %s", ctx.RemoteAddr(), ctx.Scheme(), ctx.Host())
+	})
+
+	app.Configure(
+		siris.WithRemoteAddrHeader("X-Forwarded-For"),
+		siris.WithRemoteAddrHeader("X-Real-Ip"),
+		siris.WithRemoteAddrHeader("Forwarded"),
+		siris.WithTrustedProxies("10.0.0.0/8", "192.168.1.1"),
+	)
+
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		peer     string
+		headers  map[string]string
+		expected string
+	}{
+		// not a trusted peer, headers are ignored.
+		{"203.0.113.9:1234", map[string]string{"X-Forwarded-For": "1.1.1.1", "X-Forwarded-Proto": "https"},
+			"203.0.113.9 http example.com"},
+		// trusted peer, the first untrusted hop from the right is the client.
+		{"10.0.0.2:1234", map[string]string{"X-Forwarded-For": "6.6.6.6, 198.51.100.7, 10.1.1.1"},
+			"198.51.100.7 http example.com"},
+		// all hops are trusted, the left-most is returned.
+		{"10.0.0.2:1234", map[string]string{"X-Forwarded-For": "192.168.1.1, 10.1.1.1"},
+			"192.168.1.1 http example.com"},
+		// X-Real-Ip has precedence over the X-Forwarded-For.
+		{"192.168.1.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.7", "X-Real-Ip": "198.51.100.8"},
+			"198.51.100.8 http example.com"},
+		{"10.0.0.2:1234", map[string]string{"Forwarded": `for=198.51.100.7;proto=https;host=api.example.com, for="[2001:db8::1]:4711"`},
+			"2001:db8::1 https api.example.com"},
+		{"10.0.0.2:1234", map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "spoofed.com, api.example.com"},
+			"10.0.0.2 https api.example.com"},
+	}
+
+	for i, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tt.peer
+		for k, v := range tt.headers {
+			req.Header.Set(k, v)
+		}
+
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+
+		if got := rec.Body.String(); got != tt.expected {
+			t.Fatalf("[%d] expected %q but got %q", i, tt.expected, got)
+		}
+	}
+}
+
+func TestRemoteAddrInvalidTrustedProxy(t *testing.T) {
+	app := siris.New()
+	app.Configure(siris.WithTrustedProxies("10.0.0.0/8", "10.0.0.300"))
+
+	if err := app.Build(); err == nil || !strings.Contains(err.Error(), "10.0.0.300") {
+		t.Fatalf("expected an error for the invalid trusted proxy but got: %v", err)
+	}
+}
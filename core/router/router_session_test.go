@@ -0,0 +1,155 @@
+// black-box testing
+package router_test
+
+import (
+	"testing"
+
+	"github.com/go-siris/siris"
+	"github.com/go-siris/siris/context"
+	"github.com/go-siris/siris/sessions"
+
+	"github.com/go-siris/siris/httptest"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSessionTypedValuesAndFlashes(t *testing.T) {
+	app := siris.New()
+	app.AttachSessionManager("memory", &sessions.ManagerConfig{
+		CookieName:      "siris-session-test",
+		EnableSetCookie: true,
+		Gclifetime:      3600,
+		Maxlifetime:     3600,
+	})
+
+	app.Get("/set", func(ctx context.Context) {
+		s := ctx.RequestSession()
+		s.Set("name", "siris")
+		s.Set("age", 3)
+		s.Set("admin", "true")
+		s.SetFlash("message", "saved")
+
+		if s.HasFlash("message") {
+			t.Fatalf("expected the flash message to be readable on the next request only")
+		}
+
+		ctx.Redirect("/get")
+	})
+
+	app.Get("/get", func(ctx context.Context) {
+		s := ctx.RequestSession()
+		age, err := s.GetInt("age")
+		if err != nil {
+			t.Fatal(err)
+		}
+		admin, err := s.GetBool("admin")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx.Writef("%s %d %v %d %s", s.GetString("name"), age, admin, len(s.Keys()), s.GetFlashString("message"))
+	})
+
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+	e.GET("/set").Expect().Status(siris.StatusOK).Body().Equal("siris 3 true 3 saved")
+	// the flash message is readable once.
+	e.GET("/get").Expect().Status(siris.StatusOK).Body().Equal("siris 3 true 3 ")
+}
+
+func TestSessionAutoRelease(t *testing.T) {
+	app := siris.New()
+	app.AttachSessionManager("cookie", &sessions.ManagerConfig{
+		CookieName:      "siris-session-test",
+		EnableSetCookie: true,
+		Gclifetime:      3600,
+		Maxlifetime:     3600,
+		ProviderConfig:  `{"cookieName":"siris-session-test","securityKey":"siriscookiehashkey"}`,
+	})
+
+	app.Get("/set", func(ctx context.Context) {
+		ctx.Session().Set("name", "siris")
+	})
+
+	app.Get("/get", func(ctx context.Context) {
+		s := ctx.RequestSession()
+		if s.IsDirty() {
+			t.Fatalf("expected a clean session")
+		}
+		ctx.WriteString(s.GetString("name"))
+	})
+
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+	e.GET("/set").Expect().Status(siris.StatusOK)
+	e.GET("/get").Expect().Status(siris.StatusOK).Body().Equal("siris")
+}
+
+func TestSessionReleaseAfterBodyIsLogged(t *testing.T) {
+	app := siris.New()
+	core, logs := observer.New(zapcore.ErrorLevel)
+	*app.Logger() = *zap.New(core).Sugar()
+	app.AttachSessionManager("cookie", &sessions.ManagerConfig{
+		CookieName:      "siris-session-test",
+		EnableSetCookie: true,
+		Gclifetime:      3600,
+		Maxlifetime:     3600,
+		ProviderConfig:  `{"cookieName":"siris-session-test","securityKey":"siriscookiehashkey"}`,
+	})
+
+	app.Get("/before", func(ctx context.Context) {
+		s := ctx.RequestSession()
+		s.Set("name", "siris")
+		if err := s.Release(ctx.Request().Context(), ctx.ResponseWriter()); err != nil {
+			t.Fatal(err)
+		}
+		ctx.WriteString("ok")
+	})
+
+	app.Get("/after", func(ctx context.Context) {
+		ctx.WriteString("ok")
+		ctx.Session().Set("name", "siris")
+	})
+
+	e := httptest.New(t, app, httptest.URL("http://example.com"))
+	e.GET("/before").Expect().Status(siris.StatusOK)
+	if n := logs.Len(); n != 0 {
+		t.Fatalf("expected no logs when the session is released before the body but got %d", n)
+	}
+
+	e.GET("/after").Expect().Status(siris.StatusOK)
+	if n := logs.Len(); n != 1 {
+		t.Fatalf("expected the dropped session cookie to be logged but got %d logs", n)
+	}
+}
+
+func TestSessionLogin(t *testing.T) {
+	app := siris.New()
+	app.AttachSessionManager("memory", &sessions.ManagerConfig{
+		CookieName:         "siris-session-login-test",
+		EnableSetCookie:    true,
+		Gclifetime:         3600,
+		Maxlifetime:        3600,
+		MaxSessionsPerUser: 1,
+	})
+
+	app.Get("/login", func(ctx context.Context) {
+		ctx.Session().Set("cart", "1 item")
+		ctx.SessionLogin("siris-user")
+	})
+
+	app.Get("/me", func(ctx context.Context) {
+		s := ctx.RequestSession()
+		ctx.Writef("%s %s", s.UserID(), s.GetString("cart"))
+	})
+
+	device1 := httptest.New(t, app, httptest.URL("http://example.com"))
+	device1.GET("/login").Expect().Status(siris.StatusOK)
+	device1.GET("/me").Expect().Status(siris.StatusOK).Body().Equal("siris-user 1 item")
+
+	// the login of another device exceeds the limit, the first device is logged out.
+	device2 := httptest.New(t, app, httptest.URL("http://example.com"))
+	device2.GET("/login").Expect().Status(siris.StatusOK)
+	device2.GET("/me").Expect().Status(siris.StatusOK).Body().Equal("siris-user 1 item")
+	device1.GET("/me").Expect().Status(siris.StatusOK).Body().Equal(" ")
+}
@@ -0,0 +1,244 @@
+// black-box testing
+package router_test
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-siris/siris"
+	"github.com/go-siris/siris/context"
+)
+
+// readEvent reads the lines of the next event, or comment, of the stream.
+func readEvent(t *testing.T, r *bufio.Reader) string {
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read event: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return strings.Join(lines, "\n")
+		}
+		lines = append(lines, line)
+	}
+}
+
+func getEvents(t *testing.T, url string, lastEventID string) (*http.Response, *bufio.Reader) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res, bufio.NewReader(res.Body)
+}
+
+func TestSSE(t *testing.T) {
+	app := siris.New()
+	app.Get("/events", func(ctx context.Context) {
+		events := ctx.SSE()
+		events.Retry(3 * time.Second)
+		events.Send("", "", "hello")
+		events.Send("greeting", "1", "multi\nline")
+		events.Send("user", "2", map[string]string{"name": "siris"})
+
+		if err := events.Send("bad\nname", "", "data"); err == nil {
+			events.Send("error", "", "the new line of the event's name should be rejected")
+		}
+		events.Send("last-event-id", "", events.LastEventID())
+	})
+	app.Build()
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	res, r := getEvents(t, srv.URL+"/events", "0")
+	defer res.Body.Close()
+
+	if expected, got := "text/event-stream", res.Header.Get("Content-Type"); expected != got {
+		t.Fatalf("expected Content-Type %q but got %q", expected, got)
+	}
+	if expected, got := "no-cache", res.Header.Get("Cache-Control"); expected != got {
+		t.Fatalf("expected Cache-Control %q but got %q", expected, got)
+	}
+
+	expectedEvents := []string{
+		"retry: 3000",
+		"data: hello",
+		"id: 1\nevent: greeting\ndata: multi\ndata: line",
+		"id: 2\nevent: user\ndata: {\"name\":\"siris\"}",
+		"event: last-event-id\ndata: 0",
+	}
+	for i, expected := range expectedEvents {
+		if got := readEvent(t, r); expected != got {
+			t.Fatalf("[%d] expected event:\n%s\nbut got:\n%s", i, expected, got)
+		}
+	}
+}
+
+func TestSSEHeartbeatAndCloseNotify(t *testing.T) {
+	defer func(d time.Duration) { context.SSEHeartbeat = d }(context.SSEHeartbeat)
+	context.SSEHeartbeat = 20 * time.Millisecond
+
+	done := make(chan struct{})
+	app := siris.New()
+	app.Get("/events", func(ctx context.Context) {
+		events := ctx.SSE()
+		<-events.Done()
+		if err := events.Send("", "", "closed"); err != context.ErrEventStreamClosed {
+			t.Errorf("expected the ErrEventStreamClosed but got: %v", err)
+		}
+		close(done)
+	})
+	app.Build()
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	res, r := getEvents(t, srv.URL+"/events", "")
+	if expected, got := ":", readEvent(t, r); expected != got {
+		t.Fatalf("expected the heartbeat %q but got %q", expected, got)
+	}
+	res.Body.Close()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the handler to be returned after the client is gone")
+	}
+}
+
+func TestSSEHeartbeatAfterHandler(t *testing.T) {
+	defer func(d time.Duration) { context.SSEHeartbeat = d }(context.SSEHeartbeat)
+	context.SSEHeartbeat = time.Millisecond
+
+	app := siris.New()
+	app.Get("/events", func(ctx context.Context) {
+		ctx.SSE()
+		time.Sleep(5 * time.Millisecond)
+	})
+	app.Get("/", func(ctx context.Context) {
+		ctx.WriteString("ok")
+	})
+	app.Build()
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	// the heartbeats are not written to the released response writers of the next requests.
+	for i := 0; i < 20; i++ {
+		res, _ := getEvents(t, srv.URL+"/events", "")
+		ioutil.ReadAll(res.Body)
+		res.Body.Close()
+
+		res, err := http.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if expected, got := "ok", string(b); expected != got {
+			t.Fatalf("[%d] expected body %q but got %q", i, expected, got)
+		}
+	}
+}
+
+func TestSSEBroadcasterSlowSubscriber(t *testing.T) {
+	defer func(d time.Duration) { context.SSEWriteTimeout = d }(context.SSEWriteTimeout)
+	context.SSEWriteTimeout = 100 * time.Millisecond
+
+	broadcaster := context.NewBroadcaster(0)
+	app := siris.New()
+	app.Get("/events", func(ctx context.Context) {
+		broadcaster.Subscribe(ctx.SSE())
+	})
+	app.Build()
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	// the client doesn't read the events.
+	res, _ := getEvents(t, srv.URL+"/events", "")
+	defer res.Body.Close()
+	for i := 0; broadcaster.Len() != 1; i++ {
+		if i == 500 {
+			t.Fatal("expected the subscriber")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		data := strings.Repeat("x", 256<<10)
+		for i := 0; i < 400 && broadcaster.Len() != 0; i++ {
+			broadcaster.Broadcast("", "", data)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the Broadcast not to be blocked by the slow subscriber")
+	}
+	if n := broadcaster.Len(); n != 0 {
+		t.Fatalf("expected the slow subscriber to be closed but got %d subscribers", n)
+	}
+}
+
+func TestSSEBroadcaster(t *testing.T) {
+	broadcaster := context.NewBroadcaster(2)
+	app := siris.New()
+	app.Get("/events", func(ctx context.Context) {
+		broadcaster.Subscribe(ctx.SSE())
+	})
+	app.Build()
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	waitSubscribers := func(n int) {
+		for i := 0; broadcaster.Len() != n; i++ {
+			if i == 500 {
+				t.Fatalf("expected %d subscribers but got %d", n, broadcaster.Len())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	res, r := getEvents(t, srv.URL+"/events", "")
+	waitSubscribers(1)
+
+	broadcaster.Broadcast("tick", "1", "one")
+	broadcaster.Broadcast("tick", "2", "two")
+	broadcaster.Broadcast("tick", "3", "three")
+
+	for _, expected := range []string{"1\nevent: tick\ndata: one", "2\nevent: tick\ndata: two", "3\nevent: tick\ndata: three"} {
+		if got := readEvent(t, r); "id: "+expected != got {
+			t.Fatalf("expected event:\nid: %s\nbut got:\n%s", expected, got)
+		}
+	}
+	res.Body.Close()
+	waitSubscribers(0)
+
+	// reconnects, the events after the last one it received are sent from the history.
+	broadcaster.Broadcast("tick", "4", "four")
+	res, r = getEvents(t, srv.URL+"/events", "3")
+	defer res.Body.Close()
+
+	if expected, got := "id: 4\nevent: tick\ndata: four", readEvent(t, r); expected != got {
+		t.Fatalf("expected the missed event:\n%s\nbut got:\n%s", expected, got)
+	}
+}
@@ -0,0 +1,147 @@
+// black-box testing
+package router_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-siris/siris"
+	"github.com/go-siris/siris/context"
+	"github.com/go-siris/siris/view"
+)
+
+func TestURL(t *testing.T) {
+	app := siris.New()
+	h := func(ctx context.Context) {}
+
+	app.Get("/", h).Name = "home"
+	app.Get("/users/{id:int min(1)}/{tab:alphabetical}", h).Name = "user"
+	app.Get("/files/{file:path}", h).Name = "file"
+	app.Party("admin.").Get("/", h).Name = "admin"
+	app.WildcardSubdomain().Get("/profile/{id:int}", h).Name = "profile"
+
+	// sets the vhost, the server is not started.
+	app.NewHost(&http.Server{Addr: "mydomain.com:80"})
+
+	tests := []struct {
+		routeName string
+		params    siris.URLParams
+		expected  string
+		fail      bool
+	}{
+		{"home", nil, "/", false},
+		{"home", siris.URLParams{"page": 2}, "/?page=2", false},
+		{"user", siris.URLParams{"id": 42, "tab": "posts"}, "/users/42/posts", false},
+		{"user", siris.URLParams{"id": 42, "tab": "posts", "q": []string{"a b", "c"}}, "/users/42/posts?q=a+b&q=c", false},
+		{"user", siris.URLParams{"id": 0, "tab": "posts"}, "", true},     // min(1)
+		{"user", siris.URLParams{"id": "abc", "tab": "posts"}, "", true}, // int
+		{"user", siris.URLParams{"id": 42}, "", true},                    // missing tab
+		{"file", siris.URLParams{"file": "css/my style.css"}, "/files/css/my%20style.css", false},
+		{"admin", nil, "http://admin.mydomain.com/", false},
+		{"profile", siris.URLParams{"subdomain": "john", "id": 1}, "http://john.mydomain.com/profile/1", false},
+		{"profile", siris.URLParams{"id": 1}, "", true}, // missing subdomain
+		{"notfound", nil, "", true},
+	}
+
+	for i, tt := range tests {
+		got, err := app.URL(tt.routeName, tt.params)
+		if tt.fail {
+			if err == nil {
+				t.Fatalf("[%d] expected an error for route '%s' but got url '%s'", i, tt.routeName, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("[%d] unexpected error for route '%s': %v", i, tt.routeName, err)
+		}
+
+		if got != tt.expected {
+			t.Fatalf("[%d] expected url '%s' but got '%s'", i, tt.expected, got)
+		}
+	}
+
+	if expected, got := "/users/7/posts", mustURLPath(t, app, "user", siris.URLParams{"id": 7, "tab": "posts"}); expected != got {
+		t.Fatalf("expected path '%s' but got '%s'", expected, got)
+	}
+}
+
+func mustURLPath(t *testing.T, app *siris.Application, routeName string, params siris.URLParams) string {
+	path, err := app.URLPath(routeName, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestURLViewFuncs(t *testing.T) {
+	engines := []struct {
+		name     string
+		engine   func(dir string) view.Engine
+		ext      string
+		template string
+		invalid  string
+		// the format of the output, i.e within a tag.
+		format string
+	}{
+		{"html", func(dir string) view.Engine { return view.HTML(dir, ".html") }, ".html",
+			`{{ url "user" "id" 42 "page" 2 }} {{ urlpath "admin" }} {{ url "admin" }} {{ urlpath "user" 42 }} {{ urlpath_params "user" "id" 42 }}`,
+			`{{ url "user" "id" 0 }}`, "%s"},
+		{"pug", func(dir string) view.Engine { return view.Pug(dir, ".pug") }, ".pug",
+			`p {{ url "user" "id" 42 "page" 2 }} {{ urlpath "admin" }} {{ url "admin" }} {{ urlpath "user" 42 }} {{ urlpath_params "user" "id" 42 }}`,
+			`p {{ url "user" "id" 0 }}`, "<p>%s</p>"},
+		{"django", func(dir string) view.Engine { return view.Django(dir, ".django") }, ".django",
+			`{{ url("user", "id", 42, "page", 2) }} {{ urlpath("admin") }} {{ url("admin") }} {{ urlpath("user", 42) }} {{ urlpath_params("user", "id", 42) }}`,
+			`{{ url("user", "id", 0) }}`, "%s"},
+		// the handlebars helpers can't be variadic, the parameters are the hash of the "urlpath_params".
+		{"handlebars", func(dir string) view.Engine { return view.Handlebars(dir, ".hbs") }, ".hbs",
+			`{{url "user" id=42 page=2}} {{urlpath "admin"}} {{url "admin"}} {{urlpath_params "user" id=42}} {{urlpath_params "user" id=42}}`,
+			`{{url "user" id=0}}`, "%s"},
+		{"amber", func(dir string) view.Engine { return view.Amber(dir, ".amber") }, ".amber",
+			`p #{url("user", "id", 42, "page", 2)} #{urlpath("admin")} #{url("admin")} #{urlpath("user", 42)} #{urlpath_params("user", "id", 42)}`,
+			`p #{url("user", "id", 0)}`, "<p>%s</p>"},
+	}
+
+	for _, tt := range engines {
+		dir, err := ioutil.TempDir("", "siris-view-"+tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		if err = ioutil.WriteFile(filepath.Join(dir, "index"+tt.ext), []byte(tt.template), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(filepath.Join(dir, "invalid"+tt.ext), []byte(tt.invalid), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		app := siris.New()
+		app.Get("/users/{id:int min(1)}", func(ctx context.Context) {}).Name = "user"
+		app.Party("admin.").Get("/", func(ctx context.Context) {}).Name = "admin"
+		app.NewHost(&http.Server{Addr: "mydomain.com:80"})
+		app.AttachView(tt.engine(dir))
+		if err = app.Build(); err != nil {
+			t.Fatalf("%s: build: %v", tt.name, err)
+		}
+
+		var b bytes.Buffer
+		if err = app.View(&b, "index"+tt.ext, "", nil); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		expected := fmt.Sprintf(tt.format, "/users/42?page=2 / http://admin.mydomain.com/ /users/42 /users/42")
+		if got := strings.TrimSpace(b.String()); expected != got {
+			t.Fatalf("%s: expected '%s' but got '%s'", tt.name, expected, got)
+		}
+
+		if err = app.View(&b, "invalid"+tt.ext, "", nil); err == nil {
+			t.Fatalf("%s: expected the error of an invalid parameter", tt.name)
+		}
+	}
+}
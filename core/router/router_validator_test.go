@@ -0,0 +1,86 @@
+// black-box testing
+package router_test
+
+import (
+	"testing"
+
+	"github.com/go-siris/siris"
+	"github.com/go-siris/siris/context"
+	"github.com/go-siris/siris/validator"
+
+	"github.com/go-siris/siris/httptest"
+)
+
+type validatorTestUser struct {
+	Name  string `json:"name" formam:"name" url:"name" validate:"required,max=8"`
+	Email string `json:"email" formam:"email" url:"email" validate:"required,email"`
+}
+
+func TestReadValidation(t *testing.T) {
+	app := siris.New()
+	app.AttachValidator(validator.New())
+	handler := func(read func(ctx context.Context, v interface{}) error) context.Handler {
+		return func(ctx context.Context) {
+			var user validatorTestUser
+			if err := read(ctx, &user); err != nil {
+				if errs, ok := err.(validator.ValidationErrors); ok {
+					errs.WriteProblem(ctx)
+					return
+				}
+				ctx.StatusCode(siris.StatusBadRequest)
+				return
+			}
+			ctx.WriteString(user.Name)
+		}
+	}
+
+	app.Post("/json", handler(context.Context.ReadJSON))
+	app.Post("/form", handler(context.Context.ReadForm))
+	app.Get("/query", handler(context.Context.ReadQuery))
+
+	e := httptest.New(t, app)
+
+	e.POST("/json").WithJSON(map[string]string{"name": "siris", "email": "siris@example.com"}).
+		Expect().Status(siris.StatusOK).Body().Equal("siris")
+
+	e.POST("/json").WithJSON(map[string]string{"name": "a very long name"}).
+		Expect().Status(siris.StatusUnprocessableEntity).
+		ContentType("application/problem+json", "UTF-8").
+		Body().Equal(`{"type":"about:blank","title":"Unprocessable Entity","status":422,` +
+		`"detail":"name must be at most 8; email is required","errors":[` +
+		`{"field":"name","tag":"max","param":"8","message":"name must be at most 8"},` +
+		`{"field":"email","tag":"required","message":"email is required"}]}`)
+
+	e.POST("/json").WithText("{").Expect().Status(siris.StatusBadRequest)
+
+	e.POST("/form").WithFormField("name", "siris").WithFormField("email", "siris").
+		Expect().Status(siris.StatusUnprocessableEntity).
+		Body().Contains(`"field":"email","tag":"email"`)
+
+	e.GET("/query").WithQuery("name", "siris").WithQuery("email", "siris@example.com").
+		Expect().Status(siris.StatusOK).Body().Equal("siris")
+	e.GET("/query").Expect().Status(siris.StatusUnprocessableEntity)
+
+	// disabled.
+	app.AttachValidator(nil)
+	e.GET("/query").Expect().Status(siris.StatusOK)
+}
+
+func TestReadValidationDisabledByDefault(t *testing.T) {
+	app := siris.New()
+	app.Post("/json", func(ctx context.Context) {
+		// a rule of another validator.
+		var v struct {
+			Age int `json:"age" validate:"gte=0"`
+		}
+		if err := ctx.ReadJSON(&v); err != nil {
+			ctx.StatusCode(siris.StatusBadRequest)
+			return
+		}
+		ctx.Writef("%d", v.Age)
+	})
+
+	e := httptest.New(t, app)
+	e.POST("/json").WithJSON(map[string]int{"age": 3}).
+		Expect().Status(siris.StatusOK).Body().Equal("3")
+}
@@ -27,8 +27,8 @@ func (ch *ErrorCodeHandler) Fire(ctx context.Context) {
 		// reset if previous content and it's recorder, keep the status code.
 		w.ClearHeaders()
 		w.ResetBody()
-	} else if w, ok := ctx.ResponseWriter().(*context.GzipResponseWriter); ok {
-		// reset and disable the gzip in order to be an expected form of http error result
+	} else if w, ok := ctx.ResponseWriter().(*context.CompressResponseWriter); ok {
+		// reset and disable the compression in order to be an expected form of http error result
 		w.ResetBody()
 		w.Disable()
 	} else {
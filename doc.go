@@ -946,6 +946,8 @@ Example code:
         // default template funcs are:
         //
         // - {{ urlpath "mynamedroute" "pathParameter_ifneeded" }}
+        // - {{ urlpath_params "mynamedroute" "paramName" "paramValue_ifneeded" }}
+        // - {{ url "mynamedroute" "paramName" "paramValue_ifneeded" }}
         // - {{ render "header.html" }}
         // - {{ render_r "header.html" }} // partial relative path to current page
         // - {{ yield }}
@@ -1055,7 +1057,7 @@ Example code:
     func secret(ctx context.Context) {
 
         // Check if user is authenticated
-        if auth, _ := ctx.Session().Get("authenticated"); !auth {
+        if auth, _ := ctx.RequestSession().GetBool("authenticated"); !auth {
             ctx.StatusCode(siris.StatusForbidden)
             return
         }
@@ -1075,10 +1077,13 @@ Example code:
     }
 
     func logout(ctx context.Context) {
-        session := ctx.Session()
+        session := ctx.RequestSession()
 
         // Revoke users authentication
         session.Set("authenticated", false)
+
+        // Readable once, on the next request, by session.GetFlashString("message")
+        session.SetFlash("message", "You have been logged out")
     }
 
     func main() {
@@ -1112,6 +1117,8 @@ Running the example:
     $ curl -s --cookie "mysessionid=MTQ4NzE5Mz..." http://localhost:8080/secret
     The cake is a lie!
 
+The session's values are saved at the end of the request, only if they were modified.
+
 
 
 That's the basics
@@ -0,0 +1,321 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+	Package openapi generates an OpenAPI 3 document from the registered routes.
+
+Paths and path parameters' schemas are generated from the routes' templates,
+i.e {id:int min(1)} becomes an integer schema with a minimum of 1,
+request and response bodies from the Go types that are attached to the routes.
+
+Example code:
+
+	import (
+		"github.com/go-siris/siris"
+		"github.com/go-siris/siris/context"
+		"github.com/go-siris/siris/openapi"
+	)
+
+	type User struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	func main(){
+		app := siris.New()
+		app.Get("/users/{id:int min(1)}", getUser).
+			With(openapi.Summary("Get a user"), openapi.Returns(200, User{}))
+		app.Post("/users", createUser).
+			With(openapi.Request(User{}), openapi.Returns(201, User{}))
+
+		openapi.Register(app, openapi.Config{Title: "Users API", Version: "1.0.0"})
+		app.Run(siris.Addr(":8080"))
+	}
+
+The document is served at /openapi.json by default.
+*/
+package openapi
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/go-siris/siris/context"
+	"github.com/go-siris/siris/core/router"
+)
+
+// Version is the OpenAPI specification version of the generated documents.
+const Version = "3.0.0"
+
+const contentJSON = "application/json"
+
+// DefaultPath is the request path of the generated document, see `Config#Path`.
+const DefaultPath = "/openapi.json"
+
+// Config contains the options of the generated document.
+type Config struct {
+	// Title is the title of the API.
+	// Defaults to "API".
+	Title string
+	// Version is the version of the API, not the OpenAPI's one.
+	// Defaults to "1.0.0".
+	Version string
+	// Description is the description of the API, optionally.
+	Description string
+	// Servers are the base urls of the API, optionally.
+	Servers []string
+	// Path is the request path which the document is served at,
+	// the GET route of the Path is not part of the document.
+	// Defaults to "/openapi.json".
+	Path string
+}
+
+func (c Config) validate() Config {
+	if c.Title == "" {
+		c.Title = "API"
+	}
+	if c.Version == "" {
+		c.Version = "1.0.0"
+	}
+	if c.Path == "" {
+		c.Path = DefaultPath
+	}
+	return c
+}
+
+type (
+	// Document is the root object of an OpenAPI 3 document.
+	Document struct {
+		OpenAPI    string               `json:"openapi"`
+		Info       Info                 `json:"info"`
+		Servers    []Server             `json:"servers,omitempty"`
+		Paths      map[string]*PathItem `json:"paths"`
+		Components *Components          `json:"components,omitempty"`
+	}
+
+	// Info is the metadata of the API.
+	Info struct {
+		Title       string `json:"title"`
+		Description string `json:"description,omitempty"`
+		Version     string `json:"version"`
+	}
+
+	// Server is a base url of the API.
+	Server struct {
+		URL string `json:"url"`
+	}
+
+	// PathItem contains the operations of a single path, per http method.
+	PathItem struct {
+		Get     *Operation `json:"get,omitempty"`
+		Put     *Operation `json:"put,omitempty"`
+		Post    *Operation `json:"post,omitempty"`
+		Delete  *Operation `json:"delete,omitempty"`
+		Options *Operation `json:"options,omitempty"`
+		Head    *Operation `json:"head,omitempty"`
+		Patch   *Operation `json:"patch,omitempty"`
+		Trace   *Operation `json:"trace,omitempty"`
+	}
+
+	// Operation describes a single route.
+	Operation struct {
+		OperationID string               `json:"operationId,omitempty"`
+		Summary     string               `json:"summary,omitempty"`
+		Description string               `json:"description,omitempty"`
+		Tags        []string             `json:"tags,omitempty"`
+		Parameters  []Parameter          `json:"parameters,omitempty"`
+		RequestBody *RequestBody         `json:"requestBody,omitempty"`
+		Responses   map[string]*Response `json:"responses"`
+	}
+
+	// Parameter describes a path parameter.
+	Parameter struct {
+		Name     string  `json:"name"`
+		In       string  `json:"in"`
+		Required bool    `json:"required"`
+		Schema   *Schema `json:"schema"`
+	}
+
+	// RequestBody describes the request body of an operation.
+	RequestBody struct {
+		Required bool                  `json:"required"`
+		Content  map[string]*MediaType `json:"content"`
+	}
+
+	// Response describes a response of an operation.
+	Response struct {
+		Description string                `json:"description"`
+		Content     map[string]*MediaType `json:"content,omitempty"`
+	}
+
+	// MediaType contains the schema of a body.
+	MediaType struct {
+		Schema *Schema `json:"schema"`
+	}
+
+	// Components contains the reusable schemas of the Go types.
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas,omitempty"`
+	}
+)
+
+// Generate returns the OpenAPI 3 document of the "routes".
+// Offline routes and subdomain routes are skipped,
+// paths of the specification can't describe a host.
+func Generate(routes []*router.Route, cfg Config) *Document {
+	cfg = cfg.validate()
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       cfg.Title,
+			Description: cfg.Description,
+			Version:     cfg.Version,
+		},
+		Paths: make(map[string]*PathItem),
+	}
+
+	for _, s := range cfg.Servers {
+		doc.Servers = append(doc.Servers, Server{URL: s})
+	}
+
+	schemas := newSchemaRegistry()
+
+	for _, r := range routes {
+		if !r.IsOnline() || r.Subdomain != "" {
+			continue
+		}
+
+		if r.Method == http.MethodGet && r.Path == cfg.Path {
+			continue // the document's route.
+		}
+
+		path, params := convertTmpl(r.Tmpl())
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = new(PathItem)
+			doc.Paths[path] = item
+		}
+
+		op := &Operation{
+			OperationID: r.Name,
+			Summary:     r.Doc.Summary,
+			Description: r.Doc.Description,
+			Tags:        r.Doc.Tags,
+			Parameters:  params,
+			Responses:   make(map[string]*Response),
+		}
+
+		if r.Doc.Request != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content: map[string]*MediaType{
+					contentJSON: {Schema: schemas.schemaOf(r.Doc.Request)},
+				},
+			}
+		}
+
+		for statusCode, v := range r.Doc.Responses {
+			resp := &Response{Description: http.StatusText(statusCode)}
+			if v != nil {
+				resp.Content = map[string]*MediaType{
+					contentJSON: {Schema: schemas.schemaOf(v)},
+				}
+			}
+			op.Responses[strconv.Itoa(statusCode)] = resp
+		}
+
+		if len(op.Responses) == 0 {
+			op.Responses["default"] = &Response{Description: "Default response"}
+		}
+
+		item.set(r.Method, op)
+	}
+
+	if len(schemas.schemas) > 0 {
+		doc.Components = &Components{Schemas: schemas.schemas}
+	}
+
+	return doc
+}
+
+// set sets the operation based on the http method,
+// methods that are not supported by the specification, i.e CONNECT, are ignored.
+func (p *PathItem) set(method string, op *Operation) {
+	switch method {
+	case http.MethodGet:
+		p.Get = op
+	case http.MethodPut:
+		p.Put = op
+	case http.MethodPost:
+		p.Post = op
+	case http.MethodDelete:
+		p.Delete = op
+	case http.MethodOptions:
+		p.Options = op
+	case http.MethodHead:
+		p.Head = op
+	case http.MethodPatch:
+		p.Patch = op
+	case http.MethodTrace:
+		p.Trace = op
+	}
+}
+
+// Handler returns a handler which serves the OpenAPI 3 document
+// of the "provider"'s routes as JSON.
+// The document is generated once, on the first request,
+// all routes should be registered before the server start.
+func Handler(provider router.RoutesProvider, cfg Config) context.Handler {
+	var (
+		once sync.Once
+		doc  *Document
+	)
+
+	return func(ctx context.Context) {
+		once.Do(func() {
+			doc = Generate(provider.GetRoutes(), cfg)
+		})
+		ctx.JSON(doc)
+	}
+}
+
+// Registerer is the interface which the Application and the Parties implement,
+// it's used to register the document's route.
+//
+// The routes of the document are provided by the "app" if it's a `router.RoutesProvider`,
+// as the Application and the Parties of the APIBuilder are.
+type Registerer interface {
+	Get(path string, handlers ...context.Handler) *router.Route
+}
+
+// Register registers a GET route at the `Config#Path` which serves
+// the OpenAPI 3 document of the "app"'s routes as JSON.
+//
+// Returns the document's route, it's not part of the document itself,
+// or nil if the "app" is not a `router.RoutesProvider`.
+func Register(app Registerer, cfg Config) *router.Route {
+	provider, ok := app.(router.RoutesProvider)
+	if !ok {
+		return nil
+	}
+	cfg = cfg.validate()
+
+	var handler context.Handler
+	r := app.Get(cfg.Path, func(ctx context.Context) {
+		handler(ctx)
+	})
+	if r == nil {
+		return nil
+	}
+	r.Name = "openapi"
+
+	// the "cfg.Path" is relative to the party,
+	// the document's route is skipped by its full path.
+	cfg.Path = r.Path
+	handler = Handler(provider, cfg)
+	return r
+}
@@ -0,0 +1,128 @@
+// black-box testing
+package openapi_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-siris/siris"
+	"github.com/go-siris/siris/context"
+	"github.com/go-siris/siris/core/router"
+	"github.com/go-siris/siris/openapi"
+
+	"github.com/go-siris/siris/httptest"
+)
+
+type address struct {
+	City string `json:"city"`
+}
+
+type user struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Addresses []address `json:"addresses"`
+	Friends   []*user   `json:"friends,omitempty"`
+	secret    string
+}
+
+func TestGenerate(t *testing.T) {
+	app := siris.New()
+	h := func(ctx context.Context) {}
+
+	app.Get("/users/{id:int min(1) max(100)}", h).
+		With(openapi.Summary("Get a user"), openapi.Tags("users"), openapi.Returns(200, user{}), openapi.Returns(404, nil))
+	app.Post("/users", h).
+		With(openapi.Request(user{}), openapi.Returns(201, &user{}))
+	app.Get("/users/{name:string regexp(^[a-z]+$)}/{day:date}", h)
+	app.Get("/files/{file:path}", h)
+	app.Party("admin.").Get("/", h)
+
+	doc := openapi.Generate(app.GetRoutes(), openapi.Config{Title: "Users"})
+
+	if expected, got := openapi.Version, doc.OpenAPI; expected != got {
+		t.Fatalf("expected openapi version %s but got %s", expected, got)
+	}
+
+	if expected, got := 4, len(doc.Paths); expected != got {
+		t.Fatalf("expected %d paths but got %d: %v", expected, got, doc.Paths)
+	}
+
+	get := doc.Paths["/users/{id}"].Get
+	if get == nil || get.Summary != "Get a user" || len(get.Tags) != 1 {
+		t.Fatalf("unexpected operation: %#v", get)
+	}
+
+	id := get.Parameters[0].Schema
+	if id.Type != "integer" || *id.Minimum != 1 || *id.Maximum != 100 {
+		t.Fatalf("unexpected id parameter schema: %#v", id)
+	}
+
+	if ref := get.Responses["200"].Content["application/json"].Schema.Ref; ref != "#/components/schemas/user" {
+		t.Fatalf("unexpected response schema reference: %s", ref)
+	}
+	if get.Responses["404"].Content != nil {
+		t.Fatalf("expected the 404 response to be without body")
+	}
+
+	userSchema := doc.Components.Schemas["user"]
+	if expected, got := 6, len(userSchema.Properties); expected != got {
+		t.Fatalf("expected %d properties but got %d", expected, got)
+	}
+	if expected, got := 4, len(userSchema.Required); expected != got {
+		t.Fatalf("expected %d required properties but got %d: %v", expected, got, userSchema.Required)
+	}
+	if f := userSchema.Properties["created_at"]; f.Type != "string" || f.Format != "date-time" {
+		t.Fatalf("unexpected created_at schema: %#v", f)
+	}
+	if f := userSchema.Properties["friends"]; f.Items.Ref != "#/components/schemas/user" {
+		t.Fatalf("unexpected friends schema: %#v", f)
+	}
+
+	params := doc.Paths["/users/{name}/{day}"].Get.Parameters
+	if params[0].Schema.Pattern != "^[a-z]+$" || params[1].Schema.Format != "date" {
+		t.Fatalf("unexpected parameters: %#v %#v", params[0].Schema, params[1].Schema)
+	}
+
+	if doc.Paths["/users"].Post.RequestBody == nil {
+		t.Fatalf("expected a request body")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	app := siris.New()
+	app.Get("/ping", func(ctx context.Context) {})
+	openapi.Register(app, openapi.Config{Path: "/spec.json"})
+
+	e := httptest.New(t, app)
+	obj := e.GET("/spec.json").Expect().Status(siris.StatusOK).JSON().Object()
+	obj.Value("openapi").Equal(openapi.Version)
+	obj.Value("paths").Object().Keys().ContainsOnly("/ping")
+}
+
+func TestRegisterParty(t *testing.T) {
+	app := siris.New()
+	api := app.Party("/api")
+	api.Get("/ping", func(ctx context.Context) {})
+	// a route of the root at the same relative path is part of the document.
+	app.Get("/spec.json", func(ctx context.Context) {})
+	openapi.Register(api, openapi.Config{Path: "/spec.json"})
+
+	e := httptest.New(t, app)
+	e.GET("/api/spec.json").Expect().Status(siris.StatusOK).JSON().Object().
+		Value("paths").Object().Keys().ContainsOnly("/api/ping", "/spec.json")
+}
+
+// routesOnly registers the routes but doesn't provide them.
+type routesOnly struct{}
+
+func (routesOnly) Get(path string, handlers ...context.Handler) *router.Route {
+	return &router.Route{Path: path}
+}
+
+func TestRegisterWithoutRoutesProvider(t *testing.T) {
+	if r := openapi.Register(routesOnly{}, openapi.Config{}); r != nil {
+		t.Fatalf("expected no route without the routes provider but got %s", r.Path)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"github.com/go-siris/siris/core/router"
+)
+
+// Summary sets the summary of the route's operation.
+func Summary(summary string) router.RouteOption {
+	return func(r *router.Route) {
+		r.Doc.Summary = summary
+	}
+}
+
+// Description sets the description of the route's operation.
+func Description(description string) router.RouteOption {
+	return func(r *router.Route) {
+		r.Doc.Description = description
+	}
+}
+
+// Tags adds tags to the route's operation, used to group operations.
+func Tags(tags ...string) router.RouteOption {
+	return func(r *router.Route) {
+		r.Doc.Tags = append(r.Doc.Tags, tags...)
+	}
+}
+
+// Request sets the request body's type of the route,
+// "v" is a value of that type, i.e User{}.
+func Request(v interface{}) router.RouteOption {
+	return func(r *router.Route) {
+		r.Doc.Request = v
+	}
+}
+
+// Returns adds a response of the route,
+// "v" is a value of the response body's type, i.e User{}, or nil for an empty body.
+func Returns(statusCode int, v interface{}) router.RouteOption {
+	return func(r *router.Route) {
+		if r.Doc.Responses == nil {
+			r.Doc.Responses = make(map[int]interface{})
+		}
+		r.Doc.Responses[statusCode] = v
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-siris/siris/core/router/macro"
+	"github.com/go-siris/siris/core/router/macro/interpreter/ast"
+	"github.com/go-siris/siris/core/router/macro/interpreter/parser"
+)
+
+// convertTmpl converts the route's template to an OpenAPI path,
+// i.e /users/{id:int min(1)} to /users/{id},
+// and returns its path parameters.
+func convertTmpl(tmpl macro.Template) (string, []Parameter) {
+	path := tmpl.Src
+	var params []Parameter
+
+	for _, p := range tmpl.Params {
+		path = strings.Replace(path, p.Src, "{"+p.Name+"}", 1)
+		params = append(params, Parameter{
+			Name:     p.Name,
+			In:       "path",
+			Required: true,
+			Schema:   paramSchema(p),
+		})
+	}
+
+	return path, params
+}
+
+// paramSchema returns the schema of a path parameter based on its type and its functions,
+// i.e {id:int min(1) max(5)} is an integer with a minimum of 1 and a maximum of 5.
+func paramSchema(p macro.TemplateParam) *Schema {
+	var s *Schema
+	numeric := false
+
+	switch p.Type {
+	case ast.ParamTypeInt:
+		s = &Schema{Type: "integer"}
+		numeric = true
+	case ast.ParamTypeInt64, ast.ParamTypeUint64:
+		s = &Schema{Type: "integer", Format: "int64"}
+		numeric = true
+		if p.Type == ast.ParamTypeUint64 {
+			s.Minimum = float64Ptr(0)
+		}
+	case ast.ParamTypeBool:
+		s = &Schema{Type: "boolean"}
+	case ast.ParamTypeUUID:
+		s = &Schema{Type: "string", Format: "uuid"}
+	case ast.ParamTypeDate:
+		s = &Schema{Type: "string", Format: "date"}
+	case ast.ParamTypeAlphabetical:
+		s = &Schema{Type: "string", Pattern: "^[a-zA-Z ]+$"}
+	case ast.ParamTypeFile:
+		s = &Schema{Type: "string", Pattern: "^[a-zA-Z0-9_.-]*$"}
+	default: // string, path and the custom types.
+		s = &Schema{Type: "string"}
+	}
+
+	// the template keeps only the functions' evaluators,
+	// parse the source again to get their names and arguments.
+	stmt, err := parser.NewParamParser(p.Src).Parse()
+	if err != nil {
+		return s
+	}
+
+	for _, fn := range stmt.Funcs {
+		switch fn.Name {
+		case "min", "max", "range":
+			var args []int
+			for _, a := range fn.Args {
+				n, err := ast.ParamFuncArgToInt(a)
+				if err != nil {
+					break
+				}
+				args = append(args, n)
+			}
+			if len(args) != len(fn.Args) {
+				continue
+			}
+
+			var min, max *int
+			switch {
+			case fn.Name == "min" && len(args) == 1:
+				min = &args[0]
+			case fn.Name == "max" && len(args) == 1:
+				max = &args[0]
+			case fn.Name == "range" && len(args) == 2:
+				min, max = &args[0], &args[1]
+			}
+
+			if numeric {
+				if min != nil {
+					s.Minimum = float64Ptr(float64(*min))
+				}
+				if max != nil {
+					s.Maximum = float64Ptr(float64(*max))
+				}
+			} else {
+				if min != nil {
+					s.MinLength = min
+				}
+				if max != nil {
+					s.MaxLength = max
+				}
+			}
+		case "regexp":
+			if len(fn.Args) == 1 {
+				if expr, ok := fn.Args[0].(string); ok {
+					s.Pattern = expr
+				}
+			}
+		case "prefix":
+			if len(fn.Args) == 1 {
+				if prefix, ok := fn.Args[0].(string); ok {
+					s.Pattern = "^" + regexp.QuoteMeta(prefix)
+				}
+			}
+		case "suffix":
+			if len(fn.Args) == 1 {
+				if suffix, ok := fn.Args[0].(string); ok {
+					s.Pattern = regexp.QuoteMeta(suffix) + "$"
+				}
+			}
+		case "contains":
+			if len(fn.Args) == 1 {
+				if sub, ok := fn.Args[0].(string); ok {
+					s.Pattern = regexp.QuoteMeta(sub)
+				}
+			}
+		}
+	}
+
+	return s
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,169 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is the JSON schema subset of the OpenAPI 3 specification
+// which describes the path parameters and the bodies.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemaRegistry keeps the schemas of the named struct types,
+// they're referenced by the operations through the document's components.
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	types   map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		schemas: make(map[string]*Schema),
+		types:   make(map[reflect.Type]string),
+	}
+}
+
+// schemaOf returns the schema of the "v"'s type.
+func (reg *schemaRegistry) schemaOf(v interface{}) *Schema {
+	return reg.schemaOfType(reflect.TypeOf(v))
+}
+
+func (reg *schemaRegistry) schemaOfType(typ reflect.Type) *Schema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			// encoded as base64 by encoding/json.
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: reg.schemaOfType(typ.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: reg.schemaOfType(typ.Elem())}
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return reg.structSchema(typ)
+		}
+
+		name, ok := reg.types[typ]
+		if !ok {
+			name = reg.uniqueName(typ)
+			reg.types[typ] = name
+			// register before the fields, recursive types reference themselves.
+			reg.schemas[name] = nil
+			reg.schemas[name] = reg.structSchema(typ)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		// interfaces, custom json marshalers and the rest,
+		// any value is allowed.
+		return &Schema{}
+	}
+}
+
+func (reg *schemaRegistry) uniqueName(typ reflect.Type) string {
+	name := typ.Name()
+	if _, exists := reg.schemas[name]; !exists {
+		return name
+	}
+
+	// same name in different packages.
+	return strings.Replace(typ.PkgPath(), "/", ".", -1) + "." + name
+}
+
+func (reg *schemaRegistry) structSchema(typ reflect.Type) *Schema {
+	if reflect.PtrTo(typ).Implements(marshalerType) {
+		return &Schema{}
+	}
+
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for i, n := 0, typ.NumField(); i < n; i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" && !field.Anonymous { // unexported.
+			continue
+		}
+
+		name := field.Name
+		omitempty := false
+		if tag := field.Tag.Get("json"); tag != "" {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				if opt == "omitempty" {
+					omitempty = true
+				}
+			}
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+			omitempty = true // nil pointers are allowed.
+		}
+
+		if field.Anonymous && fieldType.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			// embedded struct's fields are promoted.
+			embedded := reg.structSchema(fieldType)
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+
+		s.Properties[name] = reg.schemaOfType(fieldType)
+		if !omitempty {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return s
+}
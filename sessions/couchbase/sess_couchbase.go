@@ -42,7 +42,7 @@ import (
 	"github.com/go-siris/siris/sessions"
 )
 
-var couchbpder = &Provider{}
+var couchbpder = &Provider{serializer: sessions.DefaultSerializer}
 
 // SessionStore store each session
 type SessionStore struct {
@@ -60,6 +60,12 @@ type Provider struct {
 	pool        string
 	bucket      string
 	b           *couchbase.Bucket
+	serializer  sessions.Serializer
+}
+
+// SetSerializer sets the serializer of the saved session values.
+func (cp *Provider) SetSerializer(s sessions.Serializer) {
+	cp.serializer = sessions.SerializerOrDefault(s)
 }
 
 // Set value to couchabse session
@@ -96,16 +102,37 @@ func (cs *SessionStore) Flush() error {
 	return nil
 }
 
+// Visit calls the visitor for each session value.
+func (cs *SessionStore) Visit(visitor func(key, value interface{})) {
+	cs.lock.RLock()
+	values := make(map[interface{}]interface{}, len(cs.values))
+	for k, v := range cs.values {
+		values[k] = v
+	}
+	cs.lock.RUnlock()
+
+	for k, v := range values {
+		visitor(k, v)
+	}
+}
+
 // SessionID Get couchbase session store id
 func (cs *SessionStore) SessionID() string {
 	return cs.sid
 }
 
+// Close releases the bucket without saving the values,
+// it's called instead of the SessionRelease when the values were not modified.
+func (cs *SessionStore) Close() error {
+	cs.b.Close()
+	return nil
+}
+
 // SessionRelease Write couchbase session with Gob string
 func (cs *SessionStore) SessionRelease(w http.ResponseWriter) {
 	defer cs.b.Close()
 
-	bo, err := sessions.EncodeGob(cs.values)
+	bo, err := couchbpder.serializer.Serialize(cs.values)
 	if err != nil {
 		return
 	}
@@ -167,7 +194,7 @@ func (cp *Provider) SessionRead(sid string) (sessions.Store, error) {
 	} else if doc == nil {
 		kv = make(map[interface{}]interface{})
 	} else {
-		kv, err = sessions.DecodeGob(doc)
+		kv, err = cp.serializer.Deserialize(doc)
 		if err != nil {
 			return nil, err
 		}
@@ -214,7 +241,7 @@ func (cp *Provider) SessionRegenerate(oldsid, sid string) (sessions.Store, error
 	if doc == nil {
 		kv = make(map[interface{}]interface{})
 	} else {
-		kv, err = sessions.DecodeGob(doc)
+		kv, err = cp.serializer.Deserialize(doc)
 		if err != nil {
 			return nil, err
 		}
@@ -13,7 +13,7 @@ import (
 )
 
 var (
-	ledispder = &Provider{}
+	ledispder = &Provider{serializer: sessions.DefaultSerializer}
 	c         *ledis.DB
 )
 
@@ -59,6 +59,20 @@ func (ls *SessionStore) Flush() error {
 	return nil
 }
 
+// Visit calls the visitor for each session value.
+func (ls *SessionStore) Visit(visitor func(key, value interface{})) {
+	ls.lock.RLock()
+	values := make(map[interface{}]interface{}, len(ls.values))
+	for k, v := range ls.values {
+		values[k] = v
+	}
+	ls.lock.RUnlock()
+
+	for k, v := range values {
+		visitor(k, v)
+	}
+}
+
 // SessionID get ledis session id
 func (ls *SessionStore) SessionID() string {
 	return ls.sid
@@ -66,7 +80,7 @@ func (ls *SessionStore) SessionID() string {
 
 // SessionRelease save session values to ledis
 func (ls *SessionStore) SessionRelease(w http.ResponseWriter) {
-	b, err := sessions.EncodeGob(ls.values)
+	b, err := ledispder.serializer.Serialize(ls.values)
 	if err != nil {
 		return
 	}
@@ -79,6 +93,12 @@ type Provider struct {
 	maxlifetime int64
 	savePath    string
 	db          int
+	serializer  sessions.Serializer
+}
+
+// SetSerializer sets the serializer of the saved session values.
+func (lp *Provider) SetSerializer(s sessions.Serializer) {
+	lp.serializer = sessions.SerializerOrDefault(s)
 }
 
 // SessionInit init ledis session
@@ -121,7 +141,7 @@ func (lp *Provider) SessionRead(sid string) (sessions.Store, error) {
 	if len(kvs) == 0 {
 		kv = make(map[interface{}]interface{})
 	} else {
-		if kv, err = sessions.DecodeGob(kvs); err != nil {
+		if kv, err = lp.serializer.Deserialize(kvs); err != nil {
 			return nil, err
 		}
 	}
@@ -42,7 +42,7 @@ import (
 	"github.com/bradfitz/gomemcache/memcache"
 )
 
-var mempder = &MemProvider{}
+var mempder = &MemProvider{serializer: sessions.DefaultSerializer}
 var client *memcache.Client
 
 // SessionStore memcache session store
@@ -87,6 +87,20 @@ func (rs *SessionStore) Flush() error {
 	return nil
 }
 
+// Visit calls the visitor for each session value.
+func (rs *SessionStore) Visit(visitor func(key, value interface{})) {
+	rs.lock.RLock()
+	values := make(map[interface{}]interface{}, len(rs.values))
+	for k, v := range rs.values {
+		values[k] = v
+	}
+	rs.lock.RUnlock()
+
+	for k, v := range values {
+		visitor(k, v)
+	}
+}
+
 // SessionID get memcache session id
 func (rs *SessionStore) SessionID() string {
 	return rs.sid
@@ -94,7 +108,7 @@ func (rs *SessionStore) SessionID() string {
 
 // SessionRelease save session values to memcache
 func (rs *SessionStore) SessionRelease(w http.ResponseWriter) {
-	b, err := sessions.EncodeGob(rs.values)
+	b, err := mempder.serializer.Serialize(rs.values)
 	if err != nil {
 		return
 	}
@@ -108,6 +122,12 @@ type MemProvider struct {
 	conninfo    []string
 	poolsize    int
 	password    string
+	serializer  sessions.Serializer
+}
+
+// SetSerializer sets the serializer of the saved session values.
+func (rp *MemProvider) SetSerializer(s sessions.Serializer) {
+	rp.serializer = sessions.SerializerOrDefault(s)
 }
 
 // SessionInit init memcache session
@@ -136,7 +156,7 @@ func (rp *MemProvider) SessionRead(sid string) (sessions.Store, error) {
 	if len(item.Value) == 0 {
 		kv = make(map[interface{}]interface{})
 	} else {
-		kv, err = sessions.DecodeGob(item.Value)
+		kv, err = rp.serializer.Deserialize(item.Value)
 		if err != nil {
 			return nil, err
 		}
@@ -187,7 +207,7 @@ func (rp *MemProvider) SessionRegenerate(oldsid, sid string) (sessions.Store, er
 		kv = make(map[interface{}]interface{})
 	} else {
 		var err error
-		kv, err = sessions.DecodeGob(contain)
+		kv, err = rp.serializer.Deserialize(contain)
 		if err != nil {
 			return nil, err
 		}
@@ -41,6 +41,7 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 	"sync"
@@ -54,7 +55,7 @@ import (
 var (
 	// TableName store the session in MySQL
 	TableName = "session"
-	mysqlpder = &Provider{}
+	mysqlpder = &Provider{serializer: sessions.DefaultSerializer}
 )
 
 // SessionStore mysql session store
@@ -100,36 +101,69 @@ func (st *SessionStore) Flush() error {
 	return nil
 }
 
+// Visit calls the visitor for each session value.
+func (st *SessionStore) Visit(visitor func(key, value interface{})) {
+	st.lock.RLock()
+	values := make(map[interface{}]interface{}, len(st.values))
+	for k, v := range st.values {
+		values[k] = v
+	}
+	st.lock.RUnlock()
+
+	for k, v := range values {
+		visitor(k, v)
+	}
+}
+
 // SessionID get session id of this mysql session store
 func (st *SessionStore) SessionID() string {
 	return st.sid
 }
 
+// Close releases the database connection without saving the values,
+// it's called instead of the SessionRelease when the values were not modified.
+func (st *SessionStore) Close() error {
+	return st.c.Close()
+}
+
 // SessionRelease save mysql session values to database.
 // must call this method to save values to database.
 func (st *SessionStore) SessionRelease(w http.ResponseWriter) {
+	if err := st.Release(context.Background(), w); err != nil {
+		sessions.SLogger.Println(err)
+	}
+}
+
+// Release saves the mysql session values to database and returns the error, if any.
+// The database connection is released.
+func (st *SessionStore) Release(ctx context.Context, w http.ResponseWriter) error {
 	defer st.c.Close()
-	b, err := sessions.EncodeGob(st.values)
+	st.lock.RLock()
+	b, err := mysqlpder.serializer.Serialize(st.values)
+	st.lock.RUnlock()
 	if err != nil {
-		return
+		return err
 	}
-	st.c.Exec("UPDATE "+TableName+" set `session_data`=?, `session_expiry`=? where session_key=?",
+	_, err = st.c.ExecContext(ctx, "UPDATE "+TableName+" set `session_data`=?, `session_expiry`=? where session_key=?",
 		b, time.Now().Unix(), st.sid)
+	return err
 }
 
 // Provider mysql session provider
 type Provider struct {
 	maxlifetime int64
 	savePath    string
+	serializer  sessions.Serializer
+}
+
+// SetSerializer sets the serializer of the saved session values.
+func (mp *Provider) SetSerializer(s sessions.Serializer) {
+	mp.serializer = sessions.SerializerOrDefault(s)
 }
 
 // connect to mysql
-func (mp *Provider) connectInit() *sql.DB {
-	db, e := sql.Open("mysql", mp.savePath)
-	if e != nil {
-		return nil
-	}
-	return db
+func (mp *Provider) connectInit() (*sql.DB, error) {
+	return sql.Open("mysql", mp.savePath)
 }
 
 // SessionInit init mysql sessions.
@@ -140,22 +174,41 @@ func (mp *Provider) SessionInit(maxlifetime int64, savePath string) error {
 	return nil
 }
 
+// Init implements the sessions.ProviderV2, see `SessionInit`.
+func (mp *Provider) Init(ctx context.Context, maxlifetime int64, savePath string) error {
+	return mp.SessionInit(maxlifetime, savePath)
+}
+
 // SessionRead get mysql session by sid
 func (mp *Provider) SessionRead(sid string) (sessions.Store, error) {
-	c := mp.connectInit()
-	row := c.QueryRow("select session_data from "+TableName+" where session_key=?", sid)
+	return mp.Read(context.Background(), sid)
+}
+
+// Read gets the mysql session by sid, it inserts a new one if not exists.
+func (mp *Provider) Read(ctx context.Context, sid string) (sessions.Store, error) {
+	c, err := mp.connectInit()
+	if err != nil {
+		return nil, err
+	}
+	row := c.QueryRowContext(ctx, "select session_data from "+TableName+" where session_key=?", sid)
 	var sessiondata []byte
-	err := row.Scan(&sessiondata)
+	err = row.Scan(&sessiondata)
 	if err == sql.ErrNoRows {
-		c.Exec("insert into "+TableName+"(`session_key`,`session_data`,`session_expiry`) values(?,?,?)",
+		_, err = c.ExecContext(ctx, "insert into "+TableName+"(`session_key`,`session_data`,`session_expiry`) values(?,?,?)",
 			sid, "", time.Now().Unix())
 	}
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
 	var kv map[interface{}]interface{}
 	if len(sessiondata) == 0 {
 		kv = make(map[interface{}]interface{})
 	} else {
-		kv, err = sessions.DecodeGob(sessiondata)
+		kv, err = mp.serializer.Deserialize(sessiondata)
 		if err != nil {
+			c.Close()
 			return nil, err
 		}
 	}
@@ -165,30 +218,59 @@ func (mp *Provider) SessionRead(sid string) (sessions.Store, error) {
 
 // SessionExist check mysql session exist
 func (mp *Provider) SessionExist(sid string) bool {
-	c := mp.connectInit()
+	exists, _ := mp.Exists(context.Background(), sid)
+	return exists
+}
+
+// Exists checks if the mysql session of the sid exists.
+func (mp *Provider) Exists(ctx context.Context, sid string) (bool, error) {
+	c, err := mp.connectInit()
+	if err != nil {
+		return false, err
+	}
 	defer c.Close()
-	row := c.QueryRow("select session_data from "+TableName+" where session_key=?", sid)
+	row := c.QueryRowContext(ctx, "select session_data from "+TableName+" where session_key=?", sid)
 	var sessiondata []byte
-	err := row.Scan(&sessiondata)
-	return !(err == sql.ErrNoRows)
+	err = row.Scan(&sessiondata)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
 }
 
 // SessionRegenerate generate new sid for mysql session
 func (mp *Provider) SessionRegenerate(oldsid, sid string) (sessions.Store, error) {
-	c := mp.connectInit()
-	row := c.QueryRow("select session_data from "+TableName+" where session_key=?", oldsid)
+	return mp.Regenerate(context.Background(), oldsid, sid)
+}
+
+// Regenerate moves the mysql session of the oldsid to the sid.
+func (mp *Provider) Regenerate(ctx context.Context, oldsid, sid string) (sessions.Store, error) {
+	c, err := mp.connectInit()
+	if err != nil {
+		return nil, err
+	}
+	row := c.QueryRowContext(ctx, "select session_data from "+TableName+" where session_key=?", oldsid)
 	var sessiondata []byte
-	err := row.Scan(&sessiondata)
+	err = row.Scan(&sessiondata)
 	if err == sql.ErrNoRows {
-		c.Exec("insert into "+TableName+"(`session_key`,`session_data`,`session_expiry`) values(?,?,?)", oldsid, "", time.Now().Unix())
+		_, err = c.ExecContext(ctx, "insert into "+TableName+"(`session_key`,`session_data`,`session_expiry`) values(?,?,?)",
+			oldsid, "", time.Now().Unix())
+	}
+	if err == nil {
+		_, err = c.ExecContext(ctx, "update "+TableName+" set `session_key`=? where session_key=?", sid, oldsid)
+	}
+	if err != nil {
+		c.Close()
+		return nil, err
 	}
-	c.Exec("update "+TableName+" set `session_key`=? where session_key=?", sid, oldsid)
+
 	var kv map[interface{}]interface{}
 	if len(sessiondata) == 0 {
 		kv = make(map[interface{}]interface{})
 	} else {
-		kv, err = sessions.DecodeGob(sessiondata)
+		kv, err = mp.serializer.Deserialize(sessiondata)
 		if err != nil {
+			c.Close()
 			return nil, err
 		}
 	}
@@ -196,31 +278,63 @@ func (mp *Provider) SessionRegenerate(oldsid, sid string) (sessions.Store, error
 	return rs, nil
 }
 
+// exec runs a statement, which doesn't return rows, on a new connection.
+func (mp *Provider) exec(ctx context.Context, query string, args ...interface{}) error {
+	c, err := mp.connectInit()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	_, err = c.ExecContext(ctx, query, args...)
+	return err
+}
+
 // SessionDestroy delete mysql session by sid
 func (mp *Provider) SessionDestroy(sid string) error {
-	c := mp.connectInit()
-	c.Exec("DELETE FROM "+TableName+" where session_key=?", sid)
-	c.Close()
-	return nil
+	return mp.Destroy(context.Background(), sid)
+}
+
+// Destroy deletes the mysql session by sid.
+func (mp *Provider) Destroy(ctx context.Context, sid string) error {
+	return mp.exec(ctx, "DELETE FROM "+TableName+" where session_key=?", sid)
+}
+
+// Touch updates the expiry of the mysql session by sid.
+func (mp *Provider) Touch(ctx context.Context, sid string) error {
+	return mp.exec(ctx, "UPDATE "+TableName+" set `session_expiry`=? where session_key=?", time.Now().Unix(), sid)
 }
 
 // SessionGC delete expired values in mysql session
 func (mp *Provider) SessionGC() {
-	c := mp.connectInit()
-	c.Exec("DELETE from "+TableName+" where session_expiry < ?", time.Now().Unix()-mp.maxlifetime)
-	c.Close()
+	if err := mp.GC(context.Background()); err != nil {
+		sessions.SLogger.Println(err)
+	}
+}
+
+// GC deletes the expired mysql sessions.
+func (mp *Provider) GC(ctx context.Context) error {
+	return mp.exec(ctx, "DELETE from "+TableName+" where session_expiry < ?", time.Now().Unix()-mp.maxlifetime)
 }
 
 // SessionAll count values in mysql session
 func (mp *Provider) SessionAll() int {
-	c := mp.connectInit()
+	total, _ := mp.Count(context.Background())
+	return total
+}
+
+// Count counts the mysql sessions.
+func (mp *Provider) Count(ctx context.Context) (int, error) {
+	c, err := mp.connectInit()
+	if err != nil {
+		return 0, err
+	}
 	defer c.Close()
 	var total int
-	err := c.QueryRow("SELECT count(*) as num from " + TableName).Scan(&total)
+	err = c.QueryRowContext(ctx, "SELECT count(*) as num from "+TableName).Scan(&total)
 	if err != nil {
-		return 0
+		return 0, err
 	}
-	return total
+	return total, nil
 }
 
 func init() {
@@ -51,6 +51,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 	"sync"
@@ -61,7 +62,7 @@ import (
 	_ "github.com/lib/pq"
 )
 
-var postgresqlpder = &Provider{}
+var postgresqlpder = &Provider{serializer: sessions.DefaultSerializer}
 
 // SessionStore postgresql session store
 type SessionStore struct {
@@ -106,37 +107,69 @@ func (st *SessionStore) Flush() error {
 	return nil
 }
 
+// Visit calls the visitor for each session value.
+func (st *SessionStore) Visit(visitor func(key, value interface{})) {
+	st.lock.RLock()
+	values := make(map[interface{}]interface{}, len(st.values))
+	for k, v := range st.values {
+		values[k] = v
+	}
+	st.lock.RUnlock()
+
+	for k, v := range values {
+		visitor(k, v)
+	}
+}
+
 // SessionID get session id of this postgresql session store
 func (st *SessionStore) SessionID() string {
 	return st.sid
 }
 
+// Close releases the database connection without saving the values,
+// it's called instead of the SessionRelease when the values were not modified.
+func (st *SessionStore) Close() error {
+	return st.c.Close()
+}
+
 // SessionRelease save postgresql session values to database.
 // must call this method to save values to database.
 func (st *SessionStore) SessionRelease(w http.ResponseWriter) {
+	if err := st.Release(context.Background(), w); err != nil {
+		sessions.SLogger.Println(err)
+	}
+}
+
+// Release saves the postgresql session values to database and returns the error, if any.
+// The database connection is released.
+func (st *SessionStore) Release(ctx context.Context, w http.ResponseWriter) error {
 	defer st.c.Close()
-	b, err := sessions.EncodeGob(st.values)
+	st.lock.RLock()
+	b, err := postgresqlpder.serializer.Serialize(st.values)
+	st.lock.RUnlock()
 	if err != nil {
-		return
+		return err
 	}
-	st.c.Exec("UPDATE session set session_data=$1, session_expiry=$2 where session_key=$3",
+	_, err = st.c.ExecContext(ctx, "UPDATE session set session_data=$1, session_expiry=$2 where session_key=$3",
 		b, time.Now().Format(time.RFC3339), st.sid)
-
+	return err
 }
 
 // Provider postgresql session provider
 type Provider struct {
 	maxlifetime int64
 	savePath    string
+	serializer  sessions.Serializer
+}
+
+// SetSerializer sets the serializer of the saved session values.
+func (mp *Provider) SetSerializer(s sessions.Serializer) {
+	mp.serializer = sessions.SerializerOrDefault(s)
 }
 
 // connect to postgresql
-func (mp *Provider) connectInit() *sql.DB {
-	db, e := sql.Open("postgres", mp.savePath)
-	if e != nil {
-		return nil
-	}
-	return db
+func (mp *Provider) connectInit() (*sql.DB, error) {
+	return sql.Open("postgres", mp.savePath)
 }
 
 // SessionInit init postgresql sessions.
@@ -147,20 +180,31 @@ func (mp *Provider) SessionInit(maxlifetime int64, savePath string) error {
 	return nil
 }
 
+// Init implements the sessions.ProviderV2, see `SessionInit`.
+func (mp *Provider) Init(ctx context.Context, maxlifetime int64, savePath string) error {
+	return mp.SessionInit(maxlifetime, savePath)
+}
+
 // SessionRead get postgresql session by sid
 func (mp *Provider) SessionRead(sid string) (sessions.Store, error) {
-	c := mp.connectInit()
-	row := c.QueryRow("select session_data from session where session_key=$1", sid)
+	return mp.Read(context.Background(), sid)
+}
+
+// Read gets the postgresql session by sid, it inserts a new one if not exists.
+func (mp *Provider) Read(ctx context.Context, sid string) (sessions.Store, error) {
+	c, err := mp.connectInit()
+	if err != nil {
+		return nil, err
+	}
+	row := c.QueryRowContext(ctx, "select session_data from session where session_key=$1", sid)
 	var sessiondata []byte
-	err := row.Scan(&sessiondata)
+	err = row.Scan(&sessiondata)
 	if err == sql.ErrNoRows {
-		_, err = c.Exec("insert into session(session_key,session_data,session_expiry) values($1,$2,$3)",
+		_, err = c.ExecContext(ctx, "insert into session(session_key,session_data,session_expiry) values($1,$2,$3)",
 			sid, "", time.Now().Format(time.RFC3339))
-
-		if err != nil {
-			return nil, err
-		}
-	} else if err != nil {
+	}
+	if err != nil {
+		c.Close()
 		return nil, err
 	}
 
@@ -168,8 +212,9 @@ func (mp *Provider) SessionRead(sid string) (sessions.Store, error) {
 	if len(sessiondata) == 0 {
 		kv = make(map[interface{}]interface{})
 	} else {
-		kv, err = sessions.DecodeGob(sessiondata)
+		kv, err = mp.serializer.Deserialize(sessiondata)
 		if err != nil {
+			c.Close()
 			return nil, err
 		}
 	}
@@ -179,31 +224,59 @@ func (mp *Provider) SessionRead(sid string) (sessions.Store, error) {
 
 // SessionExist check postgresql session exist
 func (mp *Provider) SessionExist(sid string) bool {
-	c := mp.connectInit()
+	exists, _ := mp.Exists(context.Background(), sid)
+	return exists
+}
+
+// Exists checks if the postgresql session of the sid exists.
+func (mp *Provider) Exists(ctx context.Context, sid string) (bool, error) {
+	c, err := mp.connectInit()
+	if err != nil {
+		return false, err
+	}
 	defer c.Close()
-	row := c.QueryRow("select session_data from session where session_key=$1", sid)
+	row := c.QueryRowContext(ctx, "select session_data from session where session_key=$1", sid)
 	var sessiondata []byte
-	err := row.Scan(&sessiondata)
-	return !(err == sql.ErrNoRows)
+	err = row.Scan(&sessiondata)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
 }
 
 // SessionRegenerate generate new sid for postgresql session
 func (mp *Provider) SessionRegenerate(oldsid, sid string) (sessions.Store, error) {
-	c := mp.connectInit()
-	row := c.QueryRow("select session_data from session where session_key=$1", oldsid)
+	return mp.Regenerate(context.Background(), oldsid, sid)
+}
+
+// Regenerate moves the postgresql session of the oldsid to the sid.
+func (mp *Provider) Regenerate(ctx context.Context, oldsid, sid string) (sessions.Store, error) {
+	c, err := mp.connectInit()
+	if err != nil {
+		return nil, err
+	}
+	row := c.QueryRowContext(ctx, "select session_data from session where session_key=$1", oldsid)
 	var sessiondata []byte
-	err := row.Scan(&sessiondata)
+	err = row.Scan(&sessiondata)
 	if err == sql.ErrNoRows {
-		c.Exec("insert into session(session_key,session_data,session_expiry) values($1,$2,$3)",
+		_, err = c.ExecContext(ctx, "insert into session(session_key,session_data,session_expiry) values($1,$2,$3)",
 			oldsid, "", time.Now().Format(time.RFC3339))
 	}
-	c.Exec("update session set session_key=$1 where session_key=$2", sid, oldsid)
+	if err == nil {
+		_, err = c.ExecContext(ctx, "update session set session_key=$1 where session_key=$2", sid, oldsid)
+	}
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
 	var kv map[interface{}]interface{}
 	if len(sessiondata) == 0 {
 		kv = make(map[interface{}]interface{})
 	} else {
-		kv, err = sessions.DecodeGob(sessiondata)
+		kv, err = mp.serializer.Deserialize(sessiondata)
 		if err != nil {
+			c.Close()
 			return nil, err
 		}
 	}
@@ -211,31 +284,63 @@ func (mp *Provider) SessionRegenerate(oldsid, sid string) (sessions.Store, error
 	return rs, nil
 }
 
+// exec runs a statement, which doesn't return rows, on a new connection.
+func (mp *Provider) exec(ctx context.Context, query string, args ...interface{}) error {
+	c, err := mp.connectInit()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	_, err = c.ExecContext(ctx, query, args...)
+	return err
+}
+
 // SessionDestroy delete postgresql session by sid
 func (mp *Provider) SessionDestroy(sid string) error {
-	c := mp.connectInit()
-	c.Exec("DELETE FROM session where session_key=$1", sid)
-	c.Close()
-	return nil
+	return mp.Destroy(context.Background(), sid)
+}
+
+// Destroy deletes the postgresql session by sid.
+func (mp *Provider) Destroy(ctx context.Context, sid string) error {
+	return mp.exec(ctx, "DELETE FROM session where session_key=$1", sid)
+}
+
+// Touch updates the expiry of the postgresql session by sid.
+func (mp *Provider) Touch(ctx context.Context, sid string) error {
+	return mp.exec(ctx, "UPDATE session set session_expiry=$1 where session_key=$2", time.Now().Format(time.RFC3339), sid)
 }
 
 // SessionGC delete expired values in postgresql session
 func (mp *Provider) SessionGC() {
-	c := mp.connectInit()
-	c.Exec("DELETE from session where EXTRACT(EPOCH FROM (current_timestamp - session_expiry)) > $1", mp.maxlifetime)
-	c.Close()
+	if err := mp.GC(context.Background()); err != nil {
+		sessions.SLogger.Println(err)
+	}
+}
+
+// GC deletes the expired postgresql sessions.
+func (mp *Provider) GC(ctx context.Context) error {
+	return mp.exec(ctx, "DELETE from session where EXTRACT(EPOCH FROM (current_timestamp - session_expiry)) > $1", mp.maxlifetime)
 }
 
 // SessionAll count values in postgresql session
 func (mp *Provider) SessionAll() int {
-	c := mp.connectInit()
+	total, _ := mp.Count(context.Background())
+	return total
+}
+
+// Count counts the postgresql sessions.
+func (mp *Provider) Count(ctx context.Context) (int, error) {
+	c, err := mp.connectInit()
+	if err != nil {
+		return 0, err
+	}
 	defer c.Close()
 	var total int
-	err := c.QueryRow("SELECT count(*) as num from session").Scan(&total)
+	err = c.QueryRowContext(ctx, "SELECT count(*) as num from session").Scan(&total)
 	if err != nil {
-		return 0
+		return 0, err
 	}
-	return total
+	return total, nil
 }
 
 func init() {
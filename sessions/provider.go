@@ -0,0 +1,193 @@
+// Copyright 2017 Go-SIRIS Authors. All Rights Reserved.
+
+package sessions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+type (
+	// ProviderV2 is the context-aware version of the Provider,
+	// its methods receive the request's context, which carries the Manager's ProviderTimeout,
+	// and report the errors of the underline storage, i.e a redis or a database outage.
+	//
+	// Its methods are named differently than the Provider's ones,
+	// therefore a provider can implement both, as the built'n ones do.
+	// Legacy providers are adapted by the `LegacyProvider`.
+	ProviderV2 interface {
+		// Init initializes the provider with the session's max lifetime and the ProviderConfig.
+		Init(ctx context.Context, maxlifetime int64, config string) error
+		// Read returns the store of the "sid", it creates a new one if not exists.
+		Read(ctx context.Context, sid string) (Store, error)
+		// Exists reports whether a store of the "sid" exists.
+		Exists(ctx context.Context, sid string) (bool, error)
+		// Regenerate moves the store of the "oldsid" to the "sid".
+		Regenerate(ctx context.Context, oldsid, sid string) (Store, error)
+		// Destroy removes the store of the "sid".
+		Destroy(ctx context.Context, sid string) error
+		// Touch extends the expiration of the store of the "sid", for a sliding expiry.
+		Touch(ctx context.Context, sid string) error
+		// Count returns the number of the active sessions.
+		Count(ctx context.Context) (int, error)
+		// GC removes the expired stores.
+		GC(ctx context.Context) error
+	}
+
+	// StoreReleaser is implemented by the stores which report the errors of their release,
+	// the `Session#Release` prefers it over the Store's SessionRelease.
+	StoreReleaser interface {
+		Release(ctx context.Context, w http.ResponseWriter) error
+	}
+
+	// UserSessionsProvider is implemented by the providers which keep an index of the sessions of each user,
+	// the memory, the file and the redis ones.
+	// A session is indexed when it's bound to a user by the `Manager#Login`.
+	UserSessionsProvider interface {
+		// BindUser indexes the store's session under the user of the "uid",
+		// the session is removed from the index of its previous user, its current `UserIDKey` value.
+		BindUser(ctx context.Context, store Store, uid string) error
+		// UserSessions returns the existing session ids of the user, ordered by their login, oldest first.
+		UserSessions(ctx context.Context, uid string) ([]string, error)
+		// DestroyUserSessions removes all the sessions of the user.
+		DestroyUserSessions(ctx context.Context, uid string) error
+	}
+)
+
+// UserIDKey is the store's key of the session's user id.
+const UserIDKey = "_siris_user_id"
+
+// ErrNotSupported is returned by the Manager's methods
+// which require an operation that the provider doesn't implement.
+var ErrNotSupported = errors.New("session: operation is not supported by the provider")
+
+// RunContext runs the "fn" and returns its error,
+// or the ctx's error if the ctx is done before the "fn" returns.
+//
+// It's used by the providers whose clients are not context-aware,
+// the "fn" keeps running on the background and its result is ignored.
+func RunContext(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if ctx.Done() == nil {
+		return fn()
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fn()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// LegacyProvider adapts a Provider to the ProviderV2,
+// the calls are bound to the context by the `RunContext`.
+//
+// The `Register` adapts the providers which don't implement the ProviderV2.
+func LegacyProvider(p Provider) ProviderV2 {
+	if v2, ok := p.(ProviderV2); ok {
+		return v2
+	}
+	return &legacyProvider{p}
+}
+
+type legacyProvider struct {
+	p Provider
+}
+
+var _ ProviderV2 = &legacyProvider{}
+
+// SetSerializer sets the serializer of the legacy provider, if it's a SerializerSetter.
+func (l *legacyProvider) SetSerializer(s Serializer) {
+	if setter, ok := l.p.(SerializerSetter); ok {
+		setter.SetSerializer(s)
+	}
+}
+
+func (l *legacyProvider) Init(ctx context.Context, maxlifetime int64, config string) error {
+	return RunContext(ctx, func() error {
+		return l.p.SessionInit(maxlifetime, config)
+	})
+}
+
+func (l *legacyProvider) Read(ctx context.Context, sid string) (Store, error) {
+	var store Store
+	err := RunContext(ctx, func() (err error) {
+		store, err = l.p.SessionRead(sid)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (l *legacyProvider) Exists(ctx context.Context, sid string) (bool, error) {
+	var exists bool
+	err := RunContext(ctx, func() error {
+		exists = l.p.SessionExist(sid)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (l *legacyProvider) Regenerate(ctx context.Context, oldsid, sid string) (Store, error) {
+	var store Store
+	err := RunContext(ctx, func() (err error) {
+		store, err = l.p.SessionRegenerate(oldsid, sid)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (l *legacyProvider) Destroy(ctx context.Context, sid string) error {
+	return RunContext(ctx, func() error {
+		return l.p.SessionDestroy(sid)
+	})
+}
+
+// Touch calls the legacy provider's SessionUpdate, if any, otherwise it does nothing.
+func (l *legacyProvider) Touch(ctx context.Context, sid string) error {
+	updater, ok := l.p.(interface {
+		SessionUpdate(sid string) error
+	})
+	if !ok {
+		return nil
+	}
+	return RunContext(ctx, func() error {
+		return updater.SessionUpdate(sid)
+	})
+}
+
+func (l *legacyProvider) Count(ctx context.Context) (int, error) {
+	var n int
+	err := RunContext(ctx, func() error {
+		n = l.p.SessionAll()
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (l *legacyProvider) GC(ctx context.Context) error {
+	return RunContext(ctx, func() error {
+		l.p.SessionGC()
+		return nil
+	})
+}
@@ -0,0 +1,115 @@
+// Copyright 2017 Go-SIRIS Authors. All Rights Reserved.
+
+package sessions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+)
+
+// slowProvider is a legacy provider whose storage doesn't respond.
+type slowProvider struct {
+	Provider
+	delay time.Duration
+}
+
+func (p *slowProvider) SessionExist(sid string) bool {
+	time.Sleep(p.delay)
+	return false
+}
+
+func TestLegacyProviderContext(t *testing.T) {
+	p := LegacyProvider(&slowProvider{Provider: mempder, delay: time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := p.Exists(ctx, "sid"); err != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline error but got %v", err)
+	}
+
+	// legacy providers which implement the ProviderV2 are not wrapped.
+	if LegacyProvider(mempder) != ProviderV2(mempder) {
+		t.Fatalf("expected the memory provider itself")
+	}
+}
+
+func TestManagerProviderTimeout(t *testing.T) {
+	Register("slow-test", &slowProvider{Provider: mempder, delay: time.Second})
+	manager, err := NewManager("slow-test", &ManagerConfig{
+		CookieName:      "gosessionid",
+		EnableSetCookie: true,
+		Gclifetime:      3600,
+		ProviderTimeout: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: "gosessionid", Value: "0123456789"})
+	if _, err = manager.Start(r.Context(), httptest.NewRecorder(), r); err != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline error but got %v", err)
+	}
+}
+
+func TestManagerUserSessions(t *testing.T) {
+	manager, err := NewManager("memory", &ManagerConfig{CookieName: "gosessionid", Gclifetime: 3600})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	var sids []string
+	for _, uid := range []string{"user-a", "user-b", "user-a"} {
+		r := httptest.NewRequest("GET", "/", nil)
+		store, err := manager.Login(ctx, httptest.NewRecorder(), r, uid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sess := NewSession(store)
+		if uid == "user-a" {
+			sids = append(sids, store.SessionID())
+		}
+		if got := sess.UserID(); got != uid {
+			t.Fatalf("expected user id %q but got %q", uid, got)
+		}
+		if len(sess.Keys()) != 0 {
+			t.Fatalf("expected the user id to be excluded from the keys")
+		}
+	}
+
+	got, err := manager.UserSessions(ctx, "user-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	sort.Strings(sids)
+	if len(got) != 2 || got[0] != sids[0] || got[1] != sids[1] {
+		t.Fatalf("expected the sessions %v but got %v", sids, got)
+	}
+
+	if err = manager.DestroyUserSessions(ctx, "user-a"); err != nil {
+		t.Fatal(err)
+	}
+	for _, sid := range sids {
+		if exists, _ := mempder.Exists(ctx, sid); exists {
+			t.Fatalf("expected the session %s to be destroyed", sid)
+		}
+	}
+	if got, _ = manager.UserSessions(ctx, "user-b"); len(got) != 1 {
+		t.Fatalf("expected the session of the other user to be kept but got %v", got)
+	}
+
+	cookieManager, err := NewManager("cookie", &ManagerConfig{CookieName: "gosessionid", ProviderConfig: `{"cookieName":"gosessionid"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cookieManager.UserSessions(ctx, "user-a"); err != ErrNotSupported {
+		t.Fatalf("expected the not supported error but got %v", err)
+	}
+}
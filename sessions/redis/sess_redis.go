@@ -33,20 +33,26 @@
 package redis
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/garyburd/redigo/redis"
 	"github.com/go-siris/siris/sessions"
 )
 
-var redispder = &Provider{}
+var redispder = &Provider{serializer: sessions.DefaultSerializer}
 
 // MaxPoolSize redis max pool size
 var MaxPoolSize = 100
 
+// UserIndexPrefix is the key prefix of the sorted sets which index the session ids of each user,
+// their scores are the login times.
+var UserIndexPrefix = "siris:user:"
+
 // SessionStore redis session store
 type SessionStore struct {
 	p           *redis.Pool
@@ -90,6 +96,20 @@ func (rs *SessionStore) Flush() error {
 	return nil
 }
 
+// Visit calls the visitor for each session value.
+func (rs *SessionStore) Visit(visitor func(key, value interface{})) {
+	rs.lock.RLock()
+	values := make(map[interface{}]interface{}, len(rs.values))
+	for k, v := range rs.values {
+		values[k] = v
+	}
+	rs.lock.RUnlock()
+
+	for k, v := range values {
+		visitor(k, v)
+	}
+}
+
 // SessionID get redis session id
 func (rs *SessionStore) SessionID() string {
 	return rs.sid
@@ -97,13 +117,28 @@ func (rs *SessionStore) SessionID() string {
 
 // SessionRelease save session values to redis
 func (rs *SessionStore) SessionRelease(w http.ResponseWriter) {
-	b, err := sessions.EncodeGob(rs.values)
+	if err := rs.Release(context.Background(), w); err != nil {
+		sessions.SLogger.Println(err)
+	}
+}
+
+// Release saves the session values to redis and returns the error, if any.
+func (rs *SessionStore) Release(ctx context.Context, w http.ResponseWriter) error {
+	rs.lock.RLock()
+	b, err := redispder.serializer.Serialize(rs.values)
+	uid, _ := rs.values[sessions.UserIDKey].(string)
+	rs.lock.RUnlock()
 	if err != nil {
-		return
+		return err
 	}
-	c := rs.p.Get()
-	defer c.Close()
-	c.Do("SETEX", rs.sid, rs.maxlifetime, string(b))
+	return redispder.do(ctx, func(c redis.Conn) error {
+		if _, err := c.Do("SETEX", rs.sid, rs.maxlifetime, string(b)); err != nil || uid == "" {
+			return err
+		}
+		// the user's index lives as long as its last saved session.
+		_, err := c.Do("EXPIRE", UserIndexPrefix+uid, rs.maxlifetime)
+		return err
+	})
 }
 
 // Provider redis session provider
@@ -114,6 +149,12 @@ type Provider struct {
 	password    string
 	dbNum       int
 	poollist    *redis.Pool
+	serializer  sessions.Serializer
+}
+
+// SetSerializer sets the serializer of the saved session values.
+func (rp *Provider) SetSerializer(s sessions.Serializer) {
+	rp.serializer = sessions.SerializerOrDefault(s)
 }
 
 // SessionInit init redis session
@@ -170,21 +211,47 @@ func (rp *Provider) SessionInit(maxlifetime int64, savePath string) error {
 	return rp.poollist.Get().Err()
 }
 
+// do runs the "fn" with a connection of the pool,
+// it returns the ctx's error if the redis server doesn't respond before the ctx is done.
+func (rp *Provider) do(ctx context.Context, fn func(c redis.Conn) error) error {
+	return sessions.RunContext(ctx, func() error {
+		c := rp.poollist.Get()
+		defer c.Close()
+		return fn(c)
+	})
+}
+
+// Init implements the sessions.ProviderV2, see `SessionInit`.
+func (rp *Provider) Init(ctx context.Context, maxlifetime int64, savePath string) error {
+	return sessions.RunContext(ctx, func() error {
+		return rp.SessionInit(maxlifetime, savePath)
+	})
+}
+
 // SessionRead read redis session by sid
 func (rp *Provider) SessionRead(sid string) (sessions.Store, error) {
-	c := rp.poollist.Get()
-	defer c.Close()
-
-	var kv map[interface{}]interface{}
+	return rp.Read(context.Background(), sid)
+}
 
-	kvs, err := redis.String(c.Do("GET", sid))
-	if err != nil && err != redis.ErrNil {
+// Read reads the redis session by sid, it creates a new one if not exists.
+func (rp *Provider) Read(ctx context.Context, sid string) (sessions.Store, error) {
+	var kvs string
+	err := rp.do(ctx, func(c redis.Conn) (err error) {
+		kvs, err = redis.String(c.Do("GET", sid))
+		if err == redis.ErrNil {
+			err = nil
+		}
+		return
+	})
+	if err != nil {
 		return nil, err
 	}
+
+	var kv map[interface{}]interface{}
 	if len(kvs) == 0 {
 		kv = make(map[interface{}]interface{})
 	} else {
-		if kv, err = sessions.DecodeGob([]byte(kvs)); err != nil {
+		if kv, err = rp.serializer.Deserialize([]byte(kvs)); err != nil {
 			return nil, err
 		}
 	}
@@ -195,50 +262,183 @@ func (rp *Provider) SessionRead(sid string) (sessions.Store, error) {
 
 // SessionExist check redis session exist by sid
 func (rp *Provider) SessionExist(sid string) bool {
-	c := rp.poollist.Get()
-	defer c.Close()
+	exists, _ := rp.Exists(context.Background(), sid)
+	return exists
+}
 
-	if existed, err := redis.Int(c.Do("EXISTS", sid)); err != nil || existed == 0 {
-		return false
+// Exists checks if the redis session of the sid exists.
+func (rp *Provider) Exists(ctx context.Context, sid string) (bool, error) {
+	var existed int
+	err := rp.do(ctx, func(c redis.Conn) (err error) {
+		existed, err = redis.Int(c.Do("EXISTS", sid))
+		return
+	})
+	if err != nil {
+		return false, err
 	}
-	return true
+	return existed != 0, nil
 }
 
 // SessionRegenerate generate new sid for redis session
 func (rp *Provider) SessionRegenerate(oldsid, sid string) (sessions.Store, error) {
-	c := rp.poollist.Get()
-	defer c.Close()
-
-	if existed, _ := redis.Int(c.Do("EXISTS", oldsid)); existed == 0 {
-		// oldsid doesn't exists, set the new sid directly
-		// ignore error here, since if it return error
-		// the existed value will be 0
-		c.Do("SET", sid, "", "EX", rp.maxlifetime)
-	} else {
-		c.Do("RENAME", oldsid, sid)
-		c.Do("EXPIRE", sid, rp.maxlifetime)
+	return rp.Regenerate(context.Background(), oldsid, sid)
+}
+
+// Regenerate renames the redis session of the oldsid to the sid.
+func (rp *Provider) Regenerate(ctx context.Context, oldsid, sid string) (sessions.Store, error) {
+	err := rp.do(ctx, func(c redis.Conn) error {
+		existed, err := redis.Int(c.Do("EXISTS", oldsid))
+		if err != nil {
+			return err
+		}
+		if existed == 0 {
+			// oldsid doesn't exists, set the new sid directly
+			_, err = c.Do("SET", sid, "", "EX", rp.maxlifetime)
+			return err
+		}
+		if _, err = c.Do("RENAME", oldsid, sid); err != nil {
+			return err
+		}
+		_, err = c.Do("EXPIRE", sid, rp.maxlifetime)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-	return rp.SessionRead(sid)
+
+	store, err := rp.Read(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	if uid, ok := store.Get(sessions.UserIDKey).(string); ok {
+		// keep the login time of the old session id.
+		err = rp.do(ctx, func(c redis.Conn) error {
+			score, err := redis.Int64(c.Do("ZSCORE", UserIndexPrefix+uid, oldsid))
+			if err == redis.ErrNil {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			c.Send("MULTI")
+			c.Send("ZREM", UserIndexPrefix+uid, oldsid)
+			c.Send("ZADD", UserIndexPrefix+uid, score, sid)
+			_, err = c.Do("EXEC")
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
 }
 
 // SessionDestroy delete redis session by id
 func (rp *Provider) SessionDestroy(sid string) error {
-	c := rp.poollist.Get()
-	defer c.Close()
+	return rp.Destroy(context.Background(), sid)
+}
 
-	c.Do("DEL", sid)
-	return nil
+// Destroy deletes the redis session by id.
+func (rp *Provider) Destroy(ctx context.Context, sid string) error {
+	return rp.do(ctx, func(c redis.Conn) error {
+		_, err := c.Do("DEL", sid)
+		return err
+	})
+}
+
+// Touch resets the expiration of the redis session by id.
+func (rp *Provider) Touch(ctx context.Context, sid string) error {
+	return rp.do(ctx, func(c redis.Conn) error {
+		_, err := c.Do("EXPIRE", sid, rp.maxlifetime)
+		return err
+	})
+}
+
+// BindUser adds the session of the store to the sorted set of the user of the "uid".
+func (rp *Provider) BindUser(ctx context.Context, store sessions.Store, uid string) error {
+	sid := store.SessionID()
+	prev, _ := store.Get(sessions.UserIDKey).(string)
+	if prev == uid {
+		return nil
+	}
+
+	return rp.do(ctx, func(c redis.Conn) error {
+		c.Send("MULTI")
+		if prev != "" {
+			c.Send("ZREM", UserIndexPrefix+prev, sid)
+		}
+		c.Send("ZADD", UserIndexPrefix+uid, time.Now().UnixNano(), sid)
+		c.Send("EXPIRE", UserIndexPrefix+uid, rp.maxlifetime)
+		_, err := c.Do("EXEC")
+		return err
+	})
+}
+
+// UserSessions returns the session ids of the user's sorted set, oldest login first,
+// the ids whose session is expired or destroyed are removed from the set.
+func (rp *Provider) UserSessions(ctx context.Context, uid string) ([]string, error) {
+	var sids []string
+	err := rp.do(ctx, func(c redis.Conn) error {
+		all, err := redis.Strings(c.Do("ZRANGE", UserIndexPrefix+uid, 0, -1))
+		if err != nil {
+			return err
+		}
+		for _, sid := range all {
+			existed, err := redis.Int(c.Do("EXISTS", sid))
+			if err != nil {
+				return err
+			}
+			if existed == 0 {
+				if _, err = c.Do("ZREM", UserIndexPrefix+uid, sid); err != nil {
+					return err
+				}
+				continue
+			}
+			sids = append(sids, sid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sids, nil
+}
+
+// DestroyUserSessions deletes the redis sessions of the user and its sorted set.
+func (rp *Provider) DestroyUserSessions(ctx context.Context, uid string) error {
+	return rp.do(ctx, func(c redis.Conn) error {
+		sids, err := redis.Strings(c.Do("ZRANGE", UserIndexPrefix+uid, 0, -1))
+		if err != nil {
+			return err
+		}
+		keys := make([]interface{}, 0, len(sids)+1)
+		for _, sid := range sids {
+			keys = append(keys, sid)
+		}
+		keys = append(keys, UserIndexPrefix+uid)
+		_, err = c.Do("DEL", keys...)
+		return err
+	})
 }
 
 // SessionGC Impelment method, no used.
 func (rp *Provider) SessionGC() {
 }
 
+// GC implements the sessions.ProviderV2, the redis expires the sessions.
+func (rp *Provider) GC(ctx context.Context) error {
+	return nil
+}
+
 // SessionAll return all activeSession
 func (rp *Provider) SessionAll() int {
 	return 0
 }
 
+// Count implements the sessions.ProviderV2, the redis sessions are not counted.
+func (rp *Provider) Count(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
 func init() {
 	sessions.Register("redis", redispder)
 }
@@ -0,0 +1,128 @@
+// Copyright 2017 Go-SIRIS Authors. All Rights Reserved.
+
+package sessions
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+type (
+	// Serializer encodes and decodes the session values which are saved by the providers.
+	//
+	// The Gob serializer is the default one,
+	// the JSON and the Msgpack ones can be used when the saved sessions are shared with non-Go services.
+	// Set it by the ManagerConfig's Serializer field.
+	Serializer interface {
+		Serialize(values map[interface{}]interface{}) ([]byte, error)
+		Deserialize(data []byte) (map[interface{}]interface{}, error)
+	}
+
+	// SerializerSetter is implemented by the providers which save the values using a Serializer,
+	// the Manager sets the ManagerConfig's Serializer before the provider's SessionInit.
+	SerializerSetter interface {
+		SetSerializer(Serializer)
+	}
+)
+
+// DefaultSerializer is the serializer which is used when the ManagerConfig's Serializer is nil.
+var DefaultSerializer Serializer = GobSerializer{}
+
+// GobSerializer serializes the session values using the encoding/gob.
+//
+// Custom types are registered to the gob once, on their first serialization.
+type GobSerializer struct{}
+
+var _ Serializer = GobSerializer{}
+
+var gobTypes = struct {
+	sync.RWMutex
+	registered map[reflect.Type]bool
+}{registered: make(map[reflect.Type]bool)}
+
+// registerGob registers the value's type to the gob, if not already registered.
+func registerGob(v interface{}) {
+	typ := reflect.TypeOf(v)
+	if typ == nil {
+		return
+	}
+
+	gobTypes.RLock()
+	registered := gobTypes.registered[typ]
+	gobTypes.RUnlock()
+	if registered {
+		return
+	}
+
+	gobTypes.Lock()
+	if !gobTypes.registered[typ] {
+		gob.Register(v)
+		gobTypes.registered[typ] = true
+	}
+	gobTypes.Unlock()
+}
+
+// Serialize encodes the values to gob.
+func (GobSerializer) Serialize(values map[interface{}]interface{}) ([]byte, error) {
+	for _, v := range values {
+		registerGob(v)
+	}
+	buf := bytes.NewBuffer(nil)
+	if err := gob.NewEncoder(buf).Encode(values); err != nil {
+		return []byte(""), err
+	}
+	return buf.Bytes(), nil
+}
+
+// Deserialize decodes the gob data to values.
+func (GobSerializer) Deserialize(data []byte) (map[interface{}]interface{}, error) {
+	var values map[interface{}]interface{}
+	if err := gob.NewDecoder(bytes.NewBuffer(data)).Decode(&values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// JSONSerializer serializes the session values as a JSON object.
+//
+// The keys are converted to strings and the values are decoded
+// as the encoding/json does for interface{}, i.e numbers are float64.
+type JSONSerializer struct{}
+
+var _ Serializer = JSONSerializer{}
+
+// Serialize encodes the values to a JSON object.
+func (JSONSerializer) Serialize(values map[interface{}]interface{}) ([]byte, error) {
+	obj := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		obj[fmt.Sprint(k)] = v
+	}
+	return json.Marshal(obj)
+}
+
+// Deserialize decodes the JSON object to values, keys are strings.
+func (JSONSerializer) Deserialize(data []byte) (map[interface{}]interface{}, error) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	values := make(map[interface{}]interface{}, len(obj))
+	for k, v := range obj {
+		values[k] = v
+	}
+	return values, nil
+}
+
+// SerializerOrDefault returns the "s" or the DefaultSerializer if nil,
+// it's used by the providers.
+func SerializerOrDefault(s Serializer) Serializer {
+	if s == nil {
+		return DefaultSerializer
+	}
+	return s
+}
@@ -0,0 +1,27 @@
+// Copyright 2017 Go-SIRIS Authors. All Rights Reserved.
+
+package sessions
+
+import (
+	"github.com/go-siris/siris/core/msgpack"
+)
+
+// MsgpackSerializer serializes the session values as a MessagePack map,
+// see https://github.com/msgpack/msgpack/blob/master/spec.md.
+//
+// Keys are encoded as they are, usually strings. Time values are encoded as RFC3339 strings,
+// structs as maps of their exported fields, named by their msgpack or json tags.
+// Integers are decoded as int64 or uint64, nested maps with string keys as map[string]interface{}.
+type MsgpackSerializer struct{}
+
+var _ Serializer = MsgpackSerializer{}
+
+// Serialize encodes the values to a msgpack map.
+func (MsgpackSerializer) Serialize(values map[interface{}]interface{}) ([]byte, error) {
+	return msgpack.Marshal(values)
+}
+
+// Deserialize decodes the msgpack map to values.
+func (MsgpackSerializer) Deserialize(data []byte) (map[interface{}]interface{}, error) {
+	return msgpack.UnmarshalMap(data)
+}
@@ -0,0 +1,76 @@
+// Copyright 2017 Go-SIRIS Authors. All Rights Reserved.
+
+package sessions
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestSerializers(t *testing.T) {
+	values := map[interface{}]interface{}{
+		"username": "astaxie",
+		"age":      -300,
+		"admin":    true,
+		"score":    9.5,
+		"tags":     []string{"a", "b"},
+		flashesKey: map[string]interface{}{"message": "saved"},
+	}
+
+	tests := []struct {
+		name       string
+		serializer Serializer
+		age        interface{}
+		tags       interface{}
+	}{
+		{"gob", GobSerializer{}, -300, []string{"a", "b"}},
+		{"json", JSONSerializer{}, float64(-300), []interface{}{"a", "b"}},
+		{"msgpack", MsgpackSerializer{}, int64(-300), []interface{}{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		b, err := tt.serializer.Serialize(values)
+		if err != nil {
+			t.Fatalf("[%s] serialize: %v", tt.name, err)
+		}
+		got, err := tt.serializer.Deserialize(b)
+		if err != nil {
+			t.Fatalf("[%s] deserialize: %v", tt.name, err)
+		}
+
+		if got["username"] != "astaxie" || got["admin"] != true || got["score"] != 9.5 {
+			t.Fatalf("[%s] unexpected values: %#v", tt.name, got)
+		}
+		if got["age"] != tt.age {
+			t.Fatalf("[%s] expected age %#v but got %#v", tt.name, tt.age, got["age"])
+		}
+		if !reflect.DeepEqual(got["tags"], tt.tags) {
+			t.Fatalf("[%s] expected tags %#v but got %#v", tt.name, tt.tags, got["tags"])
+		}
+		if flashes, ok := got[flashesKey].(map[string]interface{}); !ok || flashes["message"] != "saved" {
+			t.Fatalf("[%s] unexpected flashes: %#v", tt.name, got[flashesKey])
+		}
+	}
+}
+
+func TestMsgpackSerializerFormat(t *testing.T) {
+	b, err := MsgpackSerializer{}.Serialize(map[interface{}]interface{}{"a": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// fixmap of 1, fixstr "a", positive fixint 1.
+	if expected := []byte{0x81, 0xa1, 'a', 0x01}; !bytes.Equal(b, expected) {
+		t.Fatalf("expected %x but got %x", expected, b)
+	}
+
+	if _, err = (MsgpackSerializer{}).Deserialize([]byte{0x91, 0x01}); err == nil {
+		t.Fatalf("expected an error for a non map")
+	}
+
+	// map32 of 2^32-1 entries without any entry.
+	if _, err = (MsgpackSerializer{}).Deserialize([]byte{0xdf, 0xff, 0xff, 0xff, 0xff}); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected the io.ErrUnexpectedEOF for a length larger than the data but got: %v", err)
+	}
+}
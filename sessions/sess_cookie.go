@@ -15,15 +15,15 @@
 package sessions
 
 import (
+	"context"
 	"crypto/aes"
-	"crypto/cipher"
 	"encoding/json"
 	"net/http"
 	"net/url"
 	"sync"
 )
 
-var cookiepder = &CookieProvider{}
+var cookiepder = &CookieProvider{serializer: DefaultSerializer}
 
 // CookieSessionStore Cookie SessionStore
 type CookieSessionStore struct {
@@ -67,48 +67,109 @@ func (st *CookieSessionStore) Flush() error {
 	return nil
 }
 
+// Visit calls the visitor for each session value.
+func (st *CookieSessionStore) Visit(visitor func(key, value interface{})) {
+	st.lock.RLock()
+	values := make(map[interface{}]interface{}, len(st.values))
+	for k, v := range st.values {
+		values[k] = v
+	}
+	st.lock.RUnlock()
+
+	for k, v := range values {
+		visitor(k, v)
+	}
+}
+
 // SessionID Return id of this cookie session
 func (st *CookieSessionStore) SessionID() string {
 	return st.sid
 }
 
-// SessionRelease Write cookie session to http response cookie
+// SessionRelease Write cookie session to http response cookie.
+// The cookie is not written if the encoded values exceed the MaxCookieSize.
 func (st *CookieSessionStore) SessionRelease(w http.ResponseWriter) {
-	encodedCookie, err := encodeCookie(cookiepder.block, cookiepder.config.SecurityKey, cookiepder.config.SecurityName, st.values)
-	if err == nil {
-		cookie := &http.Cookie{Name: cookiepder.config.CookieName,
+	if err := st.Release(context.Background(), w); err != nil {
+		SLogger.Println(err)
+	}
+}
+
+// Release writes the cookie session to the http response cookie,
+// it returns the ErrCookieTooLarge if the encoded values exceed the MaxCookieSize.
+func (st *CookieSessionStore) Release(ctx context.Context, w http.ResponseWriter) error {
+	st.lock.RLock()
+	encodedCookie, err := encodeCookie(cookiepder.ring, cookiepder.serializer, cookiepder.config.SecurityName, st.values)
+	st.lock.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if cookiepder.newCookie == nil {
+		// not used by a Manager.
+		http.SetCookie(w, &http.Cookie{Name: cookiepder.config.CookieName,
 			Value:    url.QueryEscape(encodedCookie),
 			Path:     "/",
 			HttpOnly: true,
 			Domain:   cookiepder.config.Domain,
 			Secure:   cookiepder.config.Secure,
-			MaxAge:   cookiepder.config.Maxage}
-		http.SetCookie(w, cookie)
+			MaxAge:   cookiepder.config.Maxage})
+		return nil
+	}
+
+	// the values replace the session id cookie of the Manager, with the same attributes.
+	cookie := cookiepder.newCookie(encodedCookie)
+	if cookiepder.config.Maxage > 0 {
+		cookie.MaxAge = cookiepder.config.Maxage
 	}
+	http.SetCookie(w, cookie)
+	return nil
+}
+
+// CookieSetter is implemented by the providers which store the session values in the session's cookie,
+// the Manager sets the builder of its cookie, with its name, prefix, path, domain and SameSite,
+// so the cookie of the values replaces the session id one.
+type CookieSetter interface {
+	SetCookie(newCookie func(value string) *http.Cookie)
 }
 
 type cookieConfig struct {
-	SecurityKey  string `json:"securityKey"`
-	BlockKey     string `json:"blockKey"`
-	SecurityName string `json:"securityName"`
-	CookieName   string `json:"cookieName"`
-	Domain       string `json:"domain"`
-	Secure       bool   `json:"secure"`
-	Maxage       int    `json:"maxage"`
+	Keys         []string `json:"keys"`
+	SecurityKey  string   `json:"securityKey"`
+	BlockKey     string   `json:"blockKey"`
+	SecurityName string   `json:"securityName"`
+	CookieName   string   `json:"cookieName"`
+	Domain       string   `json:"domain"`
+	Secure       bool     `json:"secure"`
+	Maxage       int      `json:"maxage"`
 }
 
 // CookieProvider Cookie session provider
 type CookieProvider struct {
 	maxlifetime int64
 	config      *cookieConfig
-	block       cipher.Block
+	ring        *keyRing
+	legacy      *legacyCookieKeys
+	serializer  Serializer
+	newCookie   func(value string) *http.Cookie
+}
+
+// SetCookie sets the builder of the session's cookie, see `CookieSetter`.
+func (pder *CookieProvider) SetCookie(newCookie func(value string) *http.Cookie) {
+	pder.newCookie = newCookie
+}
+
+// SetSerializer sets the serializer of the session values, before their encryption.
+func (pder *CookieProvider) SetSerializer(s Serializer) {
+	pder.serializer = SerializerOrDefault(s)
 }
 
 // SessionInit Init cookie session provider with max lifetime and config json.
 // maxlifetime is ignored.
 // json config:
-// 	securityKey - hash string
-// 	blockKey - gob encode hash string. it's saved as aes crypto.
+// 	keys - the key ring's secrets, the first one encrypts, all of them decrypt.
+// 	       Prepend a new key to rotate, remove the old one when its cookies are expired.
+// 	securityKey - the key if keys is empty, and the legacy hash string.
+// 	blockKey - the legacy aes crypto key, if set then the cookies of the legacy encoding are still decoded.
 // 	securityName - recognized name in encoded cookie string
 // 	cookieName - cookie name
 // 	maxage - cookie max life time.
@@ -118,16 +179,28 @@ func (pder *CookieProvider) SessionInit(maxlifetime int64, config string) error
 	if err != nil {
 		return err
 	}
-	if pder.config.BlockKey == "" {
-		pder.config.BlockKey = string(generateRandomKey(16))
+	keys := pder.config.Keys
+	if len(keys) == 0 {
+		if pder.config.SecurityKey != "" {
+			keys = []string{pder.config.SecurityKey}
+		} else {
+			keys = []string{string(generateRandomKey(32))}
+		}
+	}
+	if pder.ring, err = newKeyRing(keys...); err != nil {
+		return err
+	}
+	pder.legacy = nil
+	if pder.config.BlockKey != "" {
+		block, err := aes.NewCipher([]byte(pder.config.BlockKey))
+		if err != nil {
+			return err
+		}
+		pder.legacy = &legacyCookieKeys{block: block, hashKey: pder.config.SecurityKey}
 	}
 	if pder.config.SecurityName == "" {
 		pder.config.SecurityName = string(generateRandomKey(20))
 	}
-	pder.block, err = aes.NewCipher([]byte(pder.config.BlockKey))
-	if err != nil {
-		return err
-	}
 	pder.maxlifetime = maxlifetime
 	return nil
 }
@@ -135,8 +208,9 @@ func (pder *CookieProvider) SessionInit(maxlifetime int64, config string) error
 // SessionRead Get SessionStore in cooke.
 // decode cooke string to map and put into SessionStore with sid.
 func (pder *CookieProvider) SessionRead(sid string) (Store, error) {
-	maps, _ := decodeCookie(pder.block,
-		pder.config.SecurityKey,
+	maps, _ := decodeCookie(pder.ring,
+		pder.serializer,
+		pder.legacy,
 		pder.config.SecurityName,
 		sid, pder.maxlifetime)
 	if maps == nil {
@@ -175,6 +249,51 @@ func (pder *CookieProvider) SessionUpdate(sid string) error {
 	return nil
 }
 
+// Init implements the ProviderV2, see `SessionInit`.
+func (pder *CookieProvider) Init(ctx context.Context, maxlifetime int64, config string) error {
+	return pder.SessionInit(maxlifetime, config)
+}
+
+// Read implements the ProviderV2, see `SessionRead`.
+func (pder *CookieProvider) Read(ctx context.Context, sid string) (Store, error) {
+	return pder.SessionRead(sid)
+}
+
+// Exists implements the ProviderV2, cookie session is always existed.
+func (pder *CookieProvider) Exists(ctx context.Context, sid string) (bool, error) {
+	return true, nil
+}
+
+// Regenerate decodes the values of the "oldsid" to the store of the new "sid".
+func (pder *CookieProvider) Regenerate(ctx context.Context, oldsid, sid string) (Store, error) {
+	store, err := pder.SessionRead(oldsid)
+	if err != nil {
+		return nil, err
+	}
+	store.(*CookieSessionStore).sid = sid
+	return store, nil
+}
+
+// Destroy implements the ProviderV2, the cookie is removed by the Manager.
+func (pder *CookieProvider) Destroy(ctx context.Context, sid string) error {
+	return nil
+}
+
+// Touch implements the ProviderV2, the cookie's expiration is extended on its release.
+func (pder *CookieProvider) Touch(ctx context.Context, sid string) error {
+	return nil
+}
+
+// Count implements the ProviderV2, the cookie sessions are not counted.
+func (pder *CookieProvider) Count(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// GC implements the ProviderV2, the browsers remove the expired cookies.
+func (pder *CookieProvider) GC(ctx context.Context) error {
+	return nil
+}
+
 func init() {
 	Register("cookie", cookiepder)
 }
@@ -3,9 +3,11 @@
 package sessions
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -170,6 +172,9 @@ func TestSessionCookie(t *testing.T) {
 	cookie = resp.Cookie("gosessionid")
 	cookie.Domain().Equal("example.com")
 	cookie.Path().Equal("/")
+	if sessionID5 == "" {
+		t.Fatal("/session/functions-test: empty session id")
+	}
 	t.Log("/session/functions-test: Test Done")
 
 	t.Log("globalSessions: Test Start")
@@ -182,7 +187,7 @@ func TestSessionCookie(t *testing.T) {
 		t.Fatalf("GetSessionStore returns err, %s", err)
 	}
 	newseddion, _ := globalSessions.sessionID()
-	_, err = globalSessions.provider.SessionRegenerate("notfound1234", newseddion)
+	_, err = globalSessions.provider.Regenerate(context.Background(), "notfound1234", newseddion)
 	if err != nil {
 		t.Fatalf("SessionRegenerate returns err, %s", err)
 	}
@@ -228,3 +233,51 @@ func TestSessionCookiePanic_Manager2(t *testing.T) {
 	}
 	_, _ = NewManager("cookie", conf)
 }
+
+func TestSessionCookieStoreAttributes(t *testing.T) {
+	config := `{"cookieName":"gosessionid","enableSetCookie":true,"gclifetime":600,"secure":true,"sameSite":"Strict","cookiePrefix":"__Host-","providerConfig":"{\"cookieName\":\"gosessionid\",\"securityKey\":\"siriscookiehashkey\"}"}`
+	conf := new(ManagerConfig)
+	if err := json.Unmarshal([]byte(config), conf); err != nil {
+		t.Fatal("json decode error", err)
+	}
+	globalSessions, err := NewManager("cookie", conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	sess, err := globalSessions.SessionStart(w, r)
+	if err != nil {
+		t.Fatal("start error,", err)
+	}
+	sess.Set("username", "astaxie")
+	sess.SessionRelease(w)
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 2 {
+		t.Fatalf("expected the session id and the values cookies but got %d", len(cookies))
+	}
+	// the values cookie replaces the session id one.
+	cookie := cookies[1]
+	if cookie.Name != "__Host-gosessionid" || cookie.Path != "/" || !cookie.Secure || cookie.SameSite != http.SameSiteStrictMode {
+		t.Fatalf("expected the attributes of the manager's cookie but got: %s", cookie)
+	}
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookie)
+	sess, err = globalSessions.SessionStart(httptest.NewRecorder(), r)
+	if err != nil {
+		t.Fatal("start error,", err)
+	}
+	if username := sess.Get("username"); username != "astaxie" {
+		t.Fatalf("expected the value of the released cookie but got: %v", username)
+	}
+}
+
+func TestSessionCookieSameSiteNone(t *testing.T) {
+	conf := &ManagerConfig{CookieName: "gosessionid", SameSite: "None", ProviderConfig: `{"cookieName":"gosessionid"}`}
+	if _, err := NewManager("cookie", conf); err == nil {
+		t.Fatal("expected an error for the None SameSite without the Secure option")
+	}
+}
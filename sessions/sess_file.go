@@ -15,18 +15,22 @@
 package sessions
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
 
 var (
-	filepder      = &FileProvider{}
+	filepder      = &FileProvider{serializer: DefaultSerializer}
 	gcmaxlifetime int64
 )
 
@@ -71,6 +75,20 @@ func (fs *FileSessionStore) Flush() error {
 	return nil
 }
 
+// Visit calls the visitor for each session value.
+func (fs *FileSessionStore) Visit(visitor func(key, value interface{})) {
+	fs.lock.RLock()
+	values := make(map[interface{}]interface{}, len(fs.values))
+	for k, v := range fs.values {
+		values[k] = v
+	}
+	fs.lock.RUnlock()
+
+	for k, v := range values {
+		visitor(k, v)
+	}
+}
+
 // SessionID Get file session store id
 func (fs *FileSessionStore) SessionID() string {
 	return fs.sid
@@ -78,32 +96,39 @@ func (fs *FileSessionStore) SessionID() string {
 
 // SessionRelease Write file session to local file with Gob string
 func (fs *FileSessionStore) SessionRelease(w http.ResponseWriter) {
-	b, err := EncodeGob(fs.values)
-	if err != nil {
+	if err := fs.Release(context.Background(), w); err != nil {
 		SLogger.Println(err)
-		return
+	}
+}
+
+// Release writes the file session to the local file and returns the error, if any.
+func (fs *FileSessionStore) Release(ctx context.Context, w http.ResponseWriter) error {
+	fs.lock.RLock()
+	b, err := filepder.serializer.Serialize(fs.values)
+	fs.lock.RUnlock()
+	if err != nil {
+		return err
 	}
 	_, err = os.Stat(path.Join(filepder.savePath, string(fs.sid[0]), string(fs.sid[1]), fs.sid))
 	var f *os.File
 	if err == nil {
 		f, err = os.OpenFile(path.Join(filepder.savePath, string(fs.sid[0]), string(fs.sid[1]), fs.sid), os.O_RDWR, 0777)
-		if err != nil {
-			SLogger.Println(err)
-			return
-		}
 	} else if os.IsNotExist(err) {
 		f, err = os.Create(path.Join(filepder.savePath, string(fs.sid[0]), string(fs.sid[1]), fs.sid))
-		if err != nil {
-			SLogger.Println(err)
-			return
-		}
-	} else {
-		return
 	}
-	f.Truncate(0)
-	f.Seek(0, 0)
-	f.Write(b)
-	f.Close()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err = f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err = f.Seek(0, 0); err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	return err
 }
 
 // FileProvider File session provider
@@ -111,11 +136,21 @@ type FileProvider struct {
 	lock        sync.RWMutex
 	maxlifetime int64
 	savePath    string
+	serializer  Serializer
+}
+
+// SetSerializer sets the serializer of the saved session values.
+func (fp *FileProvider) SetSerializer(s Serializer) {
+	fp.serializer = SerializerOrDefault(s)
 }
 
 // SessionInit Init file session provider.
 // savePath sets the session files path.
 func (fp *FileProvider) SessionInit(maxlifetime int64, savePath string) error {
+	// the provider is shared by the managers, its GC may be running.
+	filepder.lock.Lock()
+	defer filepder.lock.Unlock()
+
 	fp.maxlifetime = maxlifetime
 	fp.savePath = savePath
 	return nil
@@ -153,7 +188,7 @@ func (fp *FileProvider) SessionRead(sid string) (Store, error) {
 	if len(b) == 0 {
 		kv = make(map[interface{}]interface{})
 	} else {
-		kv, err = DecodeGob(b)
+		kv, err = fp.serializer.Deserialize(b)
 		if err != nil {
 			return nil, err
 		}
@@ -166,42 +201,237 @@ func (fp *FileProvider) SessionRead(sid string) (Store, error) {
 // SessionExist Check file session exist.
 // it checkes the file named from sid exist or not.
 func (fp *FileProvider) SessionExist(sid string) bool {
+	exists, _ := fp.Exists(context.Background(), sid)
+	return exists
+}
+
+// Exists checks if the file named from the sid exists,
+// it returns the error of the stat, if it's not a "not exist" one.
+func (fp *FileProvider) Exists(ctx context.Context, sid string) (bool, error) {
 	filepder.lock.Lock()
 	defer filepder.lock.Unlock()
 
 	_, err := os.Stat(path.Join(fp.savePath, string(sid[0]), string(sid[1]), sid))
-	return err == nil
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
 }
 
 // SessionDestroy Remove all files in this save path
 func (fp *FileProvider) SessionDestroy(sid string) error {
+	return fp.Destroy(context.Background(), sid)
+}
+
+// Destroy removes the file of the sid, a missing file is not an error.
+func (fp *FileProvider) Destroy(ctx context.Context, sid string) error {
 	filepder.lock.Lock()
 	defer filepder.lock.Unlock()
-	os.Remove(path.Join(fp.savePath, string(sid[0]), string(sid[1]), sid))
-	return nil
+	err := os.Remove(path.Join(fp.savePath, string(sid[0]), string(sid[1]), sid))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Touch changes the access and modification times of the file of the sid,
+// the GC removes the files which are not modified for the max lifetime.
+func (fp *FileProvider) Touch(ctx context.Context, sid string) error {
+	filepder.lock.Lock()
+	defer filepder.lock.Unlock()
+	now := time.Now()
+	return os.Chtimes(path.Join(fp.savePath, string(sid[0]), string(sid[1]), sid), now, now)
 }
 
 // SessionGC Recycle files in save path
 func (fp *FileProvider) SessionGC() {
+	if err := fp.GC(context.Background()); err != nil {
+		SLogger.Println(err)
+	}
+}
+
+// GC removes the expired files in the save path.
+func (fp *FileProvider) GC(ctx context.Context) error {
 	filepder.lock.Lock()
 	defer filepder.lock.Unlock()
 
 	gcmaxlifetime = fp.maxlifetime
-	filepath.Walk(fp.savePath, gcpath)
+	return filepath.Walk(fp.savePath, func(path string, info os.FileInfo, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if isUserIndexDir(info) {
+			return filepath.SkipDir
+		}
+		return gcpath(path, info, err)
+	})
 }
 
 // SessionAll Get active file session number.
 // it walks save path to count files.
 func (fp *FileProvider) SessionAll() int {
+	n, err := fp.Count(context.Background())
+	if err != nil {
+		SLogger.Printf("filepath.Walk() returned %v\n", err)
+		return 0
+	}
+	return n
+}
+
+// Count walks the save path to count the session files.
+func (fp *FileProvider) Count(ctx context.Context) (int, error) {
 	a := &activeSession{}
 	err := filepath.Walk(fp.savePath, func(path string, f os.FileInfo, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if isUserIndexDir(f) {
+			return filepath.SkipDir
+		}
 		return a.visit(path, f, err)
 	})
 	if err != nil {
-		SLogger.Printf("filepath.Walk() returned %v\n", err)
-		return 0
+		return 0, err
+	}
+	return a.total, nil
+}
+
+// userIndexDir is the directory, inside the save path, of the index files of the users' sessions.
+const userIndexDir = "users"
+
+func isUserIndexDir(info os.FileInfo) bool {
+	return info != nil && info.IsDir() && info.Name() == userIndexDir
+}
+
+// userIndexFile returns the index file of the user,
+// it contains the session ids of the user, one per line, oldest login first.
+func (fp *FileProvider) userIndexFile(uid string) string {
+	h := sha256.Sum256([]byte(uid))
+	return path.Join(fp.savePath, userIndexDir, hex.EncodeToString(h[:]))
+}
+
+// readUserIndex returns the session ids of the user's index file,
+// the ids whose session file doesn't exist, i.e destroyed or expired, are omitted.
+// The lock should be held.
+func (fp *FileProvider) readUserIndex(uid string) ([]string, error) {
+	b, err := ioutil.ReadFile(fp.userIndexFile(uid))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sids []string
+	for _, sid := range strings.Fields(string(b)) {
+		if _, err = os.Stat(path.Join(fp.savePath, string(sid[0]), string(sid[1]), sid)); err == nil {
+			sids = append(sids, sid)
+		}
+	}
+	return sids, nil
+}
+
+// writeUserIndex writes the session ids to the user's index file,
+// the file is removed if there are no session ids. The lock should be held.
+func (fp *FileProvider) writeUserIndex(uid string, sids []string) error {
+	filename := fp.userIndexFile(uid)
+	if len(sids) == 0 {
+		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(path.Dir(filename), 0777); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, []byte(strings.Join(sids, "\n")+"\n"), 0777)
+}
+
+// replaceUserSession replaces the "oldsid" with the "sid" in the index of the user,
+// an empty "sid" removes the "oldsid". The lock should be held.
+func (fp *FileProvider) replaceUserSession(uid, oldsid, sid string) error {
+	b, err := ioutil.ReadFile(fp.userIndexFile(uid))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	sids := strings.Fields(string(b))
+	for i, s := range sids {
+		if s != oldsid {
+			continue
+		}
+		if sid != "" {
+			sids[i] = sid
+		} else {
+			sids = append(sids[:i], sids[i+1:]...)
+		}
+		break
+	}
+	return fp.writeUserIndex(uid, sids)
+}
+
+// BindUser adds the session of the store to the index file of the user of the "uid".
+func (fp *FileProvider) BindUser(ctx context.Context, store Store, uid string) error {
+	filepder.lock.Lock()
+	defer filepder.lock.Unlock()
+
+	sid := store.SessionID()
+	if prev, ok := store.Get(UserIDKey).(string); ok {
+		if prev == uid {
+			return nil
+		}
+		if err := fp.replaceUserSession(prev, sid, ""); err != nil {
+			return err
+		}
+	}
+
+	sids, err := fp.readUserIndex(uid)
+	if err != nil {
+		return err
+	}
+	return fp.writeUserIndex(uid, append(sids, sid))
+}
+
+// UserSessions returns the session ids of the user's index file, oldest login first.
+func (fp *FileProvider) UserSessions(ctx context.Context, uid string) ([]string, error) {
+	filepder.lock.Lock()
+	defer filepder.lock.Unlock()
+	return fp.readUserIndex(uid)
+}
+
+// DestroyUserSessions removes the session files of the user and its index file.
+func (fp *FileProvider) DestroyUserSessions(ctx context.Context, uid string) error {
+	sids, err := fp.UserSessions(ctx, uid)
+	if err != nil {
+		return err
+	}
+	for _, sid := range sids {
+		if err = fp.Destroy(ctx, sid); err != nil {
+			return err
+		}
 	}
-	return a.total
+
+	filepder.lock.Lock()
+	defer filepder.lock.Unlock()
+	return fp.writeUserIndex(uid, nil)
+}
+
+// Init implements the ProviderV2, see `SessionInit`.
+func (fp *FileProvider) Init(ctx context.Context, maxlifetime int64, savePath string) error {
+	return fp.SessionInit(maxlifetime, savePath)
+}
+
+// Read implements the ProviderV2, see `SessionRead`.
+func (fp *FileProvider) Read(ctx context.Context, sid string) (Store, error) {
+	return fp.SessionRead(sid)
+}
+
+// Regenerate implements the ProviderV2, see `SessionRegenerate`.
+func (fp *FileProvider) Regenerate(ctx context.Context, oldsid, sid string) (Store, error) {
+	return fp.SessionRegenerate(oldsid, sid)
 }
 
 // SessionRegenerate Generate new sid for file session.
@@ -242,7 +472,7 @@ func (fp *FileProvider) SessionRegenerate(oldsid, sid string) (Store, error) {
 		if len(b) == 0 {
 			kv = make(map[interface{}]interface{})
 		} else {
-			kv, err = DecodeGob(b)
+			kv, err = fp.serializer.Deserialize(b)
 			if err != nil {
 				return nil, err
 			}
@@ -251,6 +481,11 @@ func (fp *FileProvider) SessionRegenerate(oldsid, sid string) (Store, error) {
 		ioutil.WriteFile(newSidFile, b, 0777)
 		os.Remove(oldSidFile)
 		os.Chtimes(newSidFile, time.Now(), time.Now())
+		if uid, ok := kv[UserIDKey].(string); ok {
+			if err = fp.replaceUserSession(uid, oldsid, sid); err != nil {
+				SLogger.Println(err)
+			}
+		}
 		ss := &FileSessionStore{sid: sid, values: kv}
 		return ss, nil
 	}
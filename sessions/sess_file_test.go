@@ -3,9 +3,13 @@
 package sessions
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
 	"time"
 
@@ -14,6 +18,8 @@ import (
 )
 
 func TestSessionFile(t *testing.T) {
+	defer os.RemoveAll("./tmp")
+
 	config := `{"cookieName":"gosessionid","gclifetime":600,"cookieLifeTime":1800,"domain":"example.com","EnableSidInURLQuery":true,"enableSetCookie":true,"EnableSidInHTTPHeader":true,"SessionNameInHTTPHeader":"Gosessionid","providerConfig":"./tmp"}`
 	conf := new(ManagerConfig)
 	if err := json.Unmarshal([]byte(config), conf); err != nil {
@@ -220,7 +226,7 @@ func TestSessionFile(t *testing.T) {
 		t.Fatalf("GetSessionStore returns err, %s", err)
 	}
 	newseddion, _ := globalSessions.sessionID()
-	_, err = globalSessions.provider.SessionRegenerate("notfound1234", newseddion)
+	_, err = globalSessions.provider.Regenerate(context.Background(), "notfound1234", newseddion)
 	if err != nil {
 		t.Fatalf("SessionRegenerate returns err, %s", err)
 	}
@@ -266,3 +272,68 @@ func TestSessionFilePanic_Manager2(t *testing.T) {
 	}
 	_, _ = NewManager("file", conf)
 }
+
+func TestSessionFileUserIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "siris-sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manager, err := NewManager("file", &ManagerConfig{
+		CookieName:     "gosessionid",
+		Gclifetime:     3600,
+		ProviderConfig: dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	var sids []string
+	for i := 0; i < 2; i++ {
+		store, err := manager.Login(ctx, httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), "file-user")
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.SessionRelease(httptest.NewRecorder())
+		sids = append(sids, store.SessionID())
+	}
+
+	// the index is not counted as a session.
+	if n, err := manager.provider.Count(ctx); err != nil || n != 2 {
+		t.Fatalf("expected 2 sessions but got %d, %v", n, err)
+	}
+
+	// the regenerated session id replaces the old one in the index.
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: "gosessionid", Value: sids[0]})
+	store, err := manager.Regenerate(ctx, httptest.NewRecorder(), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sids[0] = store.SessionID()
+
+	got, err := manager.UserSessions(ctx, "file-user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != sids[0] || got[1] != sids[1] {
+		t.Fatalf("expected the sessions %v but got %v", sids, got)
+	}
+
+	// destroyed sessions are omitted.
+	if err = manager.provider.Destroy(ctx, sids[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = manager.UserSessions(ctx, "file-user"); len(got) != 1 || got[0] != sids[0] {
+		t.Fatalf("expected the session %s only but got %v", sids[0], got)
+	}
+
+	if err = manager.DestroyUserSessions(ctx, "file-user"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = manager.UserSessions(ctx, "file-user"); len(got) != 0 {
+		t.Fatalf("expected no sessions but got %v", got)
+	}
+}
@@ -16,12 +16,13 @@ package sessions
 
 import (
 	"container/list"
+	"context"
 	"net/http"
 	"sync"
 	"time"
 )
 
-var mempder = &MemProvider{list: list.New(), sessions: make(map[string]*list.Element)}
+var mempder = &MemProvider{list: list.New(), sessions: make(map[string]*list.Element), users: make(map[string][]string)}
 
 // MemSessionStore memory session store.
 // it saved sessions in a map in memory.
@@ -66,6 +67,20 @@ func (st *MemSessionStore) Flush() error {
 	return nil
 }
 
+// Visit calls the visitor for each session value.
+func (st *MemSessionStore) Visit(visitor func(key, value interface{})) {
+	st.lock.RLock()
+	values := make(map[interface{}]interface{}, len(st.value))
+	for k, v := range st.value {
+		values[k] = v
+	}
+	st.lock.RUnlock()
+
+	for k, v := range values {
+		visitor(k, v)
+	}
+}
+
 // SessionID get this id of memory session store
 func (st *MemSessionStore) SessionID() string {
 	return st.sid
@@ -80,12 +95,17 @@ type MemProvider struct {
 	lock        sync.RWMutex             // locker
 	sessions    map[string]*list.Element // map in memory
 	list        *list.List               // for gc
+	users       map[string][]string      // user id to its session ids, by login
 	maxlifetime int64
 	savePath    string
 }
 
 // SessionInit init memory session
 func (pder *MemProvider) SessionInit(maxlifetime int64, savePath string) error {
+	// the provider is shared by the managers, its GC may be running.
+	pder.lock.Lock()
+	defer pder.lock.Unlock()
+
 	pder.maxlifetime = maxlifetime
 	pder.savePath = savePath
 	return nil
@@ -128,6 +148,9 @@ func (pder *MemProvider) SessionRegenerate(oldsid, sid string) (Store, error) {
 		element.Value.(*MemSessionStore).sid = sid
 		pder.sessions[sid] = element
 		delete(pder.sessions, oldsid)
+		if uid, ok := element.Value.(*MemSessionStore).Get(UserIDKey).(string); ok {
+			pder.replaceUserSession(uid, oldsid, sid)
+		}
 		pder.lock.Unlock()
 		return element.Value.(*MemSessionStore), nil
 	}
@@ -147,6 +170,7 @@ func (pder *MemProvider) SessionDestroy(sid string) error {
 	if element, ok := pder.sessions[sid]; ok {
 		delete(pder.sessions, sid)
 		pder.list.Remove(element)
+		pder.unindexUserSession(element.Value.(*MemSessionStore))
 		return nil
 	}
 	return nil
@@ -165,6 +189,7 @@ func (pder *MemProvider) SessionGC() {
 			pder.lock.Lock()
 			pder.list.Remove(element)
 			delete(pder.sessions, element.Value.(*MemSessionStore).sid)
+			pder.unindexUserSession(element.Value.(*MemSessionStore))
 			pder.lock.Unlock()
 			pder.lock.RLock()
 		} else {
@@ -191,6 +216,112 @@ func (pder *MemProvider) SessionUpdate(sid string) error {
 	return nil
 }
 
+// Init implements the ProviderV2, see `SessionInit`.
+func (pder *MemProvider) Init(ctx context.Context, maxlifetime int64, savePath string) error {
+	return pder.SessionInit(maxlifetime, savePath)
+}
+
+// Read implements the ProviderV2, see `SessionRead`.
+func (pder *MemProvider) Read(ctx context.Context, sid string) (Store, error) {
+	return pder.SessionRead(sid)
+}
+
+// Exists implements the ProviderV2, see `SessionExist`.
+func (pder *MemProvider) Exists(ctx context.Context, sid string) (bool, error) {
+	return pder.SessionExist(sid), nil
+}
+
+// Regenerate implements the ProviderV2, see `SessionRegenerate`.
+func (pder *MemProvider) Regenerate(ctx context.Context, oldsid, sid string) (Store, error) {
+	return pder.SessionRegenerate(oldsid, sid)
+}
+
+// Destroy implements the ProviderV2, see `SessionDestroy`.
+func (pder *MemProvider) Destroy(ctx context.Context, sid string) error {
+	return pder.SessionDestroy(sid)
+}
+
+// Touch implements the ProviderV2, see `SessionUpdate`.
+func (pder *MemProvider) Touch(ctx context.Context, sid string) error {
+	return pder.SessionUpdate(sid)
+}
+
+// Count implements the ProviderV2, see `SessionAll`.
+func (pder *MemProvider) Count(ctx context.Context) (int, error) {
+	return pder.SessionAll(), nil
+}
+
+// GC implements the ProviderV2, see `SessionGC`.
+func (pder *MemProvider) GC(ctx context.Context) error {
+	pder.SessionGC()
+	return nil
+}
+
+// BindUser indexes the memory session of the store under the user of the "uid".
+func (pder *MemProvider) BindUser(ctx context.Context, store Store, uid string) error {
+	pder.lock.Lock()
+	defer pder.lock.Unlock()
+
+	sid := store.SessionID()
+	if prev, ok := store.Get(UserIDKey).(string); ok {
+		if prev == uid {
+			return nil
+		}
+		pder.replaceUserSession(prev, sid, "")
+	}
+	pder.users[uid] = append(pder.users[uid], sid)
+	return nil
+}
+
+// UserSessions returns the ids of the memory sessions of the user, oldest login first.
+func (pder *MemProvider) UserSessions(ctx context.Context, uid string) ([]string, error) {
+	pder.lock.RLock()
+	defer pder.lock.RUnlock()
+	return append([]string(nil), pder.users[uid]...), nil
+}
+
+// DestroyUserSessions deletes the memory sessions of the user.
+func (pder *MemProvider) DestroyUserSessions(ctx context.Context, uid string) error {
+	sids, err := pder.UserSessions(ctx, uid)
+	if err != nil {
+		return err
+	}
+	for _, sid := range sids {
+		pder.SessionDestroy(sid)
+	}
+	return nil
+}
+
+// unindexUserSession removes the session of the store from the index of its user, if bound.
+// The lock should be held.
+func (pder *MemProvider) unindexUserSession(st *MemSessionStore) {
+	if uid, ok := st.Get(UserIDKey).(string); ok {
+		pder.replaceUserSession(uid, st.sid, "")
+	}
+}
+
+// replaceUserSession replaces the "oldsid" with the "sid" in the index of the user,
+// an empty "sid" removes the "oldsid". The lock should be held.
+func (pder *MemProvider) replaceUserSession(uid, oldsid, sid string) {
+	sids := pder.users[uid]
+	for i, s := range sids {
+		if s != oldsid {
+			continue
+		}
+		if sid != "" {
+			sids[i] = sid
+			return
+		}
+		sids = append(sids[:i], sids[i+1:]...)
+		break
+	}
+	if len(sids) == 0 {
+		delete(pder.users, uid)
+		return
+	}
+	pder.users[uid] = sids
+}
+
 func init() {
 	Register("memory", mempder)
 }
@@ -3,6 +3,7 @@
 package sessions
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -220,7 +221,7 @@ func TestSessionMemory(t *testing.T) {
 		t.Fatalf("GetSessionStore returns err, %s", err)
 	}
 	newseddion, _ := globalSessions.sessionID()
-	_, err = globalSessions.provider.SessionRegenerate("notfound1234", newseddion)
+	_, err = globalSessions.provider.Regenerate(context.Background(), "notfound1234", newseddion)
 	if err != nil {
 		t.Fatalf("SessionRegenerate returns err, %s", err)
 	}
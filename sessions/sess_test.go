@@ -16,8 +16,13 @@ package sessions
 
 import (
 	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1"
 	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 )
 
 func Test_gob(t *testing.T) {
@@ -60,21 +65,19 @@ func TestGenerate(t *testing.T) {
 }
 
 func TestCookieEncodeDecode(t *testing.T) {
-	hashKey := "testhashKey"
-	blockkey := generateRandomKey(16)
-	block, err := aes.NewCipher(blockkey)
+	ring, err := newKeyRing("testhashKey")
 	if err != nil {
-		t.Fatal("NewCipher:", err)
+		t.Fatal("newKeyRing:", err)
 	}
 	securityName := string(generateRandomKey(20))
 	val := make(map[interface{}]interface{})
 	val["name"] = "astaxie"
 	val["gender"] = "male"
-	str, err := encodeCookie(block, hashKey, securityName, val)
+	str, err := encodeCookie(ring, DefaultSerializer, securityName, val)
 	if err != nil {
 		t.Fatal("encodeCookie:", err)
 	}
-	dst, err := decodeCookie(block, hashKey, securityName, str, 3600)
+	dst, err := decodeCookie(ring, DefaultSerializer, nil, securityName, str, 3600)
 	if err != nil {
 		t.Fatal("decodeCookie", err)
 	}
@@ -84,6 +87,63 @@ func TestCookieEncodeDecode(t *testing.T) {
 	if dst["gender"] != "male" {
 		t.Fatal("dst get map error")
 	}
+	if _, err = decodeCookie(ring, DefaultSerializer, nil, "othername", str, 3600); err == nil {
+		t.Fatal("decodeCookie should fail with a different security name")
+	}
+}
+
+func TestCookieKeyRotation(t *testing.T) {
+	oldRing, _ := newKeyRing("oldkey")
+	newRing, _ := newKeyRing("newkey", "oldkey")
+	val := map[interface{}]interface{}{"name": "astaxie"}
+
+	str, err := encodeCookie(oldRing, DefaultSerializer, "name", val)
+	if err != nil {
+		t.Fatal("encodeCookie:", err)
+	}
+	if dst, err := decodeCookie(newRing, DefaultSerializer, nil, "name", str, 3600); err != nil || dst["name"] != "astaxie" {
+		t.Fatal("decodeCookie with a rotated key ring", err)
+	}
+
+	str, _ = encodeCookie(newRing, DefaultSerializer, "name", val)
+	if _, err = decodeCookie(oldRing, DefaultSerializer, nil, "name", str, 3600); err == nil {
+		t.Fatal("decodeCookie should fail without the primary key")
+	}
+}
+
+func TestCookieDecodeLegacy(t *testing.T) {
+	hashKey := "testhashKey"
+	block, err := aes.NewCipher(generateRandomKey(16))
+	if err != nil {
+		t.Fatal("NewCipher:", err)
+	}
+	ring, _ := newKeyRing(hashKey)
+	val := map[interface{}]interface{}{"name": "astaxie"}
+
+	// the legacy encoding: "date|aes-ctr(gob)|hmac-sha1" in base64.
+	b, _ := EncodeGob(val)
+	iv := generateRandomKey(block.BlockSize())
+	cipher.NewCTR(block, iv).XORKeyStream(b, b)
+	b = []byte(fmt.Sprintf("name|%d|%s|", time.Now().UTC().Unix(), encode(append(iv, b...))))
+	h := hmac.New(sha1.New, []byte(hashKey))
+	h.Write(b)
+	str := string(encode(append(b, h.Sum(nil)...)[len("name")+1:]))
+
+	if _, err = decodeCookie(ring, DefaultSerializer, nil, "name", str, 3600); err == nil {
+		t.Fatal("decodeCookie should fail without the legacy keys")
+	}
+	dst, err := decodeCookie(ring, DefaultSerializer, &legacyCookieKeys{block: block, hashKey: hashKey}, "name", str, 3600)
+	if err != nil || dst["name"] != "astaxie" {
+		t.Fatal("decodeCookie of a legacy cookie", err)
+	}
+}
+
+func TestCookieTooLarge(t *testing.T) {
+	ring, _ := newKeyRing("testhashKey")
+	val := map[interface{}]interface{}{"data": string(RandomCreateBytes(MaxCookieSize))}
+	if _, err := encodeCookie(ring, DefaultSerializer, "name", val); err != ErrCookieTooLarge {
+		t.Fatalf("expected ErrCookieTooLarge but got %v", err)
+	}
 }
 
 func TestParseConfig(t *testing.T) {
@@ -16,12 +16,15 @@ package sessions
 
 import (
 	"bytes"
+	"crypto/aes"
 	"crypto/cipher"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha1"
+	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/gob"
 	"errors"
 	"fmt"
@@ -46,28 +49,12 @@ func init() {
 
 // EncodeGob encode the obj to gob
 func EncodeGob(obj map[interface{}]interface{}) ([]byte, error) {
-	for _, v := range obj {
-		gob.Register(v)
-	}
-	buf := bytes.NewBuffer(nil)
-	enc := gob.NewEncoder(buf)
-	err := enc.Encode(obj)
-	if err != nil {
-		return []byte(""), err
-	}
-	return buf.Bytes(), nil
+	return GobSerializer{}.Serialize(obj)
 }
 
 // DecodeGob decode data to map
 func DecodeGob(encoded []byte) (map[interface{}]interface{}, error) {
-	buf := bytes.NewBuffer(encoded)
-	dec := gob.NewDecoder(buf)
-	var out map[interface{}]interface{}
-	err := dec.Decode(&out)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+	return GobSerializer{}.Deserialize(encoded)
 }
 
 // generateRandomKey creates a random key with the given strength.
@@ -79,23 +66,7 @@ func generateRandomKey(strength int) []byte {
 	return k
 }
 
-// Encryption -----------------------------------------------------------------
-
-// encrypt encrypts a value using the given block in counter mode.
-//
-// A random initialization vector (http://goo.gl/zF67k) with the length of the
-// block size is prepended to the resulting ciphertext.
-func encrypt(block cipher.Block, value []byte) ([]byte, error) {
-	iv := generateRandomKey(block.BlockSize())
-	if iv == nil {
-		return nil, errors.New("encrypt: failed to generate random iv")
-	}
-	// Encrypt it.
-	stream := cipher.NewCTR(block, iv)
-	stream.XORKeyStream(value, value)
-	// Return iv + ciphertext.
-	return append(iv, value...), nil
-}
+// Legacy encryption ----------------------------------------------------------
 
 // decrypt decrypts a value using the given block in counter mode.
 //
@@ -116,38 +87,9 @@ func decrypt(block cipher.Block, value []byte) ([]byte, error) {
 	return nil, errors.New("decrypt: the value could not be decrypted")
 }
 
-func encodeCookie(block cipher.Block, hashKey, name string, value map[interface{}]interface{}) (string, error) {
-	var err error
-	var b []byte
-	// 1. EncodeGob.
-	if b, err = EncodeGob(value); err != nil {
-		return "", err
-	}
-	// 2. Encrypt (optional).
-	if b, err = encrypt(block, b); err != nil {
-		return "", err
-	}
-	b = encode(b)
-	// 3. Create MAC for "name|date|value". Extra pipe to be used later.
-	b = []byte(fmt.Sprintf("%s|%d|%s|", name, time.Now().UTC().Unix(), b))
-	h := hmac.New(sha1.New, []byte(hashKey))
-	h.Write(b)
-	sig := h.Sum(nil)
-	// Append mac, remove name.
-	b = append(b, sig...)[len(name)+1:]
-	// 4. Encode to base64.
-	b = encode(b)
-	// Done.
-	return string(b), nil
-}
-
-func decodeCookie(block cipher.Block, hashKey, name, value string, gcmaxlifetime int64) (map[interface{}]interface{}, error) {
-	// 1. Decode from base64.
-	b, err := decode([]byte(value))
-	if err != nil {
-		return nil, err
-	}
-	// 2. Verify MAC. Value is "date|value|mac".
+// decodeLegacyCookie decodes the base64 decoded "date|value|mac" of the legacy encoding.
+func decodeLegacyCookie(block cipher.Block, hashKey, name string, b []byte, gcmaxlifetime int64) (map[interface{}]interface{}, error) {
+	// 1. Verify MAC. Value is "date|value|mac".
 	parts := bytes.SplitN(b, []byte("|"), 3)
 	if len(parts) != 3 {
 		return nil, errors.New("Decode: invalid value %v")
@@ -160,19 +102,15 @@ func decodeCookie(block cipher.Block, hashKey, name, value string, gcmaxlifetime
 	if len(sig) != len(parts[2]) || subtle.ConstantTimeCompare(sig, parts[2]) != 1 {
 		return nil, errors.New("Decode: the value is not valid")
 	}
-	// 3. Verify date ranges.
-	var t1 int64
-	if t1, err = strconv.ParseInt(string(parts[0]), 10, 64); err != nil {
+	// 2. Verify date ranges.
+	t1, err := strconv.ParseInt(string(parts[0]), 10, 64)
+	if err != nil {
 		return nil, errors.New("Decode: invalid timestamp")
 	}
-	t2 := time.Now().UTC().Unix()
-	if t1 > t2 {
-		return nil, errors.New("Decode: timestamp is too new")
-	}
-	if t1 < t2-gcmaxlifetime {
-		return nil, errors.New("Decode: expired timestamp")
+	if err = verifyCookieTimestamp(t1, gcmaxlifetime); err != nil {
+		return nil, err
 	}
-	// 4. Decrypt (optional).
+	// 3. Decrypt.
 	b, err = decode(parts[1])
 	if err != nil {
 		return nil, err
@@ -180,12 +118,156 @@ func decodeCookie(block cipher.Block, hashKey, name, value string, gcmaxlifetime
 	if b, err = decrypt(block, b); err != nil {
 		return nil, err
 	}
-	// 5. DecodeGob.
-	dst, err := DecodeGob(b)
+	// 4. DecodeGob.
+	return DecodeGob(b)
+}
+
+// Authenticated encryption -------------------------------------------------
+
+const (
+	// cookieVersion is the first byte of the encoded cookies' payload,
+	// cookies which were encoded by the legacy, AES-CTR with HMAC-SHA1, encoding are not versioned.
+	cookieVersion byte = 2
+	// cookieHeaderLen is the length of the version and the timestamp of the payload.
+	cookieHeaderLen = 1 + 8
+)
+
+// MaxCookieSize is the maximum size of a cookie's value which the browsers keep.
+const MaxCookieSize = 4096
+
+// ErrCookieTooLarge is returned when the encoded session values exceed the MaxCookieSize,
+// the browsers would drop the cookie.
+var ErrCookieTooLarge = fmt.Errorf("session: encoded cookie exceeds the %d bytes limit", MaxCookieSize)
+
+// keyRing encrypts with its primary, the first, key and decrypts with any of its keys,
+// keys can be rotated without invalidating the existing cookies.
+//
+// The AES-256 keys are derived from the secrets using SHA-256,
+// the values are encrypted and authenticated with GCM.
+type keyRing struct {
+	aeads []cipher.AEAD
+}
+
+func newKeyRing(secrets ...string) (*keyRing, error) {
+	if len(secrets) == 0 {
+		return nil, errors.New("session: key ring needs at least one key")
+	}
+
+	ring := &keyRing{aeads: make([]cipher.AEAD, 0, len(secrets))}
+	for _, secret := range secrets {
+		key := sha256.Sum256([]byte(secret))
+		block, err := aes.NewCipher(key[:])
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		ring.aeads = append(ring.aeads, aead)
+	}
+
+	return ring, nil
+}
+
+// seal encrypts and authenticates the value and the additional data with the primary key,
+// the random nonce is prepended to the result.
+func (r *keyRing) seal(value, additionalData []byte) ([]byte, error) {
+	aead := r.aeads[0]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, value, additionalData), nil
+}
+
+// open decrypts the sealed value with the first key which authenticates it.
+func (r *keyRing) open(sealed, additionalData []byte) ([]byte, error) {
+	for _, aead := range r.aeads {
+		if len(sealed) < aead.NonceSize() {
+			continue
+		}
+		nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		if value, err := aead.Open(nil, nonce, ciphertext, additionalData); err == nil {
+			return value, nil
+		}
+	}
+	return nil, errors.New("Decode: the value is not valid")
+}
+
+// legacyCookieKeys are the keys of the legacy encoding,
+// used to decode the cookies which were encoded before the key ring.
+type legacyCookieKeys struct {
+	block   cipher.Block
+	hashKey string
+}
+
+// encodeCookie encodes the values as "version|timestamp|nonce|ciphertext",
+// the "name", the version and the timestamp are authenticated as well.
+//
+// Returns ErrCookieTooLarge if the result exceeds the MaxCookieSize.
+func encodeCookie(ring *keyRing, serializer Serializer, name string, value map[interface{}]interface{}) (string, error) {
+	// 1. Serialize.
+	b, err := serializer.Serialize(value)
 	if err != nil {
+		return "", err
+	}
+	// 2. Version and date.
+	header := make([]byte, cookieHeaderLen)
+	header[0] = cookieVersion
+	binary.BigEndian.PutUint64(header[1:], uint64(time.Now().UTC().Unix()))
+	// 3. Encrypt and authenticate "name|header|value".
+	sealed, err := ring.seal(b, append([]byte(name+"|"), header...))
+	if err != nil {
+		return "", err
+	}
+	// 4. Encode to base64.
+	b = encode(append(header, sealed...))
+	if len(b) > MaxCookieSize {
+		return "", ErrCookieTooLarge
+	}
+	return string(b), nil
+}
+
+// decodeCookie decodes the values of a cookie which was encoded by the encodeCookie,
+// or by the legacy, always gob, encoding if the "legacy" keys are not nil.
+func decodeCookie(ring *keyRing, serializer Serializer, legacy *legacyCookieKeys, name, value string, gcmaxlifetime int64) (map[interface{}]interface{}, error) {
+	// 1. Decode from base64.
+	b, err := decode([]byte(value))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 || b[0] != cookieVersion {
+		if legacy == nil {
+			return nil, errors.New("Decode: unknown version")
+		}
+		return decodeLegacyCookie(legacy.block, legacy.hashKey, name, b, gcmaxlifetime)
+	}
+	if len(b) < cookieHeaderLen {
+		return nil, errors.New("Decode: invalid value")
+	}
+	// 2. Decrypt and verify.
+	header := b[:cookieHeaderLen]
+	if b, err = ring.open(b[cookieHeaderLen:], append([]byte(name+"|"), header...)); err != nil {
+		return nil, err
+	}
+	// 3. Verify date ranges.
+	if err = verifyCookieTimestamp(int64(binary.BigEndian.Uint64(header[1:])), gcmaxlifetime); err != nil {
 		return nil, err
 	}
-	return dst, nil
+	// 4. Deserialize.
+	return serializer.Deserialize(b)
+}
+
+func verifyCookieTimestamp(t1 int64, gcmaxlifetime int64) error {
+	t2 := time.Now().UTC().Unix()
+	if t1 > t2 {
+		return errors.New("Decode: timestamp is too new")
+	}
+	if t1 < t2-gcmaxlifetime {
+		return errors.New("Decode: expired timestamp")
+	}
+	return nil
 }
 
 // Encoding -------------------------------------------------------------------
@@ -28,16 +28,20 @@
 package sessions
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"net/textproto"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -63,7 +67,7 @@ type Provider interface {
 	SessionGC()
 }
 
-var provides = make(map[string]Provider)
+var provides = make(map[string]ProviderV2)
 
 // SLogger a helpful variable to log information about session
 var SLogger = NewSessionLog(os.Stderr)
@@ -71,7 +75,19 @@ var SLogger = NewSessionLog(os.Stderr)
 // Register makes a session provide available by the provided name.
 // If Register is called twice with the same name or if driver is nil,
 // it panics.
+//
+// A provider which doesn't implement the ProviderV2 is adapted by the `LegacyProvider`.
 func Register(name string, provide Provider) {
+	if provide == nil {
+		panic("session: Register provide is nil")
+	}
+	RegisterV2(name, LegacyProvider(provide))
+}
+
+// RegisterV2 makes a context-aware session provide available by the provided name.
+// If RegisterV2 is called twice with the same name or if driver is nil,
+// it panics.
+func RegisterV2(name string, provide ProviderV2) {
 	if provide == nil {
 		panic("session: Register provide is nil")
 	}
@@ -96,11 +112,40 @@ type ManagerConfig struct {
 	EnableSidInHTTPHeader   bool   `json:"EnableSidInHTTPHeader"`
 	SessionNameInHTTPHeader string `json:"SessionNameInHTTPHeader"`
 	EnableSidInURLQuery     bool   `json:"EnableSidInURLQuery"`
+	// CookiePath is the path of the session id cookie, defaults to "/".
+	CookiePath string `json:"cookiePath"`
+	// SameSite is the SameSite attribute of the session id cookie,
+	// "Lax", "Strict" or "None", defaults to empty, the attribute is omitted.
+	// The "None" requires the Secure field.
+	SameSite string `json:"sameSite"`
+	// CookiePrefix is the prefix of the session id cookie's name, "__Host-" or "__Secure-".
+	// Both require the Secure field, the "__Host-" requires an empty Domain and the "/" CookiePath too.
+	CookiePrefix string `json:"cookiePrefix"`
+	// Serializer encodes and decodes the saved session values,
+	// defaults to the gob one, see `DefaultSerializer`.
+	Serializer Serializer `json:"-"`
+	// ProviderTimeout is the deadline of each provider's operation,
+	// i.e a redis command or a database query, defaults to zero, no deadline except the request's one.
+	ProviderTimeout time.Duration `json:"providerTimeout"`
+	// MaxSessionsPerUser is the maximum number of the concurrent sessions of a user,
+	// the oldest ones are destroyed on the `Login`. Defaults to zero, unlimited.
+	// It requires a provider which indexes the users' sessions, see `UserSessionsProvider`.
+	MaxSessionsPerUser int `json:"maxSessionsPerUser"`
+	// FingerprintUserAgent binds the session to the client's User-Agent,
+	// a session which is read by another User-Agent is destroyed and a new one is started.
+	FingerprintUserAgent bool `json:"fingerprintUserAgent"`
+	// FingerprintIPv4Prefix and FingerprintIPv6Prefix bind the session to the network of the client,
+	// the prefix length, in bits, of the client's IP. Defaults to zero, the IP is not checked.
+	//
+	// The client's IP is the one of the `WithClientIP`, i.e resolved behind the trusted proxies
+	// by the siris Context, otherwise the request's RemoteAddr, the proxy's address, if any.
+	FingerprintIPv4Prefix int `json:"fingerprintIPv4Prefix"`
+	FingerprintIPv6Prefix int `json:"fingerprintIPv6Prefix"`
 }
 
 // Manager contains Provider and its configuration.
 type Manager struct {
-	provider Provider
+	provider ProviderV2
 	config   *ManagerConfig
 }
 
@@ -138,7 +183,13 @@ func NewManager(provideName string, cf *ManagerConfig) (*Manager, error) {
 		}
 	}
 
-	err := provider.SessionInit(cf.Maxlifetime, cf.ProviderConfig)
+	if setter, ok := provider.(SerializerSetter); ok {
+		setter.SetSerializer(SerializerOrDefault(cf.Serializer))
+	}
+
+	ctx, cancel := withTimeout(context.Background(), cf.ProviderTimeout)
+	err := provider.Init(ctx, cf.Maxlifetime, cf.ProviderConfig)
+	cancel()
 	if err != nil {
 		return nil, err
 	}
@@ -147,10 +198,32 @@ func NewManager(provideName string, cf *ManagerConfig) (*Manager, error) {
 		cf.SessionIDLength = 16
 	}
 
-	return &Manager{
+	if err := cf.validateCookie(); err != nil {
+		return nil, err
+	}
+
+	if cf.FingerprintIPv4Prefix < 0 || cf.FingerprintIPv4Prefix > 32 ||
+		cf.FingerprintIPv6Prefix < 0 || cf.FingerprintIPv6Prefix > 128 {
+		return nil, fmt.Errorf("session: invalid fingerprint prefixes /%d and /%d", cf.FingerprintIPv4Prefix, cf.FingerprintIPv6Prefix)
+	}
+
+	if _, ok := provider.(UserSessionsProvider); !ok && cf.MaxSessionsPerUser > 0 {
+		return nil, fmt.Errorf("session: MaxSessionsPerUser: %v", ErrNotSupported)
+	}
+
+	manager := &Manager{
 		provider,
 		cf,
-	}, nil
+	}
+
+	if setter, ok := provider.(CookieSetter); ok {
+		setter.SetCookie(func(value string) *http.Cookie {
+			// released without the request, the Secure option is used as it's.
+			return manager.cookie(value, cf.Secure)
+		})
+	}
+
+	return manager, nil
 }
 
 // getSid retrieves session identifier from HTTP Request.
@@ -161,7 +234,7 @@ func NewManager(provideName string, cf *ManagerConfig) (*Manager, error) {
 // sid is empty when need to generate a new session id
 // otherwise return an valid session id.
 func (manager *Manager) getSid(r *http.Request) (string, error) {
-	cookie, errs := r.Cookie(manager.config.CookieName)
+	cookie, errs := r.Cookie(manager.cookieName())
 	if errs != nil || cookie.Value == "" {
 		var sid string
 		if manager.config.EnableSidInURLQuery {
@@ -191,39 +264,65 @@ func (manager *Manager) getSid(r *http.Request) (string, error) {
 // SessionStart generate or read the session id from http request.
 // if session id exists, return SessionStore with this id.
 func (manager *Manager) SessionStart(w http.ResponseWriter, r *http.Request) (session Store, errs error) {
-	sid, errs := manager.getSid(r)
+	return manager.Start(r.Context(), w, r)
+}
 
-	if errs != nil {
-		return nil, errs
+// Start is the context-aware SessionStart,
+// it returns the provider's errors, i.e when the storage is unreachable.
+func (manager *Manager) Start(ctx context.Context, w http.ResponseWriter, r *http.Request) (Store, error) {
+	session, err := manager.StartSession(ctx, w, r)
+	if err != nil {
+		return nil, err
 	}
+	return session.Store, nil
+}
 
-	if sid != "" && manager.provider.SessionExist(sid) {
-		return manager.provider.SessionRead(sid)
+// StartSession is like the Start but it returns the request's Session,
+// its fingerprint is marked as modified when it's taken, to be saved on its Release.
+func (manager *Manager) StartSession(ctx context.Context, w http.ResponseWriter, r *http.Request) (*Session, error) {
+	sid, err := manager.getSid(r)
+	if err != nil {
+		return nil, err
 	}
 
-	// Generate a new session
-	sid, errs = manager.sessionID()
-	if errs != nil {
-		return nil, errs
-	}
+	ctx, cancel := manager.withTimeout(ctx)
+	defer cancel()
 
-	session, errs = manager.provider.SessionRead(sid)
-	if errs != nil {
-		return nil, errs
+	fingerprint := manager.fingerprint(ctx, r)
+	if sid != "" {
+		exists, err := manager.provider.Exists(ctx, sid)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			store, err := manager.provider.Read(ctx, sid)
+			if err != nil {
+				return nil, err
+			}
+			if session := NewSession(store); matchFingerprint(session, fingerprint) {
+				return session, nil
+			}
+			// the session id is used by another client, i.e stolen, invalidate it.
+			if err = manager.provider.Destroy(ctx, sid); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	cookie := &http.Cookie{
-		Name:     manager.config.CookieName,
-		Value:    url.QueryEscape(sid),
-		Path:     "/",
-		HttpOnly: !manager.config.DisableHTTPOnly,
-		Secure:   manager.isSecure(r),
-		Domain:   manager.config.Domain,
+	// Generate a new session
+	sid, err = manager.sessionID()
+	if err != nil {
+		return nil, err
 	}
-	if manager.config.CookieLifeTime > 0 {
-		cookie.MaxAge = manager.config.CookieLifeTime
-		cookie.Expires = time.Now().Add(time.Duration(manager.config.CookieLifeTime) * time.Second)
+
+	store, err := manager.provider.Read(ctx, sid)
+	if err != nil {
+		return nil, err
 	}
+	session := NewSession(store)
+	matchFingerprint(session, fingerprint)
+
+	cookie := manager.newCookie(r, sid)
 	if manager.config.EnableSetCookie {
 		http.SetCookie(w, cookie)
 	}
@@ -234,78 +333,94 @@ func (manager *Manager) SessionStart(w http.ResponseWriter, r *http.Request) (se
 		w.Header().Set(manager.config.SessionNameInHTTPHeader, sid)
 	}
 
-	return
+	return session, nil
 }
 
 // SessionDestroy Destroy session by its id in http request cookie.
+// The provider's error is logged, see `Destroy` to handle it.
 func (manager *Manager) SessionDestroy(w http.ResponseWriter, r *http.Request) {
+	if err := manager.Destroy(r.Context(), w, r); err != nil {
+		SLogger.Println(err)
+	}
+}
+
+// Destroy is the context-aware SessionDestroy, it returns the provider's error.
+func (manager *Manager) Destroy(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	if manager.config.EnableSidInHTTPHeader {
 		r.Header.Del(manager.config.SessionNameInHTTPHeader)
 		w.Header().Del(manager.config.SessionNameInHTTPHeader)
 	}
 
-	cookie, err := r.Cookie(manager.config.CookieName)
+	cookie, err := r.Cookie(manager.cookieName())
 	if err != nil || cookie.Value == "" {
-		return
+		return nil
 	}
 
 	sid, _ := url.QueryUnescape(cookie.Value)
-	manager.provider.SessionDestroy(sid)
 	if manager.config.EnableSetCookie {
-		expiration := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
-		cookie = &http.Cookie{Name: manager.config.CookieName,
-			Path:     "/",
-			HttpOnly: !manager.config.DisableHTTPOnly,
-			Expires:  expiration,
-			MaxAge:   -1,
-			Domain:   manager.config.Domain}
+		cookie = manager.newCookie(r, "")
+		cookie.Expires = time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+		cookie.MaxAge = -1
 
 		http.SetCookie(w, cookie)
 	}
+
+	ctx, cancel := manager.withTimeout(ctx)
+	defer cancel()
+	return manager.provider.Destroy(ctx, sid)
 }
 
 // GetSessionStore Get SessionStore by its id.
 func (manager *Manager) GetSessionStore(sid string) (sessions Store, err error) {
-	sessions, err = manager.provider.SessionRead(sid)
-	return
+	ctx, cancel := manager.withTimeout(context.Background())
+	defer cancel()
+	return manager.provider.Read(ctx, sid)
 }
 
 // GC Start session gc process.
 // it can do gc in times after gc lifetime.
 func (manager *Manager) GC() {
-	manager.provider.SessionGC()
+	ctx, cancel := manager.withTimeout(context.Background())
+	if err := manager.provider.GC(ctx); err != nil {
+		SLogger.Println(err)
+	}
+	cancel()
 	time.AfterFunc(time.Duration(manager.config.Gclifetime)*time.Second, func() { manager.GC() })
 }
 
 // SessionRegenerateID Regenerate a session id for this SessionStore who's id is saving in http request.
+// The provider's error is logged and a nil store is returned, see `Regenerate` to handle it.
 func (manager *Manager) SessionRegenerateID(w http.ResponseWriter, r *http.Request) (session Store) {
+	session, err := manager.Regenerate(r.Context(), w, r)
+	if err != nil {
+		SLogger.Println(err)
+	}
+	return
+}
+
+// Regenerate is the context-aware SessionRegenerateID, it returns the provider's error.
+func (manager *Manager) Regenerate(ctx context.Context, w http.ResponseWriter, r *http.Request) (session Store, err error) {
 	sid, err := manager.sessionID()
 	if err != nil {
-		return
+		return nil, err
 	}
-	cookie, err := r.Cookie(manager.config.CookieName)
+
+	ctx, cancel := manager.withTimeout(ctx)
+	defer cancel()
+
+	cookie, err := r.Cookie(manager.cookieName())
 	if err != nil || cookie.Value == "" {
 		//delete old cookie
-		session, _ = manager.provider.SessionRead(sid)
-		cookie = &http.Cookie{Name: manager.config.CookieName,
-			Value:    url.QueryEscape(sid),
-			Path:     "/",
-			HttpOnly: !manager.config.DisableHTTPOnly,
-			Secure:   manager.isSecure(r),
-			Domain:   manager.config.Domain,
-		}
+		session, err = manager.provider.Read(ctx, sid)
 	} else {
 		oldsid, _ := url.QueryUnescape(cookie.Value)
-		session, _ = manager.provider.SessionRegenerate(oldsid, sid)
-		cookie.Value = url.QueryEscape(sid)
-		cookie.HttpOnly = true
-		cookie.Path = "/"
-		cookie.Domain = manager.config.Domain
+		session, err = manager.provider.Regenerate(ctx, oldsid, sid)
 	}
-	if manager.config.CookieLifeTime > 0 {
-		cookie.MaxAge = manager.config.CookieLifeTime
-		cookie.Expires = time.Now().Add(time.Duration(manager.config.CookieLifeTime) * time.Second)
+	if err != nil {
+		return nil, err
 	}
+
+	cookie = manager.newCookie(r, sid)
 	if manager.config.EnableSetCookie {
 		http.SetCookie(w, cookie)
 	}
@@ -319,9 +434,137 @@ func (manager *Manager) SessionRegenerateID(w http.ResponseWriter, r *http.Reque
 	return
 }
 
+// Login binds the request's session to the user of the "uid" and returns it,
+// the session id is regenerated, against session fixation,
+// and the user's oldest sessions are destroyed if the MaxSessionsPerUser is exceeded.
+// The user id and the fingerprint are saved on the session's Release.
+//
+// The user's sessions are indexed only if the provider is an `UserSessionsProvider`,
+// see `UserSessions` and `DestroyOtherSessions`.
+func (manager *Manager) Login(ctx context.Context, w http.ResponseWriter, r *http.Request, uid string) (*Session, error) {
+	if uid == "" {
+		return nil, errors.New("session: empty user id")
+	}
+
+	store, err := manager.Regenerate(ctx, w, r)
+	if err != nil {
+		return nil, err
+	}
+	session := NewSession(store)
+	matchFingerprint(session, manager.fingerprint(ctx, r))
+
+	if p, ok := manager.provider.(UserSessionsProvider); ok {
+		ctx, cancel := manager.withTimeout(ctx)
+		defer cancel()
+
+		if err = p.BindUser(ctx, session, uid); err != nil {
+			return nil, err
+		}
+
+		if limit := manager.config.MaxSessionsPerUser; limit > 0 {
+			sids, err := p.UserSessions(ctx, uid)
+			if err != nil {
+				return nil, err
+			}
+			destroyed := 0
+			for _, sid := range sids {
+				if len(sids)-destroyed <= limit {
+					break
+				}
+				if sid == session.SessionID() {
+					continue
+				}
+				if err = manager.provider.Destroy(ctx, sid); err != nil {
+					return nil, err
+				}
+				destroyed++
+			}
+		}
+	}
+
+	if err = session.SetUserID(uid); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// DestroyOtherSessions removes the sessions of the user except the "sid" one,
+// i.e to log out from all the other devices.
+// It returns the ErrNotSupported if the provider is not an `UserSessionsProvider`.
+func (manager *Manager) DestroyOtherSessions(ctx context.Context, uid, sid string) error {
+	p, ok := manager.provider.(UserSessionsProvider)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	ctx, cancel := manager.withTimeout(ctx)
+	defer cancel()
+
+	sids, err := p.UserSessions(ctx, uid)
+	if err != nil {
+		return err
+	}
+	for _, s := range sids {
+		if s == sid {
+			continue
+		}
+		if err = manager.provider.Destroy(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Release saves the modified values of the request's session, see `Session#Release`,
+// the Manager's ProviderTimeout is applied.
+func (manager *Manager) Release(ctx context.Context, session *Session, w http.ResponseWriter) error {
+	ctx, cancel := manager.withTimeout(ctx)
+	defer cancel()
+	return session.Release(ctx, w)
+}
+
+// Touch extends the expiration of the session of the "sid", for a sliding expiry.
+func (manager *Manager) Touch(ctx context.Context, sid string) error {
+	ctx, cancel := manager.withTimeout(ctx)
+	defer cancel()
+	return manager.provider.Touch(ctx, sid)
+}
+
+// UserSessions returns the session ids of the user, see `Session#SetUserID`.
+// It returns the ErrNotSupported if the provider is not an `UserSessionsProvider`.
+func (manager *Manager) UserSessions(ctx context.Context, uid string) ([]string, error) {
+	p, ok := manager.provider.(UserSessionsProvider)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+
+	ctx, cancel := manager.withTimeout(ctx)
+	defer cancel()
+	return p.UserSessions(ctx, uid)
+}
+
+// DestroyUserSessions removes all the sessions of the user, i.e to log out from all the devices.
+// It returns the ErrNotSupported if the provider is not an `UserSessionsProvider`.
+func (manager *Manager) DestroyUserSessions(ctx context.Context, uid string) error {
+	p, ok := manager.provider.(UserSessionsProvider)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	ctx, cancel := manager.withTimeout(ctx)
+	defer cancel()
+	return p.DestroyUserSessions(ctx, uid)
+}
+
 // GetActiveSession Get all active sessions count number.
 func (manager *Manager) GetActiveSession() int {
-	return manager.provider.SessionAll()
+	ctx, cancel := manager.withTimeout(context.Background())
+	defer cancel()
+	n, err := manager.provider.Count(ctx)
+	if err != nil {
+		SLogger.Println(err)
+	}
+	return n
 }
 
 // SetSecure Set cookie with https.
@@ -329,6 +572,161 @@ func (manager *Manager) SetSecure(secure bool) {
 	manager.config.Secure = secure
 }
 
+// validateCookie validates the cookie's SameSite and prefix settings.
+func (cf *ManagerConfig) validateCookie() error {
+	if _, ok := sameSiteModes[strings.ToLower(cf.SameSite)]; !ok {
+		return fmt.Errorf("session: invalid SameSite %q, use Lax, Strict or None", cf.SameSite)
+	}
+	if sameSiteModes[strings.ToLower(cf.SameSite)] == http.SameSiteNoneMode && !cf.Secure {
+		return errors.New("session: the None SameSite requires the Secure option")
+	}
+
+	switch cf.CookiePrefix {
+	case "":
+	case hostCookiePrefix:
+		if cf.Domain != "" || (cf.CookiePath != "" && cf.CookiePath != "/") {
+			return fmt.Errorf("session: the %s cookie prefix requires an empty Domain and the \"/\" CookiePath", hostCookiePrefix)
+		}
+		fallthrough
+	case secureCookiePrefix:
+		if !cf.Secure {
+			return fmt.Errorf("session: the %s cookie prefix requires the Secure option", cf.CookiePrefix)
+		}
+	default:
+		return fmt.Errorf("session: invalid cookie prefix %q, use %s or %s", cf.CookiePrefix, hostCookiePrefix, secureCookiePrefix)
+	}
+
+	return nil
+}
+
+const (
+	hostCookiePrefix   = "__Host-"
+	secureCookiePrefix = "__Secure-"
+)
+
+var sameSiteModes = map[string]http.SameSite{
+	"":       http.SameSiteDefaultMode,
+	"lax":    http.SameSiteLaxMode,
+	"strict": http.SameSiteStrictMode,
+	"none":   http.SameSiteNoneMode,
+}
+
+// cookieName returns the name of the session id cookie, including its prefix.
+func (manager *Manager) cookieName() string {
+	return manager.config.CookiePrefix + manager.config.CookieName
+}
+
+// newCookie returns the session id cookie of the "sid",
+// its attributes are the same on start, regenerate and destroy.
+func (manager *Manager) newCookie(r *http.Request, sid string) *http.Cookie {
+	return manager.cookie(sid, manager.isSecure(r))
+}
+
+// cookie returns the session's cookie of the "value", the session id
+// or, for the providers which store the values in the cookie, the encoded values.
+func (manager *Manager) cookie(value string, secure bool) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     manager.cookieName(),
+		Value:    url.QueryEscape(value),
+		Path:     manager.config.CookiePath,
+		HttpOnly: !manager.config.DisableHTTPOnly,
+		Secure:   secure,
+		Domain:   manager.config.Domain,
+		SameSite: sameSiteModes[strings.ToLower(manager.config.SameSite)],
+	}
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+	if manager.config.CookiePrefix != "" || cookie.SameSite == http.SameSiteNoneMode {
+		// the browsers reject prefixed and SameSite=None cookies without the Secure attribute,
+		// the request may be served over TLS by a proxy.
+		cookie.Secure = true
+	}
+	if manager.config.CookieLifeTime > 0 {
+		cookie.MaxAge = manager.config.CookieLifeTime
+		cookie.Expires = time.Now().Add(time.Duration(manager.config.CookieLifeTime) * time.Second)
+	}
+	return cookie
+}
+
+// fingerprintKey is the store's key of the session's client fingerprint.
+const fingerprintKey = "_siris_fingerprint"
+
+type clientIPContextKey struct{}
+
+// WithClientIP returns a copy of the "ctx" which carries the client's "ip",
+// the session's fingerprint uses it instead of the request's RemoteAddr.
+//
+// Usage:
+// manager.Start(sessions.WithClientIP(r.Context(), realIP), w, r)
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPContextKey{}, ip)
+}
+
+// clientIP returns the IP of the `WithClientIP`, otherwise the request's RemoteAddr.
+func clientIP(ctx context.Context, r *http.Request) string {
+	if ip, ok := ctx.Value(clientIPContextKey{}).(string); ok && ip != "" {
+		return ip
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// fingerprint returns the hash of the client's User-Agent and network,
+// empty if the fingerprint is disabled.
+func (manager *Manager) fingerprint(ctx context.Context, r *http.Request) string {
+	cf := manager.config
+	if !cf.FingerprintUserAgent && cf.FingerprintIPv4Prefix == 0 && cf.FingerprintIPv6Prefix == 0 {
+		return ""
+	}
+
+	h := sha256.New()
+	if cf.FingerprintUserAgent {
+		io.WriteString(h, r.UserAgent())
+	}
+	h.Write([]byte{0})
+
+	if ip := net.ParseIP(clientIP(ctx, r)); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			if cf.FingerprintIPv4Prefix > 0 {
+				h.Write(ip4.Mask(net.CIDRMask(cf.FingerprintIPv4Prefix, 32)))
+			}
+		} else if cf.FingerprintIPv6Prefix > 0 {
+			h.Write(ip.Mask(net.CIDRMask(cf.FingerprintIPv6Prefix, 128)))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// matchFingerprint reports whether the session's fingerprint is the "fingerprint",
+// a session without fingerprint takes it. It's always true if the fingerprint is disabled.
+func matchFingerprint(session Store, fingerprint string) bool {
+	if fingerprint == "" {
+		return true
+	}
+	if stored, ok := session.Get(fingerprintKey).(string); ok && stored != "" {
+		return stored == fingerprint
+	}
+	session.Set(fingerprintKey, fingerprint)
+	return true
+}
+
+// withTimeout returns a context of the "parent" which is canceled after the ProviderTimeout, if any.
+func (manager *Manager) withTimeout(parent context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(parent, manager.config.ProviderTimeout)
+}
+
+func withTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
 func (manager *Manager) sessionID() (string, error) {
 	b := make([]byte, manager.config.SessionIDLength)
 	n, err := rand.Read(b)
@@ -0,0 +1,252 @@
+// Copyright 2017 Go-SIRIS Authors. All Rights Reserved.
+
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// flashesKey is the store's key of the flash messages
+// which were set on a previous request and not read yet.
+const flashesKey = "_siris_flashes"
+
+// StoreVisitor is implemented by the stores which can iterate over their values,
+// all the built'n stores implement it.
+//
+// See `Session#Visit` and `Session#Keys`.
+type StoreVisitor interface {
+	Visit(visitor func(key, value interface{}))
+}
+
+// Session is the request's view of a Store, it's returned by the `context#RequestSession`.
+//
+// It keeps track of the modifications, the store is released, saved,
+// at the end of the request only if its values were modified.
+// It provides typed getters and flash messages as well.
+type Session struct {
+	Store
+	dirty bool
+	// flashes that were set on the previous requests, readable once.
+	flashes map[string]interface{}
+	// flashes that were set on this request, readable on the next requests.
+	pending map[string]interface{}
+}
+
+// NewSession returns a new request Session of the "store".
+func NewSession(store Store) *Session {
+	s := &Session{Store: store}
+	if flashes, ok := store.Get(flashesKey).(map[string]interface{}); ok && len(flashes) > 0 {
+		s.flashes = make(map[string]interface{}, len(flashes))
+		for k, v := range flashes {
+			s.flashes[k] = v
+		}
+	}
+	return s
+}
+
+// IsDirty reports whether the session's values were modified during this request.
+func (s *Session) IsDirty() bool {
+	return s.dirty
+}
+
+// Set sets a session value.
+func (s *Session) Set(key, value interface{}) error {
+	s.dirty = true
+	return s.Store.Set(key, value)
+}
+
+// Delete removes a session value.
+func (s *Session) Delete(key interface{}) error {
+	s.dirty = true
+	return s.Store.Delete(key)
+}
+
+// Flush removes all the session values, flash messages are removed too.
+func (s *Session) Flush() error {
+	s.dirty = true
+	s.flashes = nil
+	s.pending = nil
+	return s.Store.Flush()
+}
+
+// Release saves the session values, if modified, using the store's Release if it's a `StoreReleaser`
+// or its SessionRelease otherwise, which doesn't report any error.
+// If not modified, it closes the store if it's an `io.Closer`, i.e the database ones.
+//
+// It's called automatically by the framework at the end of the request, before the response is flushed,
+// note that stores which write to the response, like the cookie one, can't send their cookie
+// after the response's headers, they should be released manually before the response body, it's logged otherwise.
+func (s *Session) Release(ctx context.Context, w http.ResponseWriter) error {
+	if s.dirty {
+		s.dirty = false
+		if releaser, ok := s.Store.(StoreReleaser); ok {
+			return releaser.Release(ctx, w)
+		}
+		s.Store.SessionRelease(w)
+		return nil
+	}
+
+	if closer, ok := s.Store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// SetUserID sets the id of the session's user.
+//
+// Use the `Manager#Login` or the `context#SessionLogin` instead on login,
+// to regenerate the session id and index the session under the user.
+func (s *Session) SetUserID(uid string) error {
+	return s.Set(UserIDKey, uid)
+}
+
+// UserID returns the id of the session's user, empty string if not bound.
+func (s *Session) UserID() string {
+	return s.GetString(UserIDKey)
+}
+
+// GetString returns the session value of the "key" as string,
+// empty string if not found or if it's not a string.
+func (s *Session) GetString(key interface{}) string {
+	switch v := s.Get(key).(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case fmt.Stringer:
+		return v.String()
+	}
+	return ""
+}
+
+// GetInt returns the session value of the "key" as int,
+// a string value is parsed, as well as the numbers of the JSON and the Msgpack serializers.
+func (s *Session) GetInt(key interface{}) (int, error) {
+	switch v := s.Get(key).(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case int32:
+		return int(v), nil
+	case uint64:
+		return int(v), nil
+	case float64: // decoded by the JSON serializer.
+		return int(v), nil
+	case string:
+		return strconv.Atoi(v)
+	case nil:
+		return -1, fmt.Errorf("session: value for key %v not found", key)
+	default:
+		return -1, fmt.Errorf("session: value for key %v is %T, not int", key, v)
+	}
+}
+
+// GetBool returns the session value of the "key" as bool,
+// a string value is parsed.
+func (s *Session) GetBool(key interface{}) (bool, error) {
+	switch v := s.Get(key).(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(v)
+	case nil:
+		return false, fmt.Errorf("session: value for key %v not found", key)
+	default:
+		return false, fmt.Errorf("session: value for key %v is %T, not bool", key, v)
+	}
+}
+
+// Visit calls the visitor for each session value,
+// flash messages, the user id and the client's fingerprint are excluded.
+// It does nothing if the store doesn't implement the `StoreVisitor`.
+func (s *Session) Visit(visitor func(key, value interface{})) {
+	if v, ok := s.Store.(StoreVisitor); ok {
+		v.Visit(func(key, value interface{}) {
+			if key != flashesKey && key != UserIDKey && key != fingerprintKey {
+				visitor(key, value)
+			}
+		})
+	}
+}
+
+// Keys returns the keys of the session values, see `Visit`.
+func (s *Session) Keys() (keys []interface{}) {
+	s.Visit(func(key, value interface{}) {
+		keys = append(keys, key)
+	})
+	return
+}
+
+// SetFlash sets a flash message,
+// it can be read once, by the `GetFlash`, on the next requests.
+// Therefore, it survives redirects.
+func (s *Session) SetFlash(key string, value interface{}) {
+	if s.pending == nil {
+		s.pending = make(map[string]interface{})
+	}
+	s.pending[key] = value
+	s.saveFlashes()
+}
+
+// HasFlash reports whether a flash message of the "key",
+// which was set on a previous request, exists and was not read yet.
+func (s *Session) HasFlash(key string) bool {
+	_, ok := s.flashes[key]
+	return ok
+}
+
+// GetFlash returns and removes the flash message of the "key",
+// which was set on a previous request, or nil if not found.
+func (s *Session) GetFlash(key string) interface{} {
+	v, ok := s.flashes[key]
+	if !ok {
+		return nil
+	}
+
+	delete(s.flashes, key)
+	s.saveFlashes()
+	return v
+}
+
+// GetFlashString returns and removes the flash message of the "key" as string,
+// empty string if not found or if it's not a string.
+func (s *Session) GetFlashString(key string) string {
+	v, _ := s.GetFlash(key).(string)
+	return v
+}
+
+// GetFlashes returns and removes all the flash messages
+// which were set on the previous requests.
+func (s *Session) GetFlashes() map[string]interface{} {
+	flashes := s.flashes
+	if len(flashes) == 0 {
+		return map[string]interface{}{}
+	}
+
+	s.flashes = nil
+	s.saveFlashes()
+	return flashes
+}
+
+// saveFlashes saves the unread and the new flash messages to the store.
+func (s *Session) saveFlashes() {
+	flashes := make(map[string]interface{}, len(s.flashes)+len(s.pending))
+	for k, v := range s.flashes {
+		flashes[k] = v
+	}
+	for k, v := range s.pending {
+		flashes[k] = v
+	}
+
+	s.dirty = true
+	if len(flashes) == 0 {
+		s.Store.Delete(flashesKey)
+		return
+	}
+	s.Store.Set(flashesKey, flashes)
+}
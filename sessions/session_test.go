@@ -3,6 +3,9 @@
 package sessions
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -14,3 +17,229 @@ func TestSession_RandomCreateBytes(t *testing.T) {
 	_ = RandomCreateBytes(512)
 	_ = RandomCreateBytes(1024)
 }
+
+func TestSessionCookieAttributes(t *testing.T) {
+	globalSessions, err := NewManager("memory", &ManagerConfig{
+		CookieName:      "gosessionid",
+		EnableSetCookie: true,
+		DisableHTTPOnly: true,
+		Gclifetime:      3600,
+		CookiePath:      "/admin",
+		SameSite:        "Strict",
+		CookiePrefix:    "__Secure-",
+		Secure:          true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := func(cookies []*http.Cookie, step string) *http.Cookie {
+		if len(cookies) != 1 {
+			t.Fatalf("[%s] expected one cookie but got %d", step, len(cookies))
+		}
+		c := cookies[0]
+		if c.Name != "__Secure-gosessionid" || c.Path != "/admin" || c.SameSite != http.SameSiteStrictMode || !c.Secure || c.HttpOnly {
+			t.Fatalf("[%s] unexpected cookie: %s", step, c.String())
+		}
+		return c
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/admin", nil)
+	if _, err = globalSessions.SessionStart(w, r); err != nil {
+		t.Fatal(err)
+	}
+	c := expect(w.Result().Cookies(), "start")
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/admin", nil)
+	r.AddCookie(c)
+	globalSessions.SessionRegenerateID(w, r)
+	expect(w.Result().Cookies(), "regenerate")
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/admin", nil)
+	r.AddCookie(c)
+	globalSessions.SessionDestroy(w, r)
+	if c = expect(w.Result().Cookies(), "destroy"); c.MaxAge != -1 {
+		t.Fatalf("expected the cookie to be removed: %s", c.String())
+	}
+}
+
+func TestSessionCookieValidation(t *testing.T) {
+	configs := []*ManagerConfig{
+		{CookieName: "gosessionid", SameSite: "sometimes"},
+		{CookieName: "gosessionid", CookiePrefix: "__Secure-"},
+		{CookieName: "gosessionid", CookiePrefix: "__Host-", Secure: true, CookiePath: "/admin"},
+		{CookieName: "gosessionid", CookiePrefix: "__Host-", Secure: true, Domain: "example.com"},
+		{CookieName: "gosessionid", CookiePrefix: "__Other-", Secure: true},
+	}
+
+	for i, cf := range configs {
+		if _, err := NewManager("memory", cf); err == nil {
+			t.Fatalf("[%d] expected an error", i)
+		}
+	}
+}
+
+func TestSessionLogin(t *testing.T) {
+	manager, err := NewManager("memory", &ManagerConfig{
+		CookieName:         "gosessionid",
+		EnableSetCookie:    true,
+		Gclifetime:         3600,
+		MaxSessionsPerUser: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	start := func() (*http.Request, Store) {
+		r := httptest.NewRequest("GET", "/", nil)
+		store, err := manager.Start(ctx, httptest.NewRecorder(), r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r, store
+	}
+
+	var sids []string
+	for i := 0; i < 3; i++ {
+		r, anonymous := start()
+		anonymousID := anonymous.SessionID()
+		store, err := manager.Login(ctx, httptest.NewRecorder(), r, "login-user")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// against session fixation.
+		if store.SessionID() == anonymousID {
+			t.Fatalf("expected a new session id on login")
+		}
+		sids = append(sids, store.SessionID())
+	}
+
+	got, err := manager.UserSessions(ctx, "login-user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the oldest session is destroyed.
+	if len(got) != 2 || got[0] != sids[1] || got[1] != sids[2] {
+		t.Fatalf("expected the sessions %v but got %v", sids[1:], got)
+	}
+	if exists, _ := mempder.Exists(ctx, sids[0]); exists {
+		t.Fatalf("expected the oldest session to be destroyed")
+	}
+
+	// log out the other devices.
+	if err = manager.DestroyOtherSessions(ctx, "login-user", sids[2]); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = manager.UserSessions(ctx, "login-user"); len(got) != 1 || got[0] != sids[2] {
+		t.Fatalf("expected the session %s only but got %v", sids[2], got)
+	}
+
+	if _, err = NewManager("cookie", &ManagerConfig{CookieName: "gosessionid", MaxSessionsPerUser: 1, ProviderConfig: "{}"}); err == nil {
+		t.Fatalf("expected an error for a provider without users' sessions index")
+	}
+}
+
+func TestSessionLoginOldest(t *testing.T) {
+	manager, err := NewManager("memory", &ManagerConfig{
+		CookieName:         "gosessionid",
+		Gclifetime:         3600,
+		MaxSessionsPerUser: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	login := func(sid string) string {
+		r := httptest.NewRequest("GET", "/", nil)
+		if sid != "" {
+			r.AddCookie(&http.Cookie{Name: "gosessionid", Value: sid})
+		}
+		session, err := manager.Login(ctx, httptest.NewRecorder(), r, "oldest-user")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return session.SessionID()
+	}
+
+	oldest, newest := login(""), login("")
+	// the limit is lowered and the oldest session logs in again.
+	manager.config.MaxSessionsPerUser = 1
+	sid := login(oldest)
+
+	if got, _ := manager.UserSessions(ctx, "oldest-user"); len(got) != 1 || got[0] != sid {
+		t.Fatalf("expected the session %s only but got %v", sid, got)
+	}
+	if exists, _ := mempder.Exists(ctx, newest); exists {
+		t.Fatalf("expected the other session to be destroyed")
+	}
+}
+
+func TestSessionFingerprint(t *testing.T) {
+	manager, err := NewManager("memory", &ManagerConfig{
+		CookieName:            "gosessionid",
+		Gclifetime:            3600,
+		FingerprintUserAgent:  true,
+		FingerprintIPv4Prefix: 24,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	request := func(sid, userAgent, remoteAddr string) Store {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("User-Agent", userAgent)
+		r.RemoteAddr = remoteAddr
+		if sid != "" {
+			r.AddCookie(&http.Cookie{Name: "gosessionid", Value: sid})
+		}
+		store, err := manager.Start(ctx, httptest.NewRecorder(), r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store
+	}
+
+	// the fingerprint of a new session is saved on its release.
+	if session, err := manager.StartSession(ctx, httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil)); err != nil || !session.IsDirty() {
+		t.Fatalf("expected the new session's fingerprint to be marked as modified, %v", err)
+	}
+
+	sid := request("", "browser", "10.0.0.1:1234").SessionID()
+	if got := request(sid, "browser", "10.0.0.2:4321").SessionID(); got != sid {
+		t.Fatalf("expected the same session on the same network")
+	}
+
+	tests := []struct {
+		userAgent, remoteAddr string
+	}{
+		{"another browser", "10.0.0.1:1234"},
+		{"browser", "10.0.1.1:1234"},
+	}
+	for i, tt := range tests {
+		if got := request(sid, tt.userAgent, tt.remoteAddr).SessionID(); got == sid {
+			t.Fatalf("[%d] expected a new session on a fingerprint mismatch", i)
+		}
+		if exists, _ := mempder.Exists(ctx, sid); exists {
+			t.Fatalf("[%d] expected the session to be destroyed", i)
+		}
+		sid = request("", "browser", "10.0.0.1:1234").SessionID()
+	}
+
+	// behind a proxy the client's IP of the context is checked instead of the proxy's RemoteAddr.
+	ctx = WithClientIP(context.Background(), "10.0.0.1")
+	sid = request("", "browser", "192.168.1.1:1234").SessionID()
+	ctx = WithClientIP(context.Background(), "10.0.1.1")
+	if got := request(sid, "browser", "192.168.1.1:1234").SessionID(); got == sid {
+		t.Fatalf("expected a new session on a client's IP mismatch behind the same proxy")
+	}
+
+	if _, err = NewManager("memory", &ManagerConfig{CookieName: "gosessionid", FingerprintIPv4Prefix: 33}); err == nil {
+		t.Fatalf("expected an error for an invalid prefix")
+	}
+}
@@ -11,7 +11,7 @@ import (
 	"github.com/ssdb/gossdb/ssdb"
 )
 
-var ssdbProvider = &Provider{}
+var ssdbProvider = &Provider{serializer: sessions.DefaultSerializer}
 
 // Provider holds ssdb client and configs
 type Provider struct {
@@ -19,6 +19,12 @@ type Provider struct {
 	host        string
 	port        int
 	maxLifetime int64
+	serializer  sessions.Serializer
+}
+
+// SetSerializer sets the serializer of the saved session values.
+func (p *Provider) SetSerializer(s sessions.Serializer) {
+	p.serializer = sessions.SerializerOrDefault(s)
 }
 
 func (p *Provider) connectInit() error {
@@ -58,7 +64,7 @@ func (p *Provider) SessionRead(sid string) (sessions.Store, error) {
 	if value == nil || len(value.(string)) == 0 {
 		kv = make(map[interface{}]interface{})
 	} else {
-		kv, err = sessions.DecodeGob([]byte(value.(string)))
+		kv, err = p.serializer.Deserialize([]byte(value.(string)))
 		if err != nil {
 			return nil, err
 		}
@@ -100,7 +106,7 @@ func (p *Provider) SessionRegenerate(oldsid, sid string) (sessions.Store, error)
 	if value == nil || len(value.(string)) == 0 {
 		kv = make(map[interface{}]interface{})
 	} else {
-		kv, err = sessions.DecodeGob([]byte(value.(string)))
+		kv, err = p.serializer.Deserialize([]byte(value.(string)))
 		if err != nil {
 			return nil, err
 		}
@@ -180,6 +186,20 @@ func (s *SessionStore) Flush() error {
 	return nil
 }
 
+// Visit calls the visitor for each session value.
+func (s *SessionStore) Visit(visitor func(key, value interface{})) {
+	s.lock.RLock()
+	values := make(map[interface{}]interface{}, len(s.values))
+	for k, v := range s.values {
+		values[k] = v
+	}
+	s.lock.RUnlock()
+
+	for k, v := range values {
+		visitor(k, v)
+	}
+}
+
 // SessionID return the sessionID
 func (s *SessionStore) SessionID() string {
 	return s.sid
@@ -187,7 +207,7 @@ func (s *SessionStore) SessionID() string {
 
 // SessionRelease Store the keyvalues into ssdb
 func (s *SessionStore) SessionRelease(w http.ResponseWriter) {
-	b, err := sessions.EncodeGob(s.values)
+	b, err := ssdbProvider.serializer.Serialize(s.values)
 	if err != nil {
 		return
 	}
@@ -7,6 +7,7 @@ package siris
 import (
 	// std packages
 	stdContext "context"
+	"fmt"
 	"io"
 	"log"
 	"net"
@@ -118,6 +119,9 @@ type Application struct {
 	// sessions messages
 	sessions *sessions.Manager
 
+	// validator of the context's readers, defaults to nil, see `AttachValidator`.
+	validator context.Validator
+
 	// used for build
 	once sync.Once
 
@@ -225,12 +229,19 @@ func (app *Application) Logger() *zap.SugaredLogger {
 // and the template functions that are very-closed to siris.
 func (app *Application) Build() (err error) {
 	app.once.Do(func() {
+		// parse the trusted proxies once, they're checked on each request.
+		err = app.config.ParseTrustedProxies()
+		if err != nil {
+			return
+		}
+
 		// view engine
 		// here is where we declare the closed-relative framework functions.
 		// Each engine has their defaults, i.e yield,render,render_r,partial, params...
 		rv := router.NewRoutePathReverser(app.APIBuilder)
 		app.view.AddFunc("urlpath", rv.Path)
-		// app.view.AddFunc("url", rv.URL)
+		app.view.AddFunc("urlpath_params", app.urlPathFunc)
+		app.view.AddFunc("url", app.urlFunc)
 		err = app.view.Load()
 		if err != nil {
 			return // if view engine loading failed then don't continue
@@ -251,6 +262,70 @@ func (app *Application) Build() (err error) {
 	return
 }
 
+// URL returns the url of a registered route based on its name
+// and the values of its named parameters, the rest of the "params"
+// are appended as query string.
+// Each value is validated against its parameter's macro type and functions.
+//
+// Subdomain routes are being resolved to absolute urls based on the `Configuration#VHost`,
+// the value of a wildcard subdomain is taken from the "subdomain" key of the "params".
+// The rest of the routes are being resolved to their path, like `URLPath`.
+//
+// Usage:
+// app.Get("/users/{id:int}", userHandler).Name = "user"
+// app.URL("user", siris.URLParams{"id": 42, "page": 2}) // "/users/42?page=2", nil
+//
+// It's also available as "url" function on the view engines,
+// and the `URLPath` as "urlpath_params":
+// {{ url "user" "id" 42 "page" 2 }}
+// and, on the handlebars engine, as {{ url "user" id=42 page=2 }}.
+func (app *Application) URL(routeName string, params router.URLParams) (string, error) {
+	rv := router.NewRoutePathReverser(app.APIBuilder, router.WithHost(app.config.GetVHost()))
+	return rv.NamedURL(routeName, params)
+}
+
+var (
+	errURLFuncPairs = errors.New("url: odd number of key-value pairs for route '%s'")
+	errURLFuncKey   = errors.New("url: parameter key '%v' of route '%s' is not a string")
+)
+
+// urlFunc is the "url" view function,
+// it accepts the route name followed by key-value pairs of its params.
+func (app *Application) urlFunc(routeName string, pairs ...interface{}) (string, error) {
+	params, err := urlFuncParams(routeName, pairs)
+	if err != nil {
+		return "", err
+	}
+	return app.URL(routeName, params)
+}
+
+// urlPathFunc is the "urlpath_params" view function, like the urlFunc but it returns the path.
+// The "urlpath" view function takes the values of the route's parameters in order.
+func (app *Application) urlPathFunc(routeName string, pairs ...interface{}) (string, error) {
+	params, err := urlFuncParams(routeName, pairs)
+	if err != nil {
+		return "", err
+	}
+	return app.URLPath(routeName, params)
+}
+
+// urlFuncParams returns the params of the key-value pairs of the view functions.
+func urlFuncParams(routeName string, pairs []interface{}) (router.URLParams, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errURLFuncPairs.Format(routeName)
+	}
+
+	params := make(router.URLParams, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, errURLFuncKey.Format(pairs[i], routeName)
+		}
+		params[key] = pairs[i+1]
+	}
+	return params, nil
+}
+
 // ConfigureHost accepts one or more `host#Configuration`, these configurators functions
 // can access the host created by `app.Run`,
 // they're being executed when application is ready to being served to the public.
@@ -310,7 +385,11 @@ func (app *Application) NewHost(srv *http.Server) *host.Supervisor {
 
 	if !app.config.DisableBanner {
 		// show the banner and the available keys to exit from app.
-		su.RegisterOnServeHook(host.WriteStartupLogOnServe(app.Logger(), banner+"V"+Version))
+		var routes []fmt.Stringer
+		if !app.config.DisableRouteTable {
+			routes = append(routes, app.RouteTable())
+		}
+		su.RegisterOnServeHook(host.WriteStartupLogOnServe(app.Logger(), banner+"V"+Version, routes...))
 	}
 
 	// the below schedules some tasks that will run among the server
@@ -547,6 +626,7 @@ func (app *Application) View(writer io.Writer, filename string, layout string, b
 }
 
 // AttachSessionManager registers a session manager to the framework which is used for flash messages too.
+// The sessions are released, their values are saved, at the end of each request if modified.
 //
 // See context.Session too.
 func (app *Application) AttachSessionManager(provider string, cfg *sessions.ManagerConfig) {
@@ -556,9 +636,6 @@ func (app *Application) AttachSessionManager(provider string, cfg *sessions.Mana
 	}
 	app.sessions = manager
 	go app.sessions.GC()
-	app.Done(func(ctx context.Context) {
-		ctx.Session().SessionRelease(ctx.ResponseWriter())
-	})
 }
 
 // SessionManager returns the session manager which contain a Start and Destroy methods
@@ -573,6 +650,22 @@ func (app *Application) SessionManager() (*sessions.Manager, error) {
 	return app.sessions, nil
 }
 
+// AttachValidator sets the validator of the values which are read by the context's
+// ReadJSON, ReadXML, ReadForm, ReadQuery and Bind, i.e the tag-based validator of the siris/validator package.
+// The validation is disabled by default, a nil validator disables it again.
+//
+// Usage:
+// app.AttachValidator(validator.New())
+func (app *Application) AttachValidator(v context.Validator) {
+	app.validator = v
+}
+
+// Validator returns the validator of the values which are read by the context's
+// ReadJSON, ReadXML, ReadForm, ReadQuery and Bind, it's nil if the validation is disabled.
+func (app *Application) Validator() context.Validator {
+	return app.validator
+}
+
 // SPA  accepts an "assetHandler" which can be the result of an
 // app.StaticHandler or app.StaticEmbeddedHandler.
 // It wraps the router and checks:
@@ -0,0 +1,72 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package validator
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-siris/siris/context"
+)
+
+// FieldError is the failure of a field's rule.
+type FieldError struct {
+	// Field is the name of the field, as in its json, xml, form, formam or url tag,
+	// the nested fields are separated by dots, i.e "address.city" or "items[0].name".
+	Field string `json:"field" xml:"field"`
+	// Tag is the failed rule, i.e "required".
+	Tag string `json:"tag" xml:"tag"`
+	// Param is the parameter of the rule, i.e "1" of the "min=1".
+	Param   string `json:"param,omitempty" xml:"param,omitempty"`
+	Message string `json:"message" xml:"message"`
+}
+
+// ValidationErrors is the error of the Validate, and so of the context's readers,
+// it contains the failures of the invalid fields, one per field.
+type ValidationErrors []FieldError
+
+// Error returns the messages of the failures, separated by semicolons.
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+const (
+	// ProblemContentType is the Content-Type of the problem responses.
+	ProblemContentType = "application/problem+json"
+	// ProblemType is the type of the validation problems.
+	ProblemType = "about:blank"
+)
+
+// Problem is the RFC 7807 problem details of the validation errors.
+type Problem struct {
+	Type   string           `json:"type"`
+	Title  string           `json:"title"`
+	Status int              `json:"status"`
+	Detail string           `json:"detail,omitempty"`
+	Errors ValidationErrors `json:"errors"`
+}
+
+// Problem returns the problem details of the validation errors.
+func (errs ValidationErrors) Problem() Problem {
+	return Problem{
+		Type:   ProblemType,
+		Title:  http.StatusText(http.StatusUnprocessableEntity),
+		Status: http.StatusUnprocessableEntity,
+		Detail: errs.Error(),
+		Errors: errs,
+	}
+}
+
+// WriteProblem writes the problem details of the validation errors as a 422 application/problem+json response,
+// the one error shape of the invalid requests.
+func (errs ValidationErrors) WriteProblem(ctx context.Context) (int, error) {
+	ctx.StatusCode(http.StatusUnprocessableEntity)
+	ctx.ContentType(ProblemContentType)
+	return context.WriteJSON(ctx.ResponseWriter(), errs.Problem(), context.JSON{}, false)
+}
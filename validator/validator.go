@@ -0,0 +1,329 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package validator provides a tag-based context.Validator,
+// the validation is enabled by the Application's AttachValidator:
+//
+//	app.AttachValidator(validator.New())
+//
+// The rules of a struct field are declared on its "validate" tag, separated by commas,
+// the parameter of a rule follows the '=':
+//
+//	type User struct {
+//		Name  string `json:"name" validate:"required,min=1,max=64"`
+//		Email string `json:"email" validate:"required,email"`
+//		Role  string `json:"role" validate:"omitempty,oneof=admin user"`
+//	}
+//
+// The ctx.ReadJSON, ReadXML, ReadForm, ReadQuery and Bind validate the decoded value
+// and return the `ValidationErrors`, which can be written as a 422 problem response:
+//
+//	if err := ctx.ReadJSON(&user); err != nil {
+//		if errs, ok := err.(validator.ValidationErrors); ok {
+//			errs.WriteProblem(ctx)
+//			return
+//		}
+//		ctx.StatusCode(siris.StatusBadRequest)
+//		return
+//	}
+package validator
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-siris/siris/context"
+)
+
+// DefaultTagName is the struct tag of the rules, if not any other set on the Validator's TagName.
+const DefaultTagName = "validate"
+
+// Func reports whether the field's value passes the rule, the "param" is the rule's parameter, if any.
+type Func func(field reflect.Value, param string) bool
+
+type rule struct {
+	fn Func
+	// message is the format of the FieldError's message, it receives the field's name and the rule's param.
+	message string
+}
+
+// Validator is the tag-based context.Validator.
+// The nested structs, pointers to structs and slices of structs are validated too.
+type Validator struct {
+	// TagName is the struct tag of the rules, defaults to the DefaultTagName.
+	TagName string
+	rules   map[string]rule
+}
+
+var _ context.Validator = (*Validator)(nil)
+
+// New returns a new tag-based Validator with the built'n rules:
+// required, omitempty, min, max, len, eq, ne, oneof, email, url, alpha, alphanum and numeric.
+//
+// The min, max and len rules compare the numbers by their value
+// and the strings, slices and maps by their length.
+func New() *Validator {
+	v := &Validator{TagName: DefaultTagName, rules: make(map[string]rule)}
+
+	v.RegisterFunc("required", isRequired, "%s is required")
+	v.RegisterFunc("min", func(f reflect.Value, param string) bool {
+		return compare(f, param, func(n, p float64) bool { return n >= p })
+	}, "%s must be at least %s")
+	v.RegisterFunc("max", func(f reflect.Value, param string) bool {
+		return compare(f, param, func(n, p float64) bool { return n <= p })
+	}, "%s must be at most %s")
+	v.RegisterFunc("len", func(f reflect.Value, param string) bool {
+		return compare(f, param, func(n, p float64) bool { return n == p })
+	}, "%s must have a length of %s")
+	v.RegisterFunc("eq", func(f reflect.Value, param string) bool {
+		return fmt.Sprint(f.Interface()) == param
+	}, "%s must be equal to %s")
+	v.RegisterFunc("ne", func(f reflect.Value, param string) bool {
+		return fmt.Sprint(f.Interface()) != param
+	}, "%s must not be equal to %s")
+	v.RegisterFunc("oneof", func(f reflect.Value, param string) bool {
+		s := fmt.Sprint(f.Interface())
+		for _, option := range strings.Fields(param) {
+			if s == option {
+				return true
+			}
+		}
+		return false
+	}, "%s must be one of [%s]")
+	v.RegisterFunc("email", func(f reflect.Value, param string) bool {
+		addr, err := mail.ParseAddress(f.String())
+		return err == nil && addr.Name == "" && addr.Address == f.String()
+	}, "%s must be a valid email address")
+	v.RegisterFunc("url", func(f reflect.Value, param string) bool {
+		u, err := url.ParseRequestURI(f.String())
+		return err == nil && u.Scheme != "" && u.Host != ""
+	}, "%s must be a valid URL")
+	v.RegisterFunc("alpha", runes(unicode.IsLetter), "%s must contain letters only")
+	v.RegisterFunc("alphanum", runes(func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}), "%s must contain letters and digits only")
+	v.RegisterFunc("numeric", func(f reflect.Value, param string) bool {
+		_, err := strconv.ParseFloat(f.String(), 64)
+		return err == nil
+	}, "%s must be numeric")
+
+	return v
+}
+
+// RegisterFunc registers a rule, it replaces the existing one of the same tag, including the built'n ones.
+// The "message" is the format of the FieldError's message, it receives the field's name and, on a second %s,
+// the rule's param, i.e "%s must be a valid username" or "%s must be greater than %s".
+func (v *Validator) RegisterFunc(tag string, fn Func, message string) {
+	v.rules[tag] = rule{fn: fn, message: message}
+}
+
+// Validate validates the fields of a struct, or a pointer to a struct, by their rules,
+// it returns nil if the value is valid or not a struct,
+// the `ValidationErrors` if any field is invalid or an error if a rule is not registered.
+func (v *Validator) Validate(obj interface{}) error {
+	val := reflect.ValueOf(obj)
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs ValidationErrors
+	if err := v.validateStruct(val, "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (v *Validator) validateStruct(val reflect.Value, prefix string, errs *ValidationErrors) error {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if sf.PkgPath != "" { // unexported.
+			continue
+		}
+
+		field := val.Field(i)
+		name := prefix + fieldName(sf)
+		if sf.Anonymous {
+			// the fields of an embedded struct are named as the fields of its parent.
+			name = strings.TrimSuffix(prefix, ".")
+		}
+
+		if tag := sf.Tag.Get(v.TagName); tag != "" && tag != "-" {
+			if err := v.validateField(field, name, tag, errs); err != nil {
+				return err
+			}
+		}
+
+		if err := v.validateNested(field, name, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateNested validates the structs of the field, if any.
+func (v *Validator) validateNested(field reflect.Value, name string, errs *ValidationErrors) error {
+	for field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	switch field.Kind() {
+	case reflect.Struct:
+		if field.Type().PkgPath() == "time" { // time.Time has no rules of its own.
+			return nil
+		}
+		if name != "" {
+			name += "."
+		}
+		return v.validateStruct(field, name, errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < field.Len(); i++ {
+			if err := v.validateNested(field.Index(i), name+"["+strconv.Itoa(i)+"]", errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *Validator) validateField(field reflect.Value, name string, tag string, errs *ValidationErrors) error {
+	rules := strings.Split(tag, ",")
+	for _, r := range rules {
+		if r == "omitempty" {
+			if !isRequired(field, "") {
+				return nil
+			}
+		}
+	}
+
+	// the rules of a nil pointer are checked against its zero value, the required fails.
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field = reflect.Zero(field.Type().Elem())
+			continue
+		}
+		field = field.Elem()
+	}
+
+	for _, r := range rules {
+		r = strings.TrimSpace(r)
+		if r == "" || r == "omitempty" {
+			continue
+		}
+
+		tagName, param := r, ""
+		if idx := strings.IndexByte(r, '='); idx != -1 {
+			tagName, param = r[:idx], r[idx+1:]
+		}
+
+		rl, ok := v.rules[tagName]
+		if !ok {
+			return fmt.Errorf("validator: unknown rule %q of the field %s", tagName, name)
+		}
+
+		if !rl.fn(field, param) {
+			*errs = append(*errs, FieldError{
+				Field:   name,
+				Tag:     tagName,
+				Param:   param,
+				Message: formatMessage(rl.message, name, param),
+			})
+			// report the first failed rule of each field.
+			return nil
+		}
+	}
+	return nil
+}
+
+// formatMessage formats the message of a failed rule,
+// the param is passed only if the message has a second verb, i.e "%s is required".
+func formatMessage(message, name, param string) string {
+	if strings.Count(message, "%s") < 2 {
+		return fmt.Sprintf(message, name)
+	}
+	return fmt.Sprintf(message, name, param)
+}
+
+// fieldName returns the name of the field in its json, xml, form, formam or url tag, or its Go name.
+func fieldName(sf reflect.StructField) string {
+	for _, key := range []string{"json", "xml", "form", "formam", "url"} {
+		if tag := sf.Tag.Get(key); tag != "" {
+			if name := strings.Split(tag, ",")[0]; name != "" && name != "-" {
+				return name
+			}
+		}
+	}
+	return sf.Name
+}
+
+func isRequired(f reflect.Value, param string) bool {
+	switch f.Kind() {
+	case reflect.Slice, reflect.Map:
+		return f.Len() > 0
+	case reflect.Ptr, reflect.Interface:
+		return !f.IsNil()
+	case reflect.Invalid:
+		return false
+	}
+	return !reflect.DeepEqual(f.Interface(), reflect.Zero(f.Type()).Interface())
+}
+
+// compare compares the number, or the length, of the field to the param.
+func compare(f reflect.Value, param string, cmp func(n, p float64) bool) bool {
+	p, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return false
+	}
+
+	var n float64
+	switch f.Kind() {
+	case reflect.String:
+		n = float64(utf8.RuneCountInString(f.String()))
+	case reflect.Slice, reflect.Map, reflect.Array:
+		n = float64(f.Len())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(f.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n = float64(f.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = f.Float()
+	default:
+		return false
+	}
+	return cmp(n, p)
+}
+
+// runes returns a rule which reports whether all the runes of a non-empty string satisfy the "is".
+func runes(is func(rune) bool) Func {
+	return func(f reflect.Value, param string) bool {
+		s := f.String()
+		if s == "" {
+			return false
+		}
+		for _, r := range s {
+			if !is(r) {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -0,0 +1,147 @@
+package validator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testAddress struct {
+	City string `json:"city" validate:"required"`
+	Zip  string `json:"zip" validate:"omitempty,len=5,numeric"`
+}
+
+type testItem struct {
+	Name     string `json:"name" validate:"required,alphanum"`
+	Quantity int    `json:"quantity" validate:"min=1,max=10"`
+}
+
+type testOrder struct {
+	Email    string       `json:"email" validate:"required,email"`
+	Website  string       `json:"website" validate:"omitempty,url"`
+	Status   string       `json:"status" validate:"oneof=new paid"`
+	Note     *string      `json:"note" validate:"required"`
+	Address  testAddress  `json:"address"`
+	Shipping *testAddress `json:"shipping"`
+	Items    []testItem   `json:"items" validate:"min=1"`
+	internal string       `validate:"required"`
+}
+
+func TestValidate(t *testing.T) {
+	v := New()
+	note := "leave at the door"
+
+	valid := testOrder{
+		Email:   "user@example.com",
+		Website: "https://example.com",
+		Status:  "paid",
+		Note:    &note,
+		Address: testAddress{City: "Athens", Zip: "10431"},
+		Items:   []testItem{{Name: "book1", Quantity: 2}},
+	}
+	if err := v.Validate(&valid); err != nil {
+		t.Fatalf("expected a valid order but got: %v", err)
+	}
+
+	invalid := testOrder{
+		Email:    "user@",
+		Website:  "example",
+		Status:   "cancelled",
+		Address:  testAddress{Zip: "1043a"},
+		Shipping: &testAddress{},
+		Items:    []testItem{{Name: "book1", Quantity: 2}, {Name: "bad name", Quantity: 11}},
+	}
+	err := v.Validate(invalid)
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors but got: %#v", err)
+	}
+
+	expected := []FieldError{
+		{Field: "email", Tag: "email"},
+		{Field: "website", Tag: "url"},
+		{Field: "status", Tag: "oneof", Param: "new paid"},
+		{Field: "note", Tag: "required"},
+		{Field: "address.city", Tag: "required"},
+		{Field: "address.zip", Tag: "numeric"},
+		{Field: "shipping.city", Tag: "required"},
+		{Field: "items[1].name", Tag: "alphanum"},
+		{Field: "items[1].quantity", Tag: "max", Param: "10"},
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors but got %d: %v", len(expected), len(errs), errs)
+	}
+	for i, e := range expected {
+		got := errs[i]
+		if got.Field != e.Field || got.Tag != e.Tag || got.Param != e.Param {
+			t.Fatalf("[%d] expected %s:%s=%s but got %s:%s=%s", i, e.Field, e.Tag, e.Param, got.Field, got.Tag, got.Param)
+		}
+		if !strings.HasPrefix(got.Message, got.Field+" ") {
+			t.Fatalf("[%d] expected the message to start with the field's name but got %q", i, got.Message)
+		}
+	}
+
+	if p := errs.Problem(); p.Status != 422 || !reflect.DeepEqual(p.Errors, errs) || p.Detail != errs.Error() {
+		t.Fatalf("unexpected problem: %#v", p)
+	}
+}
+
+// Coordinates is exported, the embedded structs of unexported types are not validated.
+type Coordinates struct {
+	Lat string `json:"lat" validate:"required"`
+}
+
+type testLocation struct {
+	Coordinates
+	Name string `json:"name" validate:"required"`
+}
+
+type testPlace struct {
+	Coordinates
+	Location testLocation `json:"location"`
+}
+
+func TestValidateEmbedded(t *testing.T) {
+	err := New().Validate(testPlace{})
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors but got: %#v", err)
+	}
+
+	expected := []string{"lat", "location.lat", "location.name"}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors but got %d: %v", len(expected), len(errs), errs)
+	}
+	for i, field := range expected {
+		if got := errs[i].Field; got != field {
+			t.Fatalf("[%d] expected the field %q but got %q", i, field, got)
+		}
+	}
+}
+
+func TestValidateCustomRule(t *testing.T) {
+	v := New()
+	v.RegisterFunc("lower", func(f reflect.Value, param string) bool {
+		return strings.ToLower(f.String()) == f.String()
+	}, "%s must be lower case")
+
+	type user struct {
+		Username string `form:"username" validate:"required,lower"`
+		Role     string `validate:"unknown"`
+	}
+
+	if err := v.Validate(user{Username: "Siris"}); err == nil || err.Error() == "username must be lower case" {
+		t.Fatalf("expected the unknown rule error but got: %v", err)
+	}
+
+	v.RegisterFunc("unknown", func(reflect.Value, string) bool { return true }, "")
+	err := v.Validate(&user{Username: "Siris"})
+	if errs, ok := err.(ValidationErrors); !ok || errs.Error() != "username must be lower case" {
+		t.Fatalf("expected the lower rule to fail but got: %v", err)
+	}
+
+	// non-structs have no rules.
+	if err := v.Validate(map[string]string{"a": "b"}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -68,8 +68,9 @@ func (s *AmberEngine) Reload(developmentMode bool) *AmberEngine {
 
 // AddFunc adds the function to the template's function map.
 // It is legal to overwrite elements of the default actions:
-// - url func(routeName string, args ...string) string
+// - url func(routeName string, params ...interface{}) (string, error)
 // - urlpath func(routeName string, args ...string) string
+// - urlpath_params func(routeName string, params ...interface{}) (string, error)
 // - render func(fullPartialName string) (template.HTML, error).
 func (s *AmberEngine) AddFunc(funcName string, funcBody interface{}) {
 	s.rmu.Lock()
@@ -114,8 +114,9 @@ func (s *DjangoEngine) Reload(developmentMode bool) *DjangoEngine {
 
 // AddFunc adds the function to the template's Globals.
 // It is legal to overwrite elements of the default actions:
-// - url func(routeName string, args ...string) string
+// - url func(routeName string, params ...interface{}) (string, error)
 // - urlpath func(routeName string, args ...string) string
+// - urlpath_params func(routeName string, params ...interface{}) (string, error)
 // - render func(fullPartialName string) (template.HTML, error).
 func (s *DjangoEngine) AddFunc(funcName string, funcBody interface{}) {
 	s.rmu.Lock()
@@ -123,6 +124,12 @@ func (s *DjangoEngine) AddFunc(funcName string, funcBody interface{}) {
 	s.rmu.Unlock()
 }
 
+// adaptFunc returns the framework's function without its error output,
+// the error is returned by the template's execution.
+func (s *DjangoEngine) adaptFunc(funcBody interface{}) interface{} {
+	return withoutErrorOutput(funcBody)
+}
+
 // AddFilter adds a filter to the template.
 func (s *DjangoEngine) AddFilter(filterName string, filterBody FilterFunction) *DjangoEngine {
 	s.rmu.Lock()
@@ -321,7 +328,10 @@ func (s *DjangoEngine) fromCache(relativeName string) *pongo2.Template {
 
 // ExecuteWriter executes a templates and write its results to the w writer
 // layout here is useless.
-func (s *DjangoEngine) ExecuteWriter(w io.Writer, filename string, layout string, bindingData interface{}) error {
+func (s *DjangoEngine) ExecuteWriter(w io.Writer, filename string, layout string, bindingData interface{}) (err error) {
+	// the errors of the functions, pongo2 can't return them.
+	defer recoverFuncError(&err)
+
 	// reload the templates if reload configuration field is true
 	if s.reload {
 		if err := s.Load(); err != nil {
@@ -4,10 +4,17 @@
 
 package view
 
+import (
+	"reflect"
+	"sort"
+
+	"github.com/aymerick/raymond"
+)
+
 // EngineFuncer is an addition of a view engine,
 // if a view engine implements that interface
 // then siris can add some closed-relative siris functions
-// like {{ urlpath }} and {{ urlpath }}.
+// like {{ url }} and {{ urlpath }}.
 type EngineFuncer interface {
 	// AddFunc should adds a function to the template's function map.
 	AddFunc(funcName string, funcBody interface{})
@@ -20,3 +27,108 @@ type EngineFuncer interface {
 // template engine because of the different behavior, i.e urlpath and url are inside framework itself,
 // yield,partial,partial_r,current and render as inside html engine etc...
 var defaultSharedFuncs = map[string]interface{}{}
+
+// funcAdapter is implemented by the engines which can't call the framework's functions as they are,
+// i.e with an error output, see `View#AddFunc`.
+// The functions of their own AddFunc are kept as they are.
+type funcAdapter interface {
+	adaptFunc(funcBody interface{}) interface{}
+}
+
+// funcError is the panic value of a function's error on the engines
+// which don't support the functions with an error output, see `withoutErrorOutput`.
+type funcError struct {
+	err error
+}
+
+func (e funcError) Error() string {
+	return e.err.Error()
+}
+
+// recoverFuncError recovers a funcError panic as the "err", the rest of the panics are re-thrown.
+func recoverFuncError(err *error) {
+	if r := recover(); r != nil {
+		fe, ok := r.(funcError)
+		if !ok {
+			panic(r)
+		}
+		*err = fe.err
+	}
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// withoutErrorOutput returns a function with only the first output of the "fn",
+// if the "fn" returns a value and an error, its error is thrown as a funcError panic.
+// The rest of the functions are returned as they are.
+func withoutErrorOutput(fn interface{}) interface{} {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.Kind() != reflect.Func || t.NumOut() != 2 || t.Out(1) != errorType {
+		return fn
+	}
+
+	in := make([]reflect.Type, t.NumIn())
+	for i := range in {
+		in[i] = t.In(i)
+	}
+
+	ft := reflect.FuncOf(in, []reflect.Type{t.Out(0)}, t.IsVariadic())
+	return reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+		var out []reflect.Value
+		if t.IsVariadic() {
+			out = v.CallSlice(args)
+		} else {
+			out = v.Call(args)
+		}
+		if err, _ := out[1].Interface().(error); err != nil {
+			panic(funcError{err})
+		}
+		return out[:1]
+	}).Interface()
+}
+
+var handlebarsOptionsType = reflect.TypeOf((*raymond.Options)(nil))
+
+// handlebarsHelper returns the "fn" as a handlebars helper, which can't have an error output
+// neither variadic arguments, see `withoutErrorOutput`.
+//
+// The variadic ...interface{} arguments are taken from the helper's hash as key-value pairs,
+// sorted by key, i.e {{ url "user" id=42 page=2 }} calls the "url" with "user", "id", 42, "page", 2.
+func handlebarsHelper(fn interface{}) interface{} {
+	v := reflect.ValueOf(withoutErrorOutput(fn))
+	t := v.Type()
+	if t.Kind() != reflect.Func || !t.IsVariadic() || t.In(t.NumIn()-1).Elem().Kind() != reflect.Interface {
+		return v.Interface()
+	}
+
+	fixed := t.NumIn() - 1
+	in := make([]reflect.Type, fixed+1)
+	for i := 0; i < fixed; i++ {
+		in[i] = t.In(i)
+	}
+	in[fixed] = handlebarsOptionsType
+
+	out := make([]reflect.Type, t.NumOut())
+	for i := range out {
+		out[i] = t.Out(i)
+	}
+
+	variadicType := t.In(fixed)
+	ft := reflect.FuncOf(in, out, false)
+	return reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+		hash := args[fixed].Interface().(*raymond.Options).Hash()
+		keys := make([]string, 0, len(hash))
+		for key := range hash {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		pairs := reflect.MakeSlice(variadicType, 0, len(keys)*2)
+		for _, key := range keys {
+			value := hash[key]
+			pairs = reflect.Append(pairs, reflect.ValueOf(key), reflect.ValueOf(&value).Elem())
+		}
+		return v.CallSlice(append(args[:fixed:fixed], pairs))
+	}).Interface()
+}
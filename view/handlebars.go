@@ -42,13 +42,13 @@ func Handlebars(directory, extension string) *HandlebarsEngine {
 	}
 
 	// register the render helper here
-	raymond.RegisterHelper("render", func(partial string, binding interface{}) raymond.SafeString {
+	s.helpers["render"] = func(partial string, binding interface{}) raymond.SafeString {
 		contents, err := s.executeTemplateBuf(partial, binding)
 		if err != nil {
 			return raymond.SafeString("template with name: " + partial + " couldn't not be found.")
 		}
 		return raymond.SafeString(contents)
-	})
+	}
 
 	return s
 }
@@ -84,17 +84,39 @@ func (s *HandlebarsEngine) Layout(layoutFile string) *HandlebarsEngine {
 	return s
 }
 
-// AddFunc adds the function to the template's function map.
+// AddFunc adds the function to the template's helpers.
 // It is legal to overwrite elements of the default actions:
-// - url func(routeName string, args ...string) string
+// - url func(routeName string, params ...interface{}) (string, error)
 // - urlpath func(routeName string, args ...string) string
-// - render func(fullPartialName string) (raymond.HTML, error).
+// - urlpath_params func(routeName string, params ...interface{}) (string, error)
+// - render func(partial string, binding interface{}) raymond.SafeString.
 func (s *HandlebarsEngine) AddFunc(funcName string, funcBody interface{}) {
 	s.rmu.Lock()
 	s.helpers[funcName] = funcBody
 	s.rmu.Unlock()
 }
 
+// adaptFunc returns the framework's function as a helper, its error is returned by the template's execution
+// and its variadic ...interface{} arguments are taken from the helper's hash,
+// i.e {{ url "user" id=42 page=2 }}.
+func (s *HandlebarsEngine) adaptFunc(funcBody interface{}) interface{} {
+	return handlebarsHelper(funcBody)
+}
+
+// parse parses a template and registers the helpers to it,
+// they are not registered globally so each engine has its own.
+func (s *HandlebarsEngine) parse(contents string) (*raymond.Template, error) {
+	tmpl, err := raymond.Parse(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	s.rmu.RLock()
+	tmpl.RegisterHelpers(s.helpers)
+	s.rmu.RUnlock()
+	return tmpl, nil
+}
+
 // Load parses the templates to the engine.
 // It's alos responsible to add the necessary global functions.
 //
@@ -118,11 +140,6 @@ func (s *HandlebarsEngine) Load() error {
 // loadDirectory builds the handlebars templates from directory.
 func (s *HandlebarsEngine) loadDirectory() error {
 
-	// register the global helpers on the first load
-	if len(s.templateCache) == 0 && s.helpers != nil {
-		raymond.RegisterHelpers(s.helpers)
-	}
-
 	dir, extension := s.directory, s.extension
 
 	// the render works like {{ render "myfile.html" theContext.PartialContext}}
@@ -152,7 +169,7 @@ func (s *HandlebarsEngine) loadDirectory() error {
 
 			name := filepath.ToSlash(rel)
 
-			tmpl, err := raymond.Parse(contents)
+			tmpl, err := s.parse(contents)
 			if err != nil {
 				templateErr = err
 				return err
@@ -167,11 +184,6 @@ func (s *HandlebarsEngine) loadDirectory() error {
 
 // loadAssets loads the templates by binary, embedded.
 func (s *HandlebarsEngine) loadAssets() error {
-	// register the global helpers
-	if len(s.templateCache) == 0 && s.helpers != nil {
-		raymond.RegisterHelpers(s.helpers)
-	}
-
 	virtualDirectory, virtualExtension := s.directory, s.extension
 	assetFn, namesFn := s.assetFn, s.namesFn
 
@@ -210,7 +222,7 @@ func (s *HandlebarsEngine) loadAssets() error {
 			contents := string(buf)
 			name := filepath.ToSlash(rel)
 
-			tmpl, err := raymond.Parse(contents)
+			tmpl, err := s.parse(contents)
 			if err != nil {
 				templateErr = err
 				return err
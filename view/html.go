@@ -170,8 +170,9 @@ func (s *HTMLEngine) AddLayoutFunc(funcName string, funcBody interface{}) *HTMLE
 
 // AddFunc adds the function to the template's function map.
 // It is legal to overwrite elements of the default actions:
-// - url func(routeName string, args ...string) string
+// - url func(routeName string, params ...interface{}) (string, error)
 // - urlpath func(routeName string, args ...string) string
+// - urlpath_params func(routeName string, params ...interface{}) (string, error)
 // - render func(fullPartialName string) (template.HTML, error).
 func (s *HTMLEngine) AddFunc(funcName string, funcBody interface{}) {
 	s.rmu.Lock()
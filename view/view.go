@@ -56,13 +56,18 @@ func (v *View) ExecuteWriter(w io.Writer, filename string, layout string, bindin
 	return e.ExecuteWriter(w, filename, layout, bindingData)
 }
 
-// AddFunc adds a function to all registered engines.
+// AddFunc adds a function to all registered engines,
+// it's adapted to the engines which can't call it as it is, i.e the handlebars.
 // Each template engine that supports functions has its own AddFunc too.
 func (v *View) AddFunc(funcName string, funcBody interface{}) {
 	for i, n := 0, len(v.engines); i < n; i++ {
 		e := v.engines[i]
 		if engineFuncer, ok := e.(EngineFuncer); ok {
-			engineFuncer.AddFunc(funcName, funcBody)
+			fn := funcBody
+			if adapter, ok := e.(funcAdapter); ok {
+				fn = adapter.adaptFunc(fn)
+			}
+			engineFuncer.AddFunc(funcName, fn)
 		}
 	}
 }
@@ -0,0 +1,196 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// -------------------------------------------------------------------------------------
+// -------------------------------------------------------------------------------------
+// --------------------------------Ask, request/reply messages--------------------------
+// -------------------------------------------------------------------------------------
+// -------------------------------------------------------------------------------------
+
+/*
+An ask is a custom message whose event is suffixed by the ask separator and the ask's id,
+siris-websocket-message:chat#ask:1;0;hello
+and its reply is a custom message of the reply, or the error reply, event of the same id.
+siris-websocket-message:#reply:1;0;hi
+siris-websocket-message:#error:1;0;the error message
+
+The peers which don't support asks just ignore them, as they don't have listeners for these events.
+*/
+
+// The same values are exists on client side also
+const (
+	websocketAskSeparator     = "#ask:"
+	websocketReplyPrefix      = "#reply:"
+	websocketErrorReplyPrefix = "#error:"
+)
+
+var (
+	// ErrAskTimeout is returned by the `Ask` when the reply is not received in time.
+	ErrAskTimeout = errors.New("websocket: ask timeout")
+	// errRemoteAsk is returned by the `Ask` of the connections of the other nodes of a Broker.
+	errRemoteAsk = errors.New("websocket: ask is not supported by the connections of other nodes")
+)
+
+// AskFunc is the callback which replies to the asks of an event, see `OnAsk`.
+// Receives the message, of type string, int, bool, []byte or the decoded JSON,
+// and returns the reply, of the same types as the Emit's message, or an error
+// which is returned by the other side's `Ask`.
+type AskFunc func(message interface{}) (reply interface{}, err error)
+
+type askReply struct {
+	message interface{}
+	err     error
+}
+
+// asks keeps the ask listeners and the pending asks of a connection,
+// it's used by the server's connections and by the Go client.
+type asks struct {
+	mu        sync.Mutex
+	nextID    uint64
+	pending   map[string]chan askReply
+	listeners map[string]AskFunc
+	// closed is set when the connection is closed for good, the next asks fail immediately.
+	closed bool
+}
+
+// on registers the listener of the event's asks, it replaces the previous one.
+func (a *asks) on(event string, cb AskFunc) {
+	a.mu.Lock()
+	if a.listeners == nil {
+		a.listeners = make(map[string]AskFunc)
+	}
+	a.listeners[event] = cb
+	a.mu.Unlock()
+}
+
+// ask sends the message as an ask of the event and waits for its reply,
+// a non-positive timeout waits until the reply or the disconnection.
+func (a *asks) ask(send func([]byte) error, event string, message interface{}, timeout time.Duration) (interface{}, error) {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil, ErrDisconnected
+	}
+	a.nextID++
+	id := strconv.FormatUint(a.nextID, 10)
+	ch := make(chan askReply, 1)
+	if a.pending == nil {
+		a.pending = make(map[string]chan askReply)
+	}
+	a.pending[id] = ch
+	a.mu.Unlock()
+
+	data, err := websocketMessageSerialize(event+websocketAskSeparator+id, message)
+	if err == nil {
+		err = send([]byte(data))
+	}
+	if err != nil {
+		a.remove(id)
+		return nil, err
+	}
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case reply := <-ch:
+		return reply.message, reply.err
+	case <-expired:
+		a.remove(id)
+		return nil, ErrAskTimeout
+	}
+}
+
+func (a *asks) remove(id string) chan askReply {
+	a.mu.Lock()
+	ch := a.pending[id]
+	delete(a.pending, id)
+	a.mu.Unlock()
+	return ch
+}
+
+// handle handles the custom message if it's an ask or a reply, it reports whether it was.
+// The asks are replied on a goroutine, so the listeners can ask the other side too.
+func (a *asks) handle(send func([]byte) error, event string, customData string) bool {
+	if strings.HasPrefix(event, websocketReplyPrefix) || strings.HasPrefix(event, websocketErrorReplyPrefix) {
+		isError := strings.HasPrefix(event, websocketErrorReplyPrefix)
+		id := event[strings.IndexByte(event, ':')+1:]
+		ch := a.remove(id)
+		if ch == nil { // expired.
+			return true
+		}
+
+		message, err := websocketMessageDeserialize(event, customData)
+		if err == nil && isError {
+			msg, _ := message.(string)
+			message, err = nil, errors.New(msg)
+		}
+		ch <- askReply{message: message, err: err}
+		return true
+	}
+
+	idx := strings.LastIndex(event, websocketAskSeparator)
+	if idx == -1 {
+		return false
+	}
+
+	id := event[idx+len(websocketAskSeparator):]
+	askEvent := event[:idx]
+	a.mu.Lock()
+	cb := a.listeners[askEvent]
+	a.mu.Unlock()
+
+	go func() {
+		var (
+			reply interface{}
+			err   error
+		)
+
+		if cb == nil {
+			err = errors.New("websocket: no ask listener for the event " + askEvent)
+		} else if message, derr := websocketMessageDeserialize(event, customData); derr != nil {
+			err = derr
+		} else {
+			reply, err = cb(message)
+		}
+
+		var data string
+		if err != nil {
+			data, _ = websocketMessageSerialize(websocketErrorReplyPrefix+id, err.Error())
+		} else if data, err = websocketMessageSerialize(websocketReplyPrefix+id, reply); err != nil {
+			data, _ = websocketMessageSerialize(websocketErrorReplyPrefix+id, err.Error())
+		}
+		send([]byte(data))
+	}()
+
+	return true
+}
+
+// cancel fails the pending asks with the ErrDisconnected, it's called on disconnection.
+// If "closed" then the next asks fail too, the Go client passes false as it reconnects.
+func (a *asks) cancel(closed bool) {
+	a.mu.Lock()
+	pending := a.pending
+	a.pending = nil
+	a.closed = closed
+	a.mu.Unlock()
+
+	for _, ch := range pending {
+		ch <- askReply{err: ErrDisconnected}
+	}
+}
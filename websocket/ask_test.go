@@ -0,0 +1,124 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-siris/siris"
+)
+
+func TestAsk(t *testing.T) {
+	ws := New(Config{Endpoint: "/ws"})
+	serverReplies := make(chan interface{}, 1)
+	ws.OnConnection(func(c Connection) {
+		c.OnAsk("double", func(message interface{}) (interface{}, error) {
+			n, ok := message.(int)
+			if !ok {
+				return nil, errors.New("not a number")
+			}
+			return n * 2, nil
+		})
+		c.OnAsk("whoami", func(message interface{}) (interface{}, error) {
+			// ask the client back from the listener.
+			reply, err := c.Ask("name", nil, 2*time.Second)
+			if err != nil {
+				return nil, err
+			}
+			serverReplies <- reply
+			return map[string]interface{}{"name": reply}, nil
+		})
+	})
+
+	app := siris.New()
+	ws.Attach(app)
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	client, err := Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", Config{ReconnectDelay: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.OnAsk("name", func(interface{}) (interface{}, error) {
+		return "gopher", nil
+	})
+
+	reply, err := client.Ask("double", 21, 2*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply != 42 {
+		t.Fatalf("expected 42 but got %#v", reply)
+	}
+
+	if _, err = client.Ask("double", "twenty", 2*time.Second); err == nil || err.Error() != "not a number" {
+		t.Fatalf("expected the listener's error but got %v", err)
+	}
+
+	if _, err = client.Ask("unknown", 1, 2*time.Second); err == nil || !strings.Contains(err.Error(), "no ask listener") {
+		t.Fatalf("expected the missing listener error but got %v", err)
+	}
+
+	reply, err = client.Ask("whoami", nil, 2*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := <-serverReplies; got != "gopher" {
+		t.Fatalf("expected the client's reply to the server but got %#v", got)
+	}
+	if m, ok := reply.(map[string]interface{}); !ok || m["name"] != "gopher" {
+		t.Fatalf("unexpected reply %#v", reply)
+	}
+
+	client.Disconnect()
+	if _, err = client.Ask("double", 1, time.Second); err != ErrDisconnected {
+		t.Fatalf("expected the disconnected error but got %v", err)
+	}
+}
+
+func TestAskTimeout(t *testing.T) {
+	var a asks
+	sent := make(chan []byte, 1)
+	send := func(data []byte) error {
+		sent <- data
+		return nil
+	}
+
+	if _, err := a.ask(send, "event", "message", 10*time.Millisecond); err != ErrAskTimeout {
+		t.Fatalf("expected the timeout error but got %v", err)
+	}
+	if expected := websocketMessagePrefix + "event#ask:1;0;message"; string(<-sent) != expected {
+		t.Fatalf("unexpected ask message")
+	}
+
+	// a late reply is ignored.
+	reply, _ := websocketMessageSerialize(websocketReplyPrefix+"1", "late")
+	if !a.handle(send, websocketReplyPrefix+"1", reply) {
+		t.Fatalf("expected the reply to be handled")
+	}
+
+	// a plain event is not an ask.
+	if a.handle(send, "event", websocketMessagePrefix+"event;0;message") {
+		t.Fatalf("expected a plain message to be not handled")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := a.ask(send, "event", "message", 0)
+		done <- err
+	}()
+	<-sent
+	a.cancel(true)
+	if err := <-done; err != ErrDisconnected {
+		t.Fatalf("expected the disconnected error but got %v", err)
+	}
+}
@@ -0,0 +1,378 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-siris/siris/context"
+)
+
+// -------------------------------------------------------------------------------------
+// -------------------------------------------------------------------------------------
+// --------------------------------Broker, the servers' message bus---------------------
+// -------------------------------------------------------------------------------------
+// -------------------------------------------------------------------------------------
+
+// BrokerMessageType is the type of a BrokerMessage.
+type BrokerMessageType uint8
+
+const (
+	// BrokerEmit is a websocket message, the Data, from a connection, the From,
+	// to a room, a connection or All or Broadcast, the To.
+	BrokerEmit BrokerMessageType = iota
+	// BrokerConnect is published when the connection, the From, is connected to the Node.
+	BrokerConnect
+	// BrokerDisconnect is published when the connection, the From, is disconnected from the Node.
+	BrokerDisconnect
+	// BrokerJoin is published when the connection, the From, joins to a Room.
+	BrokerJoin
+	// BrokerLeave is published when the connection, the From, leaves from a Room.
+	BrokerLeave
+	// BrokerDisconnectRequest asks the node of the connection, the To, to disconnect it.
+	BrokerDisconnectRequest
+	// BrokerSync asks the other nodes to publish their connections and rooms,
+	// it's published by a server when its subscription is established.
+	BrokerSync
+	// BrokerHeartbeat is published by each node periodically, see `Config#BrokerHeartbeat`.
+	BrokerHeartbeat
+	// BrokerSubscribed is not published, the Broker sends it to a handler when its subscription is established,
+	// on the `Subscribe` and after each reconnection, the messages of the other nodes may be lost meanwhile.
+	BrokerSubscribed
+)
+
+type (
+	// BrokerMessage is the message which the servers publish to and receive from the Broker.
+	BrokerMessage struct {
+		Type BrokerMessageType `json:"type"`
+		// Node is the id of the publisher server.
+		Node string `json:"node"`
+		From string `json:"from,omitempty"`
+		To   string `json:"to,omitempty"`
+		Room string `json:"room,omitempty"`
+		Data []byte `json:"data,omitempty"`
+	}
+
+	// Broker is the message bus of the websocket servers of the instances behind a load balancer,
+	// set it to the Config's Broker field.
+	//
+	// The servers publish the room, broadcast and direct messages to the Broker
+	// and deliver the ones they receive from it to their connections,
+	// so a `To(room).Emit` reaches the clients which are connected to the other instances.
+	// The servers publish their connections and rooms too,
+	// so the `IsConnected`, `GetConnectionsByRoom` and `Disconnect` work across the instances.
+	//
+	// The in-process `NewMemoryBroker` and the redis pub/sub websocket/redis#New are the built'n brokers.
+	Broker interface {
+		// Publish sends the message to all the subscribers, including the publisher.
+		Publish(msg BrokerMessage) error
+		// Subscribe registers a handler of the published messages,
+		// the messages should be handled in the order that they were published.
+		//
+		// The handler should receive a BrokerSubscribed message
+		// when its subscription is established, and again after each reconnection,
+		// the server asks the other nodes for their connections and rooms then.
+		Subscribe(handler func(msg BrokerMessage))
+	}
+)
+
+// MemoryBroker is the in-process Broker,
+// it's useful when more than one websocket servers are running on the same process, i.e on tests.
+type MemoryBroker struct {
+	mu       sync.Mutex
+	handlers []func(BrokerMessage)
+}
+
+var _ Broker = (*MemoryBroker)(nil)
+
+// NewMemoryBroker returns a new in-process Broker.
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+// Publish calls the handlers with the message, it never fails.
+func (b *MemoryBroker) Publish(msg BrokerMessage) error {
+	b.mu.Lock()
+	handlers := b.handlers
+	b.mu.Unlock()
+
+	for _, h := range handlers {
+		h(msg)
+	}
+	return nil
+}
+
+// Subscribe registers a handler of the published messages,
+// it's subscribed immediately.
+func (b *MemoryBroker) Subscribe(handler func(BrokerMessage)) {
+	b.mu.Lock()
+	b.handlers = append(b.handlers[:len(b.handlers):len(b.handlers)], handler)
+	b.mu.Unlock()
+
+	handler(BrokerMessage{Type: BrokerSubscribed})
+}
+
+// remoteNodes keeps the connections and the rooms of the other nodes, as published to the Broker.
+type remoteNodes struct {
+	mu          sync.RWMutex
+	connections map[string]string    // connection id to its node
+	rooms       map[string][]string  // room name to the connection ids
+	lastSeen    map[string]time.Time // node to the time of its last message
+}
+
+func newRemoteNodes() *remoteNodes {
+	return &remoteNodes{
+		connections: make(map[string]string),
+		rooms:       make(map[string][]string),
+		lastSeen:    make(map[string]time.Time),
+	}
+}
+
+// reset removes everything, the state of the other nodes is published again after a BrokerSync.
+func (r *remoteNodes) reset() {
+	r.mu.Lock()
+	r.connections = make(map[string]string)
+	r.rooms = make(map[string][]string)
+	r.lastSeen = make(map[string]time.Time)
+	r.mu.Unlock()
+}
+
+// seen marks the node as alive.
+func (r *remoteNodes) seen(node string) {
+	r.mu.Lock()
+	r.lastSeen[node] = time.Now()
+	r.mu.Unlock()
+}
+
+// expire removes the connections of the nodes which are not seen after the "deadline".
+func (r *remoteNodes) expire(deadline time.Time) {
+	r.mu.Lock()
+	var connIDs []string
+	for node, t := range r.lastSeen {
+		if !t.Before(deadline) {
+			continue
+		}
+		delete(r.lastSeen, node)
+		for connID, connNode := range r.connections {
+			if connNode == node {
+				connIDs = append(connIDs, connID)
+			}
+		}
+	}
+	r.mu.Unlock()
+
+	for _, connID := range connIDs {
+		r.disconnect(connID)
+	}
+}
+
+func (r *remoteNodes) isConnected(connID string) bool {
+	r.mu.RLock()
+	_, ok := r.connections[connID]
+	r.mu.RUnlock()
+	return ok
+}
+
+func (r *remoteNodes) hasRoom(roomName string) bool {
+	r.mu.RLock()
+	ok := len(r.rooms[roomName]) > 0
+	r.mu.RUnlock()
+	return ok
+}
+
+func (r *remoteNodes) roomConnections(roomName string) []string {
+	r.mu.RLock()
+	connIDs := append([]string(nil), r.rooms[roomName]...)
+	r.mu.RUnlock()
+	return connIDs
+}
+
+func (r *remoteNodes) join(roomName, connID string) {
+	r.mu.Lock()
+	for _, id := range r.rooms[roomName] {
+		if id == connID {
+			r.mu.Unlock()
+			return
+		}
+	}
+	r.rooms[roomName] = append(r.rooms[roomName], connID)
+	r.mu.Unlock()
+}
+
+// leave removes the connection from the room, or from all the rooms if the roomName is empty.
+func (r *remoteNodes) leave(roomName, connID string) {
+	r.mu.Lock()
+	for name, connIDs := range r.rooms {
+		if roomName != "" && name != roomName {
+			continue
+		}
+		for i := range connIDs {
+			if connIDs[i] == connID {
+				connIDs = append(connIDs[:i], connIDs[i+1:]...)
+				break
+			}
+		}
+		if len(connIDs) == 0 {
+			delete(r.rooms, name)
+		} else {
+			r.rooms[name] = connIDs
+		}
+	}
+	r.mu.Unlock()
+}
+
+func (r *remoteNodes) connect(connID, node string) {
+	r.mu.Lock()
+	r.connections[connID] = node
+	r.mu.Unlock()
+}
+
+func (r *remoteNodes) disconnect(connID string) {
+	r.leave("", connID)
+	r.mu.Lock()
+	delete(r.connections, connID)
+	r.mu.Unlock()
+}
+
+// publish sends a message to the broker, if any, the server's node is set.
+func (s *server) publish(msg BrokerMessage) error {
+	if s.config.Broker == nil {
+		return nil
+	}
+	msg.Node = s.node
+	return s.config.Broker.Publish(msg)
+}
+
+// handleBrokerMessage handles the messages of the broker, including the ones which this server published.
+func (s *server) handleBrokerMessage(msg BrokerMessage) {
+	if msg.Type == BrokerSubscribed {
+		// the messages of the other nodes may be lost, ask for their state again.
+		s.remote.reset()
+		s.publish(BrokerMessage{Type: BrokerSync})
+		return
+	}
+
+	if msg.Node != s.node {
+		s.remote.seen(msg.Node)
+	}
+
+	if msg.Type == BrokerEmit {
+		s.emitLocal(msg.From, msg.To, msg.Data)
+		return
+	}
+
+	if msg.Node == s.node {
+		return
+	}
+
+	switch msg.Type {
+	case BrokerConnect:
+		s.remote.connect(msg.From, msg.Node)
+	case BrokerDisconnect:
+		s.remote.disconnect(msg.From)
+	case BrokerJoin:
+		s.remote.join(msg.Room, msg.From)
+	case BrokerLeave:
+		s.remote.leave(msg.Room, msg.From)
+	case BrokerDisconnectRequest:
+		if s.connections.get(msg.To) != nil {
+			s.Disconnect(msg.To)
+		}
+	case BrokerSync:
+		s.publishState()
+	}
+}
+
+// heartbeat publishes the server's heartbeat every "interval"
+// and removes the connections of the nodes which are not heard of for three intervals.
+func (s *server) heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.publish(BrokerMessage{Type: BrokerHeartbeat})
+		s.remote.expire(time.Now().Add(-3 * interval))
+	}
+}
+
+// publishState publishes the local connections and their rooms, as a reply to a BrokerSync.
+func (s *server) publishState() {
+	s.mu.Lock()
+	var messages []BrokerMessage
+	for _, cKV := range s.connections {
+		messages = append(messages, BrokerMessage{Type: BrokerConnect, From: cKV.key})
+	}
+	for name, connIDs := range s.rooms {
+		for _, connID := range connIDs {
+			messages = append(messages, BrokerMessage{Type: BrokerJoin, From: connID, Room: name})
+		}
+	}
+	s.mu.Unlock()
+
+	for _, msg := range messages {
+		s.publish(msg)
+	}
+}
+
+// remoteConnection is a connection of another node, it's returned by the `GetConnectionsByRoom`.
+//
+// Its messages and its disconnection are published to the broker,
+// the event listeners, the rooms and the values are not available.
+type remoteConnection struct {
+	id     string
+	server *server
+}
+
+var _ Connection = &remoteConnection{}
+
+func (c *remoteConnection) EmitMessage(nativeMessage []byte) error {
+	return c.server.emitMessage("", c.id, nativeMessage)
+}
+
+func (c *remoteConnection) Emit(event string, message interface{}) error {
+	data, err := websocketMessageSerialize(event, message)
+	if err != nil {
+		return err
+	}
+	return c.EmitMessage([]byte(data))
+}
+
+func (c *remoteConnection) ID() string { return c.id }
+
+// Context returns nil, the request of the connection was served by another node.
+func (c *remoteConnection) Context() context.Context { return nil }
+
+func (c *remoteConnection) OnDisconnect(DisconnectFunc)  {}
+func (c *remoteConnection) OnStatusCode(ErrorFunc)       {}
+func (c *remoteConnection) FireStatusCode(string)        {}
+func (c *remoteConnection) OnMessage(NativeMessageFunc)  {}
+func (c *remoteConnection) On(string, MessageFunc)       {}
+func (c *remoteConnection) Join(string)                  {}
+func (c *remoteConnection) Leave(string) bool            { return false }
+func (c *remoteConnection) OnLeave(LeaveRoomFunc)        {}
+func (c *remoteConnection) SetValue(string, interface{}) {}
+func (c *remoteConnection) GetValue(string) interface{}  { return nil }
+func (c *remoteConnection) GetValueArrString(string) []string {
+	return nil
+}
+func (c *remoteConnection) GetValueString(string) string { return "" }
+func (c *remoteConnection) GetValueInt(string) int       { return 0 }
+
+// Ask returns an error, the replies are not published to the broker.
+func (c *remoteConnection) Ask(string, interface{}, time.Duration) (interface{}, error) {
+	return nil, errRemoteAsk
+}
+
+func (c *remoteConnection) OnAsk(string, AskFunc) {}
+
+func (c *remoteConnection) To(to string) Emitter {
+	return &emitter{server: c.server, from: c.id, to: to}
+}
+
+// Disconnect asks the node of the connection to disconnect it.
+func (c *remoteConnection) Disconnect() error {
+	return c.server.Disconnect(c.id)
+}
@@ -0,0 +1,153 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-siris/siris"
+	"github.com/go-siris/siris/context"
+	"github.com/gorilla/websocket"
+)
+
+func newBrokerTestNode(t *testing.T, broker Broker) (Server, *httptest.Server) {
+	ws := New(Config{
+		Endpoint:    "/ws",
+		Broker:      broker,
+		IDGenerator: func(ctx context.Context) string { return ctx.URLParam("id") },
+	})
+	ws.OnConnection(func(c Connection) {
+		c.Join("chat")
+		c.On("say", func(msg string) {
+			c.To("chat").Emit("said", msg)
+		})
+	})
+
+	app := siris.New()
+	ws.Attach(app)
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+	return ws, httptest.NewServer(app)
+}
+
+func dialBrokerTestNode(t *testing.T, srv *httptest.Server, id string) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws?id="+id, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMemoryBroker(t *testing.T) {
+	broker := NewMemoryBroker()
+	a, srvA := newBrokerTestNode(t, broker)
+	defer srvA.Close()
+	b, srvB := newBrokerTestNode(t, broker)
+	defer srvB.Close()
+
+	alice := dialBrokerTestNode(t, srvA, "alice")
+	defer alice.Close()
+	bob := dialBrokerTestNode(t, srvB, "bob")
+	defer bob.Close()
+
+	waitFor(t, "the rooms of the other nodes", func() bool {
+		return len(a.GetConnectionsByRoom("chat")) == 2 && len(b.GetConnectionsByRoom("chat")) == 2
+	})
+	if !a.IsConnected("bob") || !b.IsConnected("alice") {
+		t.Fatalf("expected the connections of the other node to be connected")
+	}
+
+	msg, err := websocketMessageSerialize("say", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = alice.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected, _ := websocketMessageSerialize("said", "hello")
+	for _, client := range []*websocket.Conn{alice, bob} {
+		client.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, got, err := client.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != expected {
+			t.Fatalf("expected %q but got %q", expected, got)
+		}
+	}
+
+	// a node of another broker shares nothing.
+	c, srvC := newBrokerTestNode(t, NewMemoryBroker())
+	defer srvC.Close()
+	if c.IsConnected("alice") {
+		t.Fatalf("expected the connection of another broker to be unknown")
+	}
+
+	if err = b.Disconnect("alice"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the remote disconnection", func() bool {
+		return !a.IsConnected("alice") && !b.IsConnected("alice")
+	})
+	if got := b.GetConnectionsByRoom("chat"); len(got) != 1 || got[0].ID() != "bob" {
+		t.Fatalf("expected only the bob's connection to be in the room but got %d", len(got))
+	}
+}
+
+func TestBrokerResubscribe(t *testing.T) {
+	broker := NewMemoryBroker()
+	a, srvA := newBrokerTestNode(t, broker)
+	defer srvA.Close()
+	_, srvB := newBrokerTestNode(t, broker)
+	defer srvB.Close()
+
+	alice := dialBrokerTestNode(t, srvB, "alice")
+	defer alice.Close()
+	waitFor(t, "the connection of the other node", func() bool { return a.IsConnected("alice") })
+
+	// the disconnection of a node which is gone while the subscription was lost.
+	broker.Publish(BrokerMessage{Type: BrokerConnect, Node: "gone", From: "bob"})
+	if !a.IsConnected("bob") {
+		t.Fatalf("expected the connection of the gone node to be connected before the resubscription")
+	}
+
+	a.(*server).handleBrokerMessage(BrokerMessage{Type: BrokerSubscribed})
+	if a.IsConnected("bob") {
+		t.Fatalf("expected the connection of the gone node to be removed on the resubscription")
+	}
+	if !a.IsConnected("alice") {
+		t.Fatalf("expected the connections of the other nodes to be synced on the resubscription")
+	}
+}
+
+func TestBrokerHeartbeat(t *testing.T) {
+	broker := NewMemoryBroker()
+	s := New(Config{Broker: broker, BrokerHeartbeat: 20 * time.Millisecond})
+
+	broker.Publish(BrokerMessage{Type: BrokerConnect, Node: "crashed", From: "bob"})
+	broker.Publish(BrokerMessage{Type: BrokerJoin, Node: "crashed", From: "bob", Room: "chat"})
+	if !s.IsConnected("bob") || len(s.GetConnectionsByRoom("chat")) != 1 {
+		t.Fatalf("expected the connection of the other node to be connected")
+	}
+
+	waitFor(t, "the expiration of the crashed node", func() bool {
+		return !s.IsConnected("bob") && len(s.GetConnectionsByRoom("chat")) == 0
+	})
+}
@@ -23,6 +23,11 @@ var websocketMessageSeparatorLen = websocketMessageSeparator.length;
 var websocketMessagePrefixAndSepIdx = websocketMessagePrefixLen + websocketMessageSeparatorLen - 1;
 var websocketMessagePrefixIdx = websocketMessagePrefixLen - 1;
 var websocketMessageSeparatorIdx = websocketMessageSeparatorLen - 1;
+// an ask is a custom message whose event is suffixed by the ask separator and the ask's id,
+// its reply is a custom message of the reply, or the error reply, event of the same id.
+var websocketAskSeparator = "#ask:";
+var websocketReplyPrefix = "#reply:";
+var websocketErrorReplyPrefix = "#error:";
 var Ws = (function () {
     //
     function Ws(endpoint, protocols) {
@@ -32,6 +37,9 @@ var Ws = (function () {
         this.disconnectListeners = [];
         this.nativeMessageListeners = [];
         this.messageListeners = {};
+        this.askListeners = {};
+        this.pendingAsks = {};
+        this.askID = 0;
         if (!window["WebSocket"]) {
             return;
         }
@@ -50,6 +58,7 @@ var Ws = (function () {
             return null;
         });
         this.conn.onclose = (function (evt) {
+            _this.cancelAsks();
             _this.fireDisconnect();
             return null;
         });
@@ -152,6 +161,9 @@ var Ws = (function () {
         if (message.indexOf(websocketMessagePrefix) != -1) {
             var event_1 = this.getWebsocketCustomEvent(message);
             if (event_1 != "") {
+                if (this.handleAsk(event_1, message)) {
+                    return;
+                }
                 // it's a custom message
                 this.fireMessage(event_1, this.getCustomMessage(event_1, message));
                 return;
@@ -204,6 +216,63 @@ var Ws = (function () {
             }
         }
     };
+    Ws.prototype.OnAsk = function (event, cb) {
+        this.askListeners[event] = cb;
+    };
+    // handleAsk replies to the asks and resolves the pending asks of the replies,
+    // returns false if the message is not an ask or a reply.
+    Ws.prototype.handleAsk = function (event, websocketMessage) {
+        var _this = this;
+        var isError = event.indexOf(websocketErrorReplyPrefix) == 0;
+        if (isError || event.indexOf(websocketReplyPrefix) == 0) {
+            var id = event.substring(event.indexOf(":") + 1, event.length);
+            var pending = this.pendingAsks[id];
+            if (pending !== undefined) {
+                delete this.pendingAsks[id];
+                clearTimeout(pending.timer);
+                var reply = this.decodeMessage(event, websocketMessage);
+                if (isError) {
+                    pending.reject(new Error(reply));
+                }
+                else {
+                    pending.resolve(reply);
+                }
+            }
+            return true;
+        }
+        var idx = event.lastIndexOf(websocketAskSeparator);
+        if (idx == -1) {
+            return false;
+        }
+        var replyID = event.substring(idx + websocketAskSeparator.length, event.length);
+        var askEvent = event.substring(0, idx);
+        var cb = this.askListeners[askEvent];
+        var replyError = function (err) {
+            _this.EmitMessage(_this._msg(websocketErrorReplyPrefix + replyID, websocketStringMessageType, String(err && err.message || err)));
+        };
+        if (cb === undefined) {
+            replyError("websocket: no ask listener for the event " + askEvent);
+            return true;
+        }
+        try {
+            Promise.resolve(cb(this.decodeMessage(event, websocketMessage))).then(function (reply) {
+                _this.Emit(websocketReplyPrefix + replyID, reply);
+            }, replyError);
+        }
+        catch (err) {
+            replyError(err);
+        }
+        return true;
+    };
+    Ws.prototype.cancelAsks = function () {
+        for (var id in this.pendingAsks) {
+            if (this.pendingAsks.hasOwnProperty(id)) {
+                clearTimeout(this.pendingAsks[id].timer);
+                this.pendingAsks[id].reject(new Error("websocket: client is disconnected"));
+            }
+        }
+        this.pendingAsks = {};
+    };
     //
     // Ws Actions
     Ws.prototype.Disconnect = function () {
@@ -218,6 +287,23 @@ var Ws = (function () {
         var messageStr = this.encodeMessage(event, data);
         this.EmitMessage(messageStr);
     };
+    // Ask sends an ask to the server's OnAsk listener of the event
+    // and returns a Promise of its reply, a non-positive timeout, in milliseconds, waits until the reply or the disconnection.
+    Ws.prototype.Ask = function (event, data, timeout) {
+        var _this = this;
+        var id = String(++this.askID);
+        return new Promise(function (resolve, reject) {
+            var timer = null;
+            if (timeout > 0) {
+                timer = setTimeout(function () {
+                    delete _this.pendingAsks[id];
+                    reject(new Error("websocket: ask timeout"));
+                }, timeout);
+            }
+            _this.pendingAsks[id] = { resolve: resolve, reject: reject, timer: timer };
+            _this.Emit(event + websocketAskSeparator + id, data);
+        });
+    };
     return Ws;
 }());
 `)
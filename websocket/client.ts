@@ -25,10 +25,24 @@ var websocketMessagePrefixAndSepIdx = websocketMessagePrefixLen + websocketMessa
 var websocketMessagePrefixIdx = websocketMessagePrefixLen - 1;
 var websocketMessageSeparatorIdx = websocketMessageSeparatorLen - 1;
 
+// an ask is a custom message whose event is suffixed by the ask separator and the ask's id,
+// its reply is a custom message of the reply, or the error reply, event of the same id.
+const websocketAskSeparator = "#ask:";
+const websocketReplyPrefix = "#reply:";
+const websocketErrorReplyPrefix = "#error:";
+
 type onConnectFunc = () => void;
 type onWebsocketDisconnectFunc = () => void;
 type onWebsocketNativeMessageFunc = (websocketMessage: string) => void;
 type onMessageFunc = (message: any) => void;
+// onAskFunc returns the reply, or a Promise of the reply, a thrown error or a rejection is sent as an error reply.
+type onAskFunc = (message: any) => any;
+
+interface pendingAsk {
+    resolve: (reply: any) => void;
+    reject: (err: Error) => void;
+    timer: any;
+}
 
 class Ws {
     private conn: WebSocket;
@@ -40,6 +54,9 @@ class Ws {
     private disconnectListeners: onWebsocketDisconnectFunc[] = [];
     private nativeMessageListeners: onWebsocketNativeMessageFunc[] = [];
     private messageListeners: { [event: string]: onMessageFunc[] } = {};
+    private askListeners: { [event: string]: onAskFunc } = {};
+    private pendingAsks: { [id: string]: pendingAsk } = {};
+    private askID: number = 0;
 
     //
 
@@ -64,6 +81,7 @@ class Ws {
         });
 
         this.conn.onclose = ((evt: Event): any => {
+            this.cancelAsks();
             this.fireDisconnect();
             return null;
         });
@@ -176,6 +194,9 @@ class Ws {
         if (message.indexOf(websocketMessagePrefix) != -1) {
             let event = this.getWebsocketCustomEvent(message);
             if (event != "") {
+                if (this.handleAsk(event, message)) {
+                    return;
+                }
                 // it's a custom message
                 this.fireMessage(event, this.getCustomMessage(event, message));
                 return;
@@ -239,6 +260,65 @@ class Ws {
     }
 
 
+    OnAsk(event: string, cb: onAskFunc): void {
+        this.askListeners[event] = cb;
+    }
+
+    // handleAsk replies to the asks and resolves the pending asks of the replies,
+    // returns false if the message is not an ask or a reply.
+    private handleAsk(event: string, websocketMessage: string): boolean {
+        let isError = event.indexOf(websocketErrorReplyPrefix) == 0;
+        if (isError || event.indexOf(websocketReplyPrefix) == 0) {
+            let id = event.substring(event.indexOf(":") + 1, event.length);
+            let pending = this.pendingAsks[id];
+            if (pending !== undefined) {
+                delete this.pendingAsks[id];
+                clearTimeout(pending.timer);
+                let reply = this.decodeMessage(event, websocketMessage);
+                if (isError) {
+                    pending.reject(new Error(reply));
+                } else {
+                    pending.resolve(reply);
+                }
+            }
+            return true;
+        }
+
+        let idx = event.lastIndexOf(websocketAskSeparator);
+        if (idx == -1) {
+            return false;
+        }
+        let replyID = event.substring(idx + websocketAskSeparator.length, event.length);
+        let askEvent = event.substring(0, idx);
+        let cb = this.askListeners[askEvent];
+        let replyError = (err: any) => {
+            this.EmitMessage(this._msg(websocketErrorReplyPrefix + replyID, websocketStringMessageType, String(err && err.message || err)));
+        };
+        if (cb === undefined) {
+            replyError("websocket: no ask listener for the event " + askEvent);
+            return true;
+        }
+
+        try {
+            Promise.resolve(cb(this.decodeMessage(event, websocketMessage))).then((reply: any) => {
+                this.Emit(websocketReplyPrefix + replyID, reply);
+            }, replyError);
+        } catch (err) {
+            replyError(err);
+        }
+        return true;
+    }
+
+    private cancelAsks(): void {
+        for (let id in this.pendingAsks) {
+            if (this.pendingAsks.hasOwnProperty(id)) {
+                clearTimeout(this.pendingAsks[id].timer);
+                this.pendingAsks[id].reject(new Error("websocket: client is disconnected"));
+            }
+        }
+        this.pendingAsks = {};
+    }
+
     //
 
     // Ws Actions
@@ -258,6 +338,23 @@ class Ws {
         this.EmitMessage(messageStr);
     }
 
+    // Ask sends an ask to the server's OnAsk listener of the event
+    // and returns a Promise of its reply, a non-positive timeout, in milliseconds, waits until the reply or the disconnection.
+    Ask(event: string, data: any, timeout?: number): Promise<any> {
+        let id = String(++this.askID);
+        return new Promise((resolve, reject) => {
+            let timer: any = null;
+            if (timeout > 0) {
+                timer = setTimeout(() => {
+                    delete this.pendingAsks[id];
+                    reject(new Error("websocket: ask timeout"));
+                }, timeout);
+            }
+            this.pendingAsks[id] = { resolve: resolve, reject: reject, timer: timer };
+            this.Emit(event + websocketAskSeparator + id, data);
+        });
+    }
+
     //
 
 }
@@ -28,6 +28,12 @@ const (
 	DefaultWebsocketWriterBufferSize = 4096
 	// DefaultClientSourcePath "/siris-ws.js"
 	DefaultClientSourcePath = "/siris-ws.js"
+	// DefaultReconnectDelay 1 * time.Second
+	DefaultReconnectDelay = 1 * time.Second
+	// DefaultMaxReconnectDelay 30 * time.Second
+	DefaultMaxReconnectDelay = 30 * time.Second
+	// DefaultBrokerHeartbeat 10 * time.Second
+	DefaultBrokerHeartbeat = 10 * time.Second
 )
 
 var (
@@ -87,6 +93,30 @@ type Config struct {
 	// subprotocol by selecting the first match in this list with a protocol
 	// requested by the client.
 	Subprotocols []string
+
+	// Broker is the message bus of the servers of the instances behind a load balancer,
+	// the room, broadcast and direct messages reach the connections of all the instances through it.
+	// See `NewMemoryBroker` and the websocket/redis package.
+	// Defaults to nil, the messages are delivered to the connections of this server only.
+	Broker Broker
+	// BrokerHeartbeat is the interval of the server's heartbeats on the Broker,
+	// the connections of a node which is not heard of for three intervals, i.e a crashed one, are removed.
+	// Default value is 10 * time.Second
+	BrokerHeartbeat time.Duration
+
+	// The fields below are used by the Go client only, see `Dial`.
+
+	// Header is the header of the client's handshake request, i.e the cookies of the session.
+	// Defaults to nil.
+	Header http.Header
+	// ReconnectDelay is the delay before the client's first reconnection attempt,
+	// it's doubled on each failed attempt, up to the MaxReconnectDelay.
+	// Negative value disables the reconnection.
+	// Default value is 1 * time.Second
+	ReconnectDelay time.Duration
+	// MaxReconnectDelay is the max delay between the client's reconnection attempts.
+	// Default value is 30 * time.Second
+	MaxReconnectDelay time.Duration
 }
 
 // Validate validates the configuration
@@ -142,5 +172,17 @@ func (c Config) Validate() Config {
 		c.IDGenerator = DefaultIDGenerator
 	}
 
+	if c.BrokerHeartbeat <= 0 {
+		c.BrokerHeartbeat = DefaultBrokerHeartbeat
+	}
+
+	if c.ReconnectDelay == 0 {
+		c.ReconnectDelay = DefaultReconnectDelay
+	}
+
+	if c.MaxReconnectDelay <= 0 {
+		c.MaxReconnectDelay = DefaultMaxReconnectDelay
+	}
+
 	return c
 }
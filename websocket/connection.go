@@ -175,6 +175,14 @@ type (
 		// Note: the callback(s) called right before the server deletes the connection from the room
 		// so the connection theoretical can still send messages to its room right before it is being disconnected.
 		OnLeave(roomLeaveCb LeaveRoomFunc)
+		// Ask sends a message to an event of the client and waits for the reply of its `OnAsk` listener,
+		// a non-positive timeout waits until the reply or the disconnection.
+		//
+		// The javascript client's Ask returns a Promise of the server's reply.
+		Ask(event string, message interface{}, timeout time.Duration) (interface{}, error)
+		// OnAsk registers the callback which replies to the client's asks of the event,
+		// it replaces the previous one. The asks are replied on their own goroutines.
+		OnAsk(event string, cb AskFunc)
 		// Disconnect disconnects the client, close the underline websocket conn and removes it from the conn list
 		// returns the error, if any, from the underline connection
 		Disconnect() error
@@ -201,6 +209,7 @@ type (
 		onErrorListeners         []ErrorFunc
 		onNativeMessageListeners []NativeMessageFunc
 		onEventListeners         map[string][]MessageFunc
+		asks                     asks
 		// these were  maden for performance only
 		self      Emitter // pre-defined emitter than sends message to its self client
 		broadcast Emitter // pre-defined emitter that sends message to all except this
@@ -352,38 +361,10 @@ func (c *connection) messageReceived(data []byte) {
 		customData := string(data)
 		//it's a custom ws message
 		receivedEvt := getWebsocketCustomEvent(customData)
-		listeners := c.onEventListeners[receivedEvt]
-		if listeners == nil { // if not listeners for this event exit from here
-			return
-		}
-		customMessage, err := websocketMessageDeserialize(receivedEvt, customData)
-		if customMessage == nil || err != nil {
+		if c.asks.handle(c.send, receivedEvt, customData) {
 			return
 		}
-
-		for i := range listeners {
-			if fn, ok := listeners[i].(func()); ok { // its a simple func(){} callback
-				fn()
-			} else if fnString, ok := listeners[i].(func(string)); ok {
-
-				if msgString, is := customMessage.(string); is {
-					fnString(msgString)
-				} else if msgInt, is := customMessage.(int); is {
-					// here if server side waiting for string but client side sent an int, just convert this int to a string
-					fnString(strconv.Itoa(msgInt))
-				}
-
-			} else if fnInt, ok := listeners[i].(func(int)); ok {
-				fnInt(customMessage.(int))
-			} else if fnBool, ok := listeners[i].(func(bool)); ok {
-				fnBool(customMessage.(bool))
-			} else if fnBytes, ok := listeners[i].(func([]byte)); ok {
-				fnBytes(customMessage.([]byte))
-			} else {
-				listeners[i].(func(interface{}))(customMessage)
-			}
-
-		}
+		fireMessageListeners(c.onEventListeners[receivedEvt], receivedEvt, customData)
 	} else {
 		// it's native websocket message
 		for i := range c.onNativeMessageListeners {
@@ -393,6 +374,42 @@ func (c *connection) messageReceived(data []byte) {
 
 }
 
+// fireMessageListeners deserializes the custom message of the event and calls the event's listeners,
+// it's used by the server's connections and by the Go client, see `Dial`.
+func fireMessageListeners(listeners []MessageFunc, receivedEvt string, customData string) {
+	if listeners == nil { // if not listeners for this event exit from here
+		return
+	}
+	customMessage, err := websocketMessageDeserialize(receivedEvt, customData)
+	if customMessage == nil || err != nil {
+		return
+	}
+
+	for i := range listeners {
+		if fn, ok := listeners[i].(func()); ok { // its a simple func(){} callback
+			fn()
+		} else if fnString, ok := listeners[i].(func(string)); ok {
+
+			if msgString, is := customMessage.(string); is {
+				fnString(msgString)
+			} else if msgInt, is := customMessage.(int); is {
+				// here if server side waiting for string but client side sent an int, just convert this int to a string
+				fnString(strconv.Itoa(msgInt))
+			}
+
+		} else if fnInt, ok := listeners[i].(func(int)); ok {
+			fnInt(customMessage.(int))
+		} else if fnBool, ok := listeners[i].(func(bool)); ok {
+			fnBool(customMessage.(bool))
+		} else if fnBytes, ok := listeners[i].(func([]byte)); ok {
+			fnBytes(customMessage.([]byte))
+		} else {
+			listeners[i].(func(interface{}))(customMessage)
+		}
+
+	}
+}
+
 func (c *connection) ID() string {
 	return c.id
 }
@@ -445,6 +462,20 @@ func (c *connection) Emit(event string, message interface{}) error {
 	return c.self.Emit(event, message)
 }
 
+// send writes the message to the client, it's used by the asks.
+func (c *connection) send(data []byte) error {
+	c.writeDefault(data)
+	return nil
+}
+
+func (c *connection) Ask(event string, message interface{}, timeout time.Duration) (interface{}, error) {
+	return c.asks.ask(c.send, event, message, timeout)
+}
+
+func (c *connection) OnAsk(event string, cb AskFunc) {
+	c.asks.on(event, cb)
+}
+
 func (c *connection) OnMessage(cb NativeMessageFunc) {
 	c.onNativeMessageListeners = append(c.onNativeMessageListeners, cb)
 }
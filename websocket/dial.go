@@ -0,0 +1,372 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// -------------------------------------------------------------------------------------
+// -------------------------------------------------------------------------------------
+// --------------------------------Go client implementation-----------------------------
+// -------------------------------------------------------------------------------------
+// -------------------------------------------------------------------------------------
+
+// ErrDisconnected is returned by the client's Emit and EmitMessage
+// while it's disconnected, i.e while it's reconnecting,
+// and by the `Ask` of a connection which is disconnected before the reply.
+var ErrDisconnected = errors.New("websocket: client is disconnected")
+
+type (
+	// ClientConnection is the Go client of a websocket server, it's returned by the `Dial`.
+	// It speaks the same protocol as the javascript client, the ClientSource,
+	// so its `Emit` reaches the server connection's `On` listeners and vice versa.
+	ClientConnection interface {
+		// Emitter implements EmitMessage & Emit, the messages are sent to the server.
+		Emitter
+		// OnMessage registers a callback which fires when native websocket message received
+		OnMessage(NativeMessageFunc)
+		// On registers a callback to a particular event which fires when a message to this event received
+		On(string, MessageFunc)
+		// OnDisconnect registers a callback which fires when the connection is lost or closed by the `Disconnect`,
+		// the client reconnects to the server after the first, unless the reconnection is disabled.
+		OnDisconnect(DisconnectFunc)
+		// OnReconnect registers a callback which fires when the client is connected to the server again,
+		// i.e to re-join its rooms.
+		OnReconnect(func())
+		// OnStatusCode registers a callback which fires when the connection occurs an error,
+		// including the failed reconnection attempts.
+		OnStatusCode(ErrorFunc)
+		// Ask sends a message to an event of the server and waits for the reply of its `OnAsk` listener,
+		// a non-positive timeout waits until the reply or the disconnection.
+		Ask(event string, message interface{}, timeout time.Duration) (interface{}, error)
+		// OnAsk registers the callback which replies to the server's asks of the event.
+		OnAsk(event string, cb AskFunc)
+		// Disconnect closes the connection, the client doesn't reconnect after that.
+		Disconnect() error
+	}
+
+	clientConnection struct {
+		url         string
+		config      Config
+		dialer      *websocket.Dialer
+		messageType int
+
+		mu                       sync.RWMutex // for the listeners
+		onEventListeners         map[string][]MessageFunc
+		onNativeMessageListeners []NativeMessageFunc
+		onDisconnectListeners    []DisconnectFunc
+		onReconnectListeners     []func()
+		onErrorListeners         []ErrorFunc
+		asks                     asks
+
+		// writerMu protects the writes and the underline, which is nil while reconnecting.
+		writerMu  sync.Mutex
+		underline *websocket.Conn
+		closed    bool
+		done      chan struct{}
+	}
+)
+
+var _ ClientConnection = &clientConnection{}
+
+// Dial connects to the websocket server of the "url", i.e ws://localhost:8080/my_endpoint,
+// the client's `On` listeners can be registered after that, the messages are received on a goroutine.
+//
+// The Config's buffers, timeouts, ping period, max message size, binary messages and subprotocols
+// are used as on the server side, the Header, ReconnectDelay and MaxReconnectDelay are used by the client only.
+//
+// The client pings the server every PingPeriod and
+// reconnects, with an exponential backoff, when the connection is lost.
+func Dial(url string, cfg Config) (ClientConnection, error) {
+	cfg = cfg.Validate()
+	c := &clientConnection{
+		url:    url,
+		config: cfg,
+		dialer: &websocket.Dialer{
+			Proxy:           websocket.DefaultDialer.Proxy,
+			ReadBufferSize:  cfg.ReadBufferSize,
+			WriteBufferSize: cfg.WriteBufferSize,
+			Subprotocols:    cfg.Subprotocols,
+		},
+		messageType:      websocket.TextMessage,
+		onEventListeners: make(map[string][]MessageFunc, 0),
+		done:             make(chan struct{}),
+	}
+
+	if c.config.BinaryMessages {
+		c.messageType = websocket.BinaryMessage
+	}
+
+	conn, _, err := c.dialer.Dial(url, c.config.Header)
+	if err != nil {
+		return nil, err
+	}
+	c.underline = conn
+
+	go c.run(conn)
+	return c, nil
+}
+
+// run reads the messages of the connection and reconnects when it's lost,
+// until the client is disconnected.
+func (c *clientConnection) run(conn *websocket.Conn) {
+	for {
+		stopPinger := c.startPinger(conn)
+		err := c.startReader(conn)
+		close(stopPinger)
+
+		c.writerMu.Lock()
+		closed := c.closed
+		c.underline = nil
+		c.writerMu.Unlock()
+		conn.Close()
+		c.asks.cancel(closed)
+
+		if !closed && websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
+			c.fireStatusCode(err.Error())
+		}
+		c.fireDisconnect()
+
+		if closed || c.config.ReconnectDelay < 0 {
+			return
+		}
+
+		if conn = c.reconnect(); conn == nil {
+			return
+		}
+		c.fireReconnect()
+	}
+}
+
+// reconnect dials the server until it's connected or the client is disconnected, returns nil on the latter.
+func (c *clientConnection) reconnect() *websocket.Conn {
+	delay := c.config.ReconnectDelay
+	for {
+		select {
+		case <-c.done:
+			return nil
+		case <-time.After(delay):
+		}
+
+		conn, _, err := c.dialer.Dial(c.url, c.config.Header)
+		if err == nil {
+			c.writerMu.Lock()
+			if c.closed {
+				c.writerMu.Unlock()
+				conn.Close()
+				return nil
+			}
+			c.underline = conn
+			c.writerMu.Unlock()
+			return conn
+		}
+
+		c.fireStatusCode(err.Error())
+		if delay *= 2; delay > c.config.MaxReconnectDelay {
+			delay = c.config.MaxReconnectDelay
+		}
+	}
+}
+
+// startPinger pings the server every PingPeriod until the returned channel is closed.
+func (c *clientConnection) startPinger(conn *websocket.Conn) chan struct{} {
+	stop := make(chan struct{})
+	pinger := time.NewTicker(c.config.PingPeriod)
+
+	go func() {
+		defer pinger.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-pinger.C:
+				// WriteControl can be called concurrently with the other methods.
+				if err := conn.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(WriteWait)); err != nil {
+					if e, ok := err.(net.Error); ok && e.Temporary() {
+						continue
+					}
+					// the reader fails too, which handles the disconnection.
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	return stop
+}
+
+func (c *clientConnection) startReader(conn *websocket.Conn) error {
+	hasReadTimeout := c.config.ReadTimeout > 0
+
+	conn.SetReadLimit(c.config.MaxMessageSize)
+	conn.SetPongHandler(func(s string) error {
+		if hasReadTimeout {
+			conn.SetReadDeadline(time.Now().Add(c.config.ReadTimeout))
+		}
+
+		return nil
+	})
+
+	for {
+		if hasReadTimeout {
+			// set the read deadline based on the configuration
+			conn.SetReadDeadline(time.Now().Add(c.config.ReadTimeout))
+		}
+
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		c.messageReceived(data)
+	}
+}
+
+func (c *clientConnection) messageReceived(data []byte) {
+	if bytes.HasPrefix(data, websocketMessagePrefixBytes) {
+		customData := string(data)
+		receivedEvt := getWebsocketCustomEvent(customData)
+		if c.asks.handle(c.send, receivedEvt, customData) {
+			return
+		}
+		c.mu.RLock()
+		listeners := c.onEventListeners[receivedEvt]
+		c.mu.RUnlock()
+		fireMessageListeners(listeners, receivedEvt, customData)
+		return
+	}
+
+	c.mu.RLock()
+	listeners := c.onNativeMessageListeners
+	c.mu.RUnlock()
+	for i := range listeners {
+		listeners[i](data)
+	}
+}
+
+func (c *clientConnection) write(websocketMessageType int, data []byte) error {
+	c.writerMu.Lock()
+	defer c.writerMu.Unlock()
+
+	if c.underline == nil {
+		return ErrDisconnected
+	}
+	if writeTimeout := c.config.WriteTimeout; writeTimeout > 0 {
+		// set the write deadline based on the configuration
+		c.underline.SetWriteDeadline(time.Now().Add(writeTimeout))
+	}
+
+	return c.underline.WriteMessage(websocketMessageType, data)
+}
+
+func (c *clientConnection) send(data []byte) error {
+	return c.write(c.messageType, data)
+}
+
+func (c *clientConnection) EmitMessage(nativeMessage []byte) error {
+	return c.write(c.messageType, nativeMessage)
+}
+
+func (c *clientConnection) Emit(event string, data interface{}) error {
+	message, err := websocketMessageSerialize(event, data)
+	if err != nil {
+		return err
+	}
+	return c.EmitMessage([]byte(message))
+}
+
+func (c *clientConnection) Ask(event string, message interface{}, timeout time.Duration) (interface{}, error) {
+	return c.asks.ask(c.send, event, message, timeout)
+}
+
+func (c *clientConnection) OnAsk(event string, cb AskFunc) {
+	c.asks.on(event, cb)
+}
+
+func (c *clientConnection) OnMessage(cb NativeMessageFunc) {
+	c.mu.Lock()
+	c.onNativeMessageListeners = append(c.onNativeMessageListeners, cb)
+	c.mu.Unlock()
+}
+
+func (c *clientConnection) On(event string, cb MessageFunc) {
+	c.mu.Lock()
+	c.onEventListeners[event] = append(c.onEventListeners[event], cb)
+	c.mu.Unlock()
+}
+
+func (c *clientConnection) OnDisconnect(cb DisconnectFunc) {
+	c.mu.Lock()
+	c.onDisconnectListeners = append(c.onDisconnectListeners, cb)
+	c.mu.Unlock()
+}
+
+func (c *clientConnection) fireDisconnect() {
+	c.mu.RLock()
+	listeners := c.onDisconnectListeners
+	c.mu.RUnlock()
+	for i := range listeners {
+		listeners[i]()
+	}
+}
+
+func (c *clientConnection) OnReconnect(cb func()) {
+	c.mu.Lock()
+	c.onReconnectListeners = append(c.onReconnectListeners, cb)
+	c.mu.Unlock()
+}
+
+func (c *clientConnection) fireReconnect() {
+	c.mu.RLock()
+	listeners := c.onReconnectListeners
+	c.mu.RUnlock()
+	for i := range listeners {
+		listeners[i]()
+	}
+}
+
+func (c *clientConnection) OnStatusCode(cb ErrorFunc) {
+	c.mu.Lock()
+	c.onErrorListeners = append(c.onErrorListeners, cb)
+	c.mu.Unlock()
+}
+
+func (c *clientConnection) fireStatusCode(errorMessage string) {
+	c.mu.RLock()
+	listeners := c.onErrorListeners
+	c.mu.RUnlock()
+	for i := range listeners {
+		listeners[i](errorMessage)
+	}
+}
+
+// Disconnect sends a close message to the server and closes the connection,
+// the OnDisconnect listeners are fired by the reader.
+func (c *clientConnection) Disconnect() error {
+	c.writerMu.Lock()
+	if c.closed {
+		c.writerMu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+	conn := c.underline
+	c.writerMu.Unlock()
+
+	if conn == nil { // reconnecting.
+		return nil
+	}
+
+	conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(WriteWait))
+	return conn.Close()
+}
@@ -0,0 +1,90 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-siris/siris"
+)
+
+func TestDial(t *testing.T) {
+	ws := New(Config{Endpoint: "/ws"})
+	ws.OnConnection(func(c Connection) {
+		c.On("echo", func(msg string) {
+			c.Emit("echo", msg)
+		})
+		c.On("kick", func() {
+			c.Disconnect()
+		})
+	})
+
+	app := siris.New()
+	ws.Attach(app)
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	client, err := Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", Config{ReconnectDelay: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	echo := make(chan string, 1)
+	disconnected := make(chan struct{}, 2)
+	reconnected := make(chan struct{}, 1)
+	client.On("echo", func(msg string) { echo <- msg })
+	client.OnDisconnect(func() { disconnected <- struct{}{} })
+	client.OnReconnect(func() { reconnected <- struct{}{} })
+
+	expectEcho := func(msg string) {
+		if err := client.Emit("echo", msg); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case got := <-echo:
+			if got != msg {
+				t.Fatalf("expected %q but got %q", msg, got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting for the echo of %q", msg)
+		}
+	}
+	wait := func(ch chan struct{}, what string) {
+		select {
+		case <-ch:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting for the %s", what)
+		}
+	}
+
+	expectEcho("hello")
+
+	// the server disconnects the client, which reconnects.
+	if err = client.Emit("kick", "now"); err != nil {
+		t.Fatal(err)
+	}
+	wait(disconnected, "disconnection")
+	wait(reconnected, "reconnection")
+	expectEcho("hello again")
+
+	if err = client.Disconnect(); err != nil {
+		t.Fatal(err)
+	}
+	wait(disconnected, "manual disconnection")
+	if err = client.Emit("echo", "bye"); err != ErrDisconnected {
+		t.Fatalf("expected the disconnected error but got %v", err)
+	}
+	select {
+	case <-reconnected:
+		t.Fatalf("expected no reconnection after a manual disconnection")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
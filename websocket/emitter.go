@@ -27,24 +27,25 @@ type (
 	}
 
 	emitter struct {
-		conn *connection
-		to   string
+		server *server
+		from   string
+		to     string
 	}
 )
 
 var _ Emitter = &emitter{}
 
 func newEmitter(c *connection, to string) *emitter {
-	return &emitter{conn: c, to: to}
+	return &emitter{server: c.server, from: c.id, to: to}
 }
 
+// EmitMessage sends the native message, it returns the broker's error, if any.
 func (e *emitter) EmitMessage(nativeMessage []byte) error {
-	e.conn.server.emitMessage(e.conn.id, e.to, nativeMessage)
-	return nil
+	return e.server.emitMessage(e.from, e.to, nativeMessage)
 }
 
 func (e *emitter) Emit(event string, data interface{}) error {
-	if e.conn.server.rooms[e.to] == nil {
+	if e.to != All && e.to != Broadcast && !e.server.hasRoom(e.to) {
 		return nil
 	}
 
@@ -52,6 +53,5 @@ func (e *emitter) Emit(event string, data interface{}) error {
 	if err != nil {
 		return err
 	}
-	e.EmitMessage([]byte(message))
-	return nil
+	return e.EmitMessage([]byte(message))
 }
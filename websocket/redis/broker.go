@@ -0,0 +1,189 @@
+// Copyright 2017 Gerasimos Maropoulos, ΓΜ. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package redis provides a websocket Broker which publishes the messages
+// of the websocket servers to a redis pub/sub channel, so they can reach
+// the connections of all the instances behind a load balancer.
+//
+// depend on github.com/garyburd/redigo/redis
+//
+// Usage:
+//
+//	pool := &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", "127.0.0.1:6379") }}
+//	ws := websocket.New(websocket.Config{Endpoint: "/ws", Broker: wsredis.New(pool, "")})
+package redis
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/go-siris/siris/websocket"
+)
+
+// DefaultChannel is the redis channel
+// which the messages are published to, if not any other passed on `New`.
+var DefaultChannel = "siris.websocket"
+
+// ReconnectDelay is the delay between the subscription's reconnection attempts.
+var ReconnectDelay = time.Second
+
+// Broker is the redis pub/sub websocket Broker.
+type Broker struct {
+	pool    *redis.Pool
+	channel string
+
+	// OnError is called with the errors of the subscription,
+	// i.e a redis outage or a malformed message, defaults to nil.
+	OnError func(error)
+
+	mu         sync.Mutex
+	handlers   []func(websocket.BrokerMessage)
+	started    bool
+	subscribed bool
+	closed     bool
+	conn       *redis.PubSubConn
+}
+
+var _ websocket.Broker = (*Broker)(nil)
+
+// New returns a new redis Broker which uses the "pool"'s connections,
+// the subscription holds one connection of the pool while the Broker is open.
+//
+// The messages are published to the "channel", if empty then the `DefaultChannel` is used.
+func New(pool *redis.Pool, channel string) *Broker {
+	if channel == "" {
+		channel = DefaultChannel
+	}
+	return &Broker{pool: pool, channel: channel}
+}
+
+// Publish publishes the message to the channel.
+func (b *Broker) Publish(msg websocket.BrokerMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	conn := b.pool.Get()
+	defer conn.Close()
+	_, err = conn.Do("PUBLISH", b.channel, data)
+	return err
+}
+
+// Subscribe registers a handler of the published messages,
+// the channel is subscribed on the first call, in the background.
+//
+// The handlers receive a websocket.BrokerSubscribed message
+// when the subscription is confirmed by redis and after each reconnection.
+func (b *Broker) Subscribe(handler func(websocket.BrokerMessage)) {
+	b.mu.Lock()
+	b.handlers = append(b.handlers[:len(b.handlers):len(b.handlers)], handler)
+	start := !b.started
+	b.started = true
+	subscribed := b.subscribed
+	b.mu.Unlock()
+
+	if start {
+		go b.listen()
+	} else if subscribed {
+		handler(websocket.BrokerMessage{Type: websocket.BrokerSubscribed})
+	}
+}
+
+// Close unsubscribes from the channel.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	conn := b.conn
+	b.conn = nil
+	b.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+func (b *Broker) isClosed() bool {
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	return closed
+}
+
+func (b *Broker) fireError(err error) {
+	if b.OnError != nil {
+		b.OnError(err)
+	}
+}
+
+// listen receives the messages of the channel until the Broker is closed,
+// it re-subscribes after the ReconnectDelay if the connection is lost.
+func (b *Broker) listen() {
+	for !b.isClosed() {
+		if err := b.receive(); err != nil && !b.isClosed() {
+			b.fireError(err)
+			time.Sleep(ReconnectDelay)
+		}
+	}
+}
+
+func (b *Broker) receive() error {
+	conn := &redis.PubSubConn{Conn: b.pool.Get()}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return conn.Close()
+	}
+	b.conn = conn
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		b.subscribed = false
+		b.mu.Unlock()
+		conn.Close()
+	}()
+
+	if err := conn.Subscribe(b.channel); err != nil {
+		return err
+	}
+
+	for {
+		switch v := conn.Receive().(type) {
+		case redis.Subscription:
+			if v.Kind != "subscribe" || v.Channel != b.channel {
+				continue
+			}
+
+			b.mu.Lock()
+			b.subscribed = true
+			b.mu.Unlock()
+			// the messages which were published before the confirmation are lost.
+			b.handle(websocket.BrokerMessage{Type: websocket.BrokerSubscribed})
+		case redis.Message:
+			var msg websocket.BrokerMessage
+			if err := json.Unmarshal(v.Data, &msg); err != nil {
+				b.fireError(err)
+				continue
+			}
+			b.handle(msg)
+		case error:
+			return v
+		}
+	}
+}
+
+func (b *Broker) handle(msg websocket.BrokerMessage) {
+	b.mu.Lock()
+	handlers := b.handlers
+	b.mu.Unlock()
+
+	for _, h := range handlers {
+		h(msg)
+	}
+}
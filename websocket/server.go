@@ -67,6 +67,8 @@ type Server interface {
 	// 2. leave from all joined rooms
 	// 3. fire the disconnect callbacks, if any
 	// 4. close the underline connection and return its error, if any.
+	// If the connection belongs to another node of the config's Broker
+	// the disconnection is requested through the Broker.
 	//
 	// You can use the connection.Disconnect() instead.
 	Disconnect(connID string) error
@@ -177,6 +179,10 @@ type (
 		rooms                 map[string][]string // by default a connection is joined to a room which has the connection id as its name
 		mu                    sync.Mutex          // for rooms
 		onConnectionListeners []ConnectionFunc
+		// node is the id of the server on the config's Broker.
+		node string
+		// remote keeps the connections and the rooms of the other nodes of the Broker.
+		remote *remoteNodes
 		//connectionPool        *sync.Pool // sadly I can't make this because the websocket connection is live until is closed.
 	}
 )
@@ -222,7 +228,10 @@ func (s *server) handleConnection(ctx context.Context, websocketConn UnderlineCo
 	// create the new connection
 	c := newConnection(ctx, s, websocketConn, cid)
 	// add the connection to the server's list
+	s.mu.Lock()
 	s.connections.add(cid, c)
+	s.mu.Unlock()
+	s.publish(BrokerMessage{Type: BrokerConnect, From: cid})
 
 	// join to itself
 	s.Join(c.ID(), c.ID())
@@ -270,8 +279,18 @@ func (s *server) OnConnection(cb ConnectionFunc) {
 // useful when you have defined a custom connection id generator (based on a database)
 // and you want to check if that connection is already connected (on multiple tabs)
 func (s *server) IsConnected(connID string) bool {
+	s.mu.Lock()
 	c := s.connections.get(connID)
-	return c != nil
+	s.mu.Unlock()
+	return c != nil || s.remote.isConnected(connID)
+}
+
+// hasRoom reports whether a connection of this or of another node is joined to the room.
+func (s *server) hasRoom(roomName string) bool {
+	s.mu.Lock()
+	_, found := s.rooms[roomName]
+	s.mu.Unlock()
+	return found || s.remote.hasRoom(roomName)
 }
 
 // Join joins a websocket client to a room,
@@ -281,7 +300,12 @@ func (s *server) IsConnected(connID string) bool {
 func (s *server) Join(roomName string, connID string) {
 	s.mu.Lock()
 	s.join(roomName, connID)
+	local := s.connections.get(connID) != nil
 	s.mu.Unlock()
+
+	if local {
+		s.publish(BrokerMessage{Type: BrokerJoin, From: connID, Room: roomName})
+	}
 }
 
 // join used internally, no locks used.
@@ -295,17 +319,16 @@ func (s *server) join(roomName string, connID string) {
 // LeaveAll kicks out a connection from ALL of its joined rooms
 func (s *server) LeaveAll(connID string) {
 	s.mu.Lock()
-	for name, connectionIDs := range s.rooms {
-		for i := range connectionIDs {
-			if connectionIDs[i] == connID {
-				// fire the on room leave connection's listeners
-				s.connections.get(connID).fireOnLeave(name)
-				// the connection is inside this room, lets remove it
-				s.rooms[name] = append(s.rooms[name][:i], s.rooms[name][i+1:]...)
-			}
-		}
+	for name := range s.rooms {
+		s.leave(name, connID)
 	}
+	local := s.connections.get(connID) != nil
 	s.mu.Unlock()
+
+	if local {
+		// an empty room means all the rooms.
+		s.publish(BrokerMessage{Type: BrokerLeave, From: connID})
+	}
 }
 
 // Leave leaves a websocket client from a room,
@@ -316,7 +339,12 @@ func (s *server) LeaveAll(connID string) {
 func (s *server) Leave(roomName string, connID string) bool {
 	s.mu.Lock()
 	left := s.leave(roomName, connID)
+	local := s.connections.get(connID) != nil
 	s.mu.Unlock()
+
+	if left && local {
+		s.publish(BrokerMessage{Type: BrokerLeave, From: connID, Room: roomName})
+	}
 	return left
 }
 
@@ -339,23 +367,34 @@ func (s *server) leave(roomName string, connID string) (left bool) {
 
 	if left {
 		// fire the on room leave connection's listeners
-		s.connections.get(connID).fireOnLeave(roomName)
+		if c := s.connections.get(connID); c != nil {
+			c.fireOnLeave(roomName)
+		}
 	}
 	return
 }
 
 // GetConnectionsByRoom returns a list of Connection
 // which are joined to this room.
+//
+// The connections of the other nodes of the config's Broker are included,
+// their Emit and Disconnect are published to the Broker.
 func (s *server) GetConnectionsByRoom(roomName string) []Connection {
 	s.mu.Lock()
 	var conns []Connection
 	if connIDs, found := s.rooms[roomName]; found {
 		for _, connID := range connIDs {
-			conns = append(conns, s.connections.get(connID))
+			if c := s.connections.get(connID); c != nil {
+				conns = append(conns, c)
+			}
 		}
 
 	}
 	s.mu.Unlock()
+
+	for _, connID := range s.remote.roomConnections(roomName) {
+		conns = append(conns, &remoteConnection{id: connID, server: s})
+	}
 	return conns
 }
 
@@ -363,41 +402,60 @@ func (s *server) GetConnectionsByRoom(roomName string) []Connection {
 // this is the main function which writes the RAW websocket messages to the client.
 // It sends them(messages) to the correct room (self, broadcast or to specific client)
 //
+// If the config's Broker is set the message is published to it
+// and each node, including this one, delivers it to its own connections.
+//
 // You don't have to use this generic method, exists only for extreme
 // apps which you have an external goroutine with a list of custom connection list.
 //
 // You SHOULD use connection.EmitMessage/Emit/To().Emit/EmitMessage instead.
 // let's keep it unexported for the best.
-func (s *server) emitMessage(from, to string, data []byte) {
-	if to != All && to != Broadcast && s.rooms[to] != nil {
-		// it suppose to send the message to a specific room/or a user inside its own room
+func (s *server) emitMessage(from, to string, data []byte) error {
+	if s.config.Broker != nil {
+		return s.publish(BrokerMessage{Type: BrokerEmit, From: from, To: to, Data: data})
+	}
+
+	s.emitLocal(from, to, data)
+	return nil
+}
+
+// emitLocal writes the message to the connections of this node.
+func (s *server) emitLocal(from, to string, data []byte) {
+	var (
+		conns []*connection
+		stale []string
+	)
+
+	s.mu.Lock()
+	if to != All && to != Broadcast {
+		// it suppose to send the message to a specific room/or a user inside its own room,
+		// if the room doesn't exist on this node then there is nothing to do.
 		for _, connectionIDInsideRoom := range s.rooms[to] {
 			if c := s.connections.get(connectionIDInsideRoom); c != nil {
-				c.writeDefault(data) //send the message to the client(s)
+				conns = append(conns, c)
 			} else {
 				// the connection is not connected but it's inside the room, we remove it on disconnect but for ANY CASE:
-				cid := connectionIDInsideRoom
-				if c != nil {
-					cid = c.id
-				}
-				s.Leave(cid, to)
+				stale = append(stale, connectionIDInsideRoom)
 			}
 		}
 	} else {
 		// it suppose to send the message to all opened connections or to all except the sender
 		for _, cKV := range s.connections {
-			connID := cKV.key
-			if to != All && to != connID { // if it's not suppose to send to all connections (including itself)
-				if to == Broadcast && from == connID { // if broadcast to other connections except this
-					continue //here we do the opossite of previous block,
-					// just skip this connection when it's suppose to send the message to all connections except the sender
-				}
-
+			if to == Broadcast && from == cKV.key { // if broadcast to other connections except this
+				continue // just skip this connection when it's suppose to send the message to all connections except the sender
 			}
-			// send to the client(s) when the top validators passed
-			cKV.value.writeDefault(data)
+			conns = append(conns, cKV.value)
 		}
 	}
+
+	for _, cid := range stale {
+		s.leave(to, cid)
+	}
+	s.mu.Unlock()
+
+	for _, c := range conns {
+		c.writeDefault(data) //send the message to the client(s)
+	}
 }
 
 // Disconnect force-disconnects a websocket connection based on its connection.ID()
@@ -409,12 +467,28 @@ func (s *server) emitMessage(from, to string, data []byte) {
 //
 // You can use the connection.Disconnect() instead.
 func (s *server) Disconnect(connID string) (err error) {
+	s.mu.Lock()
+	local := s.connections.get(connID) != nil
+	s.mu.Unlock()
+
+	if !local {
+		if s.remote.isConnected(connID) {
+			// ask the node of the connection to disconnect it.
+			return s.publish(BrokerMessage{Type: BrokerDisconnectRequest, To: connID})
+		}
+		return nil
+	}
+
 	// leave from all joined rooms before remove the actual connection from the list.
 	// note: we cannot use that to send data if the client is actually closed.
 	s.LeaveAll(connID)
 
 	// remove the connection from the list
-	if c, ok := s.connections.remove(connID); ok {
+	s.mu.Lock()
+	c, ok := s.connections.remove(connID)
+	s.mu.Unlock()
+	if ok {
+		s.publish(BrokerMessage{Type: BrokerDisconnect, From: connID})
 		if !c.disconnected {
 			c.disconnected = true
 			// stop the ping timer
@@ -422,6 +496,8 @@ func (s *server) Disconnect(connID string) (err error) {
 
 			// fire the disconnect callbacks, if any
 			c.fireDisconnect()
+			// fail the pending asks, if any
+			c.asks.cancel(true)
 			// close the underline connection and return its error, if any.
 			err = c.underline.Close()
 		}
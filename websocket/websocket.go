@@ -11,12 +11,25 @@ import (
 )
 
 // New returns a new websocket server policy adaptor.
+//
+// If the config's Broker is set the server subscribes to it,
+// asks the other nodes for their connections and rooms, once subscribed,
+// and publishes its heartbeats.
 func New(cfg Config) Server {
-	return &server{
-		config: cfg.Validate(),
-		rooms:  make(map[string][]string, 0),
+	s := &server{
+		config:                cfg.Validate(),
+		rooms:                 make(map[string][]string, 0),
 		onConnectionListeners: make([]ConnectionFunc, 0),
+		node:                  randomString(32),
+		remote:                newRemoteNodes(),
 	}
+
+	if broker := s.config.Broker; broker != nil {
+		broker.Subscribe(s.handleBrokerMessage)
+		go s.heartbeat(s.config.BrokerHeartbeat)
+	}
+
+	return s
 }
 
 func fixPath(s string) string {